@@ -0,0 +1,107 @@
+// backtest — прогон истории через стратегию и трейлинг бота.
+//
+//	go run ./cmd/backtest -inst BTC-USDT-SWAP,ETH-USDT-SWAP -from 2025-01-01 -to 2025-02-01
+//	go run ./cmd/backtest -inst BTC-USDT-SWAP -from 2025-01-01 -to 2025-02-01 -data ./data
+//
+// Стратегии берутся из configs/$CONFIG_FILE (как у бота) или -strategies, торговые
+// и трейлинг настройки — из user_defaults/default_trailing.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"trade_bot/internal/backtest"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	okx_client "trade_bot/internal/modules/okx_client/service"
+	okxws "trade_bot/internal/modules/okx_websocket/service"
+	strategy "trade_bot/internal/modules/strategy/service"
+)
+
+func main() {
+	var (
+		insts   = flag.String("inst", "BTC-USDT-SWAP", "инструменты через запятую")
+		fromS   = flag.String("from", "", "начало, YYYY-MM-DD (UTC)")
+		toS     = flag.String("to", "", "конец, YYYY-MM-DD (UTC), по умолчанию сейчас")
+		dataDir = flag.String("data", "", "каталог с CSV <instId>_<tf>.csv (иначе OKX REST)")
+		saveDir = flag.String("save", "", "сохранить скачанную историю в CSV сюда")
+		feePct  = flag.Float64("fee", 0.05, "taker-комиссия за сторону, %")
+		outCSV  = flag.String("out", "", "записать сделки в CSV")
+		quiet   = flag.Bool("q", false, "не печатать список сделок")
+		strats  = flag.String("strategies", "", "стратегии через запятую (пусто — strategy.engines из конфига)")
+	)
+	flag.Parse()
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	from, err := time.Parse("2006-01-02", *fromS)
+	if err != nil {
+		log.Fatalf("-from: %v", err)
+	}
+	to := time.Now().UTC()
+	if *toS != "" {
+		if to, err = time.Parse("2006-01-02", *toS); err != nil {
+			log.Fatalf("-to: %v", err)
+		}
+	}
+
+	settings := models.NewTradingSettingsFromDefaults(0, cfg).Settings
+	if *strats != "" {
+		cfg.Strategy.Engines = strings.Split(*strats, ",")
+	}
+	engs, err := strategy.NewEngines(cfg)
+	if err != nil {
+		log.Fatalf("strategies: %v", err)
+	}
+	for _, e := range engs {
+		settings.TradingSettings.Strategies = append(settings.TradingSettings.Strategies, e.Type())
+	}
+
+	symbols := strings.Split(*insts, ",")
+	ctx := context.Background()
+
+	loader := backtest.Loader{Dir: *dataDir, SaveDir: *saveDir}
+	tick := make(map[string]float64)
+	if *dataDir == "" {
+		loader.Mkt = okxws.NewClient(cfg, nil)
+
+		// tickSz — чтобы SL/TP округлялись как в бою
+		pub := okx_client.NewClient(cfg, &models.UserSettings{})
+		for _, s := range symbols {
+			if meta, err := pub.GetInstrumentMeta(ctx, s); err == nil {
+				tick[s] = meta.TickSz
+			}
+		}
+	}
+
+	candles, err := loader.Load(ctx, cfg, symbols, from, to)
+	if err != nil {
+		log.Fatalf("load: %v", err)
+	}
+
+	res := backtest.Run(cfg, backtest.Options{
+		Settings: settings,
+		TickSz:   tick,
+		FeePct:   *feePct,
+		From:     from,
+	}, candles)
+
+	if !*quiet {
+		backtest.PrintTrades(os.Stdout, res.Trades)
+		os.Stdout.WriteString("\n")
+	}
+	backtest.PrintStats(os.Stdout, res.Stats)
+
+	if *outCSV != "" {
+		if err := backtest.WriteTradesCSV(*outCSV, res.Trades); err != nil {
+			log.Fatalf("write %s: %v", *outCSV, err)
+		}
+	}
+}
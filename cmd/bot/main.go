@@ -7,6 +7,7 @@ import (
 	"trade_bot/internal/modules/config"
 	"trade_bot/internal/modules/health"
 	"trade_bot/internal/modules/okx_websocket"
+	"trade_bot/internal/modules/paper"
 	"trade_bot/internal/modules/postgres"
 	"trade_bot/internal/modules/strategy"
 	telegram "trade_bot/internal/modules/telegram_bot"
@@ -27,6 +28,7 @@ func main() {
 		config.Module(),
 		postgres.Module(),
 		okx_websocket.Module(),
+		paper.Module(),
 		strategy.Module(),
 		bootstrap.Module(),
 		runner.Module(),
@@ -0,0 +1,187 @@
+// optimize — перебор параметров стратегии и трейлинга на истории.
+//
+//	go run ./cmd/optimize -top 100 -from 2025-01-01 -to 2025-04-01 \
+//	    -grid strategy.donchian_period=10:40:5 -grid trailing.be_trigger_r=0.4,0.6,0.8 \
+//	    -objective mar -folds 3 -out result.json
+//
+// Каждая комбинация — полный backtest.Run; комбинации гоняются параллельно
+// на всех ядрах. С -folds N период режется на N+1 кусков (walk-forward):
+// лучшие параметры на куске k проверяются на куске k+1.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+	"trade_bot/internal/backtest"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	okx_client "trade_bot/internal/modules/okx_client/service"
+	okxws "trade_bot/internal/modules/okx_websocket/service"
+	"trade_bot/internal/optimizer"
+)
+
+type gridFlags []string
+
+func (g *gridFlags) String() string     { return strings.Join(*g, " ") }
+func (g *gridFlags) Set(v string) error { *g = append(*g, v); return nil }
+
+func main() {
+	var (
+		grids     gridFlags
+		insts     = flag.String("inst", "BTC-USDT-SWAP", "инструменты через запятую")
+		topN      = flag.Int("top", 0, "вместо -inst взять N самых волатильных USDT-perp (OKX REST)")
+		fromS     = flag.String("from", "", "начало, YYYY-MM-DD (UTC)")
+		toS       = flag.String("to", "", "конец, YYYY-MM-DD (UTC), по умолчанию сейчас")
+		dataDir   = flag.String("data", "", "каталог с CSV <instId>_<tf>.csv (иначе OKX REST)")
+		saveDir   = flag.String("save", "", "сохранить скачанную историю в CSV сюда")
+		feePct    = flag.Float64("fee", 0.05, "taker-комиссия за сторону, %")
+		objS      = flag.String("objective", "expectancy", "expectancy | sharpe | mar")
+		minTrades = flag.Int("min-trades", 30, "меньше сделок — комбинация не ранжируется")
+		folds     = flag.Int("folds", 0, "walk-forward окон (0 — без разбиения)")
+		workers   = flag.Int("workers", 0, "параллельных прогонов (0 — по числу CPU)")
+		show      = flag.Int("show", 20, "сколько лучших комбинаций печатать")
+		out       = flag.String("out", "", "экспорт результатов: .csv или .json")
+	)
+	flag.Var(&grids, "grid", "диапазон: group.yaml_key=from:to:step или =v1,v2 (можно несколько)")
+	flag.Parse()
+
+	if len(grids) == 0 {
+		log.Fatalf("нужен хотя бы один -grid")
+	}
+	opt := optimizer.Options{MinTrades: *minTrades, Workers: *workers, Folds: *folds}
+	for _, g := range grids {
+		r, err := optimizer.ParseRange(g)
+		if err != nil {
+			log.Fatalf("-grid: %v", err)
+		}
+		opt.Ranges = append(opt.Ranges, r)
+	}
+	obj, err := optimizer.ParseObjective(*objS)
+	if err != nil {
+		log.Fatalf("-objective: %v", err)
+	}
+	opt.Objective = obj
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	from, err := time.Parse("2006-01-02", *fromS)
+	if err != nil {
+		log.Fatalf("-from: %v", err)
+	}
+	to := time.Now().UTC()
+	if *toS != "" {
+		if to, err = time.Parse("2006-01-02", *toS); err != nil {
+			log.Fatalf("-to: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	symbols := strings.Split(*insts, ",")
+	loader := backtest.Loader{Dir: *dataDir, SaveDir: *saveDir}
+	tick := make(map[string]float64)
+	if *dataDir == "" {
+		mkt := okxws.NewClient(cfg, nil)
+		loader.Mkt = mkt
+		if *topN > 0 {
+			if symbols = mkt.TopVolatile(*topN); len(symbols) == 0 {
+				log.Fatalf("-top: OKX не вернул тикеры")
+			}
+		}
+
+		pub := okx_client.NewClient(cfg, &models.UserSettings{})
+		for _, s := range symbols {
+			if meta, err := pub.GetInstrumentMeta(ctx, s); err == nil {
+				tick[s] = meta.TickSz
+			}
+		}
+	}
+
+	candles, err := loader.Load(ctx, cfg, symbols, from, to)
+	if err != nil {
+		log.Fatalf("load: %v", err)
+	}
+
+	points := len(optimizer.Grid(opt.Ranges))
+	log.Printf("optimize: %d symbols, %d candles, %d combinations, objective=%s, folds=%d",
+		len(symbols), len(candles), points, obj, *folds)
+
+	// стратегия логирует каждый сигнал — на тысячах прогонов это только шум
+	started := time.Now()
+	log.SetOutput(io.Discard)
+	rep, err := optimizer.Optimize(ctx, cfg, backtest.Options{
+		Settings: models.NewTradingSettingsFromDefaults(0, cfg).Settings,
+		TickSz:   tick,
+		FeePct:   *feePct,
+	}, candles, from, to, opt)
+	log.SetOutput(os.Stderr)
+	if err != nil {
+		log.Fatalf("optimize: %v", err)
+	}
+	log.Printf("optimize: done in %s", time.Since(started).Round(time.Second))
+
+	printReport(os.Stdout, rep, *show)
+
+	if *out != "" {
+		switch strings.ToLower(filepath.Ext(*out)) {
+		case ".json":
+			err = optimizer.WriteJSON(*out, rep)
+		default:
+			err = optimizer.WriteCSV(*out, rep)
+		}
+		if err != nil {
+			log.Fatalf("write %s: %v", *out, err)
+		}
+	}
+}
+
+func printReport(w io.Writer, rep optimizer.Report, show int) {
+	if len(rep.Folds) == 0 {
+		fmt.Fprintf(w, "%-4s %8s %7s %8s %8s %7s  %s\n", "#", "score", "trades", "win%", "exp R", "maxDD", "params")
+		for i, r := range rep.Ranking {
+			if i >= show {
+				break
+			}
+			printRun(w, i+1, r)
+		}
+		return
+	}
+
+	for _, f := range rep.Folds {
+		fmt.Fprintf(w, "fold %d: train %s..%s  test %s..%s\n", f.N,
+			f.TrainFrom.Format("2006-01-02"), f.TrainTo.Format("2006-01-02"),
+			f.TestFrom.Format("2006-01-02"), f.TestTo.Format("2006-01-02"))
+		if f.NoValid {
+			fmt.Fprintf(w, "  train: no valid params (ни одна комбинация не набрала min-trades), test пропущен\n")
+			continue
+		}
+		fmt.Fprintf(w, "  train: ")
+		printRun(w, 1, f.Best)
+		fmt.Fprintf(w, "  test:  trades=%d win=%.1f%% exp=%.3fR total=%.2fR maxDD=%.2fR\n",
+			f.Test.Trades, f.Test.WinRate*100, f.Test.ExpectancyR, f.Test.TotalR, f.Test.MaxDrawdownR)
+	}
+	fmt.Fprintf(w, "\nout-of-sample (все test-окна):\n")
+	backtest.PrintStats(w, rep.OOS)
+}
+
+func printRun(w io.Writer, n int, r optimizer.Run) {
+	score := fmt.Sprintf("%8.3f", r.Score)
+	if math.IsInf(r.Score, -1) {
+		score = fmt.Sprintf("%8s", "-")
+	}
+	fmt.Fprintf(w, "%-4d %s %7d %7.1f%% %8.3f %7.2f  %s\n",
+		n, score, r.Stats.Trades, r.Stats.WinRate*100, r.Stats.ExpectancyR, r.Stats.MaxDrawdownR, r.Params)
+}
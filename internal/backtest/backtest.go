@@ -0,0 +1,339 @@
+// Package backtest — прогон истории через те же стратегии и тот же трейлинг,
+// что крутятся в боте: strategy/service.Engines -> sessions.StopPct/CalcSLTP ->
+// sessions.DecideTrail15m на каждой закрытой 1m свече.
+//
+// Результат считается в R (1R = расстояние до стартового SL), размер позиции
+// нормирован к 1 — так сделки с разным StopPct и RiskPct сравнимы.
+package backtest
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	strategy "trade_bot/internal/modules/strategy/service"
+	"trade_bot/internal/runner/sessions"
+)
+
+// Options — всё, что в живом боте берётся из настроек юзера и биржи.
+type Options struct {
+	Settings models.Settings    // Strategies, StopPct/StopMode, TakeProfitRR, MaxOpenPositions + TrailingConfig
+	TickSz   map[string]float64 // instId -> tickSz (0 — без округления)
+	FeePct   float64            // taker-комиссия за сторону, % (0.05)
+	From     time.Time          // сигналы раньше From только греют стратегию
+}
+
+type Trade struct {
+	InstID   string
+	Side     models.Side
+	Strategy models.StrategyType
+
+	Entry    float64
+	SL       float64 // стартовый
+	TP       float64
+	RiskDist float64
+
+	OpenedAt time.Time
+	ClosedAt time.Time
+	Exit     float64 // средневзвешенная цена выхода
+	R        float64 // итог с учётом комиссий
+	FeeR     float64
+	Reason   string // SL / TP / TIME_STOP / END
+	Partial  bool   // была частичная фиксация
+}
+
+type Result struct {
+	Trades []Trade
+	Stats  Stats
+}
+
+// Run прогоняет свечи всех инструментов и таймфреймов (1m + LTF + HTF).
+// Порядок: по End, при равенстве — старший ТФ раньше (как прогрев в боте).
+func Run(cfg *config.Config, opt Options, candles []models.CandleTick) Result {
+	sorted := candles
+	if !sort.SliceIsSorted(candles, candleLess(candles)) {
+		sorted = Sorted(candles)
+	}
+
+	// движки из cfg.Strategy.Engines, сигналы — только тех, на которые «подписан» прогон
+	engs, err := strategy.NewEngines(cfg)
+	if err != nil {
+		log.Printf("[BACKTEST] engines: %v", err)
+		return Result{}
+	}
+	atr := strategy.NewATR(cfg)
+	trailTF := sessions.TrailTF(cfg.Strategy.LTF)
+	open := make(map[string]*position) // instId -> позиция
+	last := make(map[string]float64)
+	var trades []Trade
+
+	for _, ct := range sorted {
+		if helper.NormTF(ct.TimeframeRaw) == "1m" {
+			last[ct.InstID] = ct.Close
+			if p := open[ct.InstID]; p != nil && !ct.Start.Before(p.trade.OpenedAt) {
+				if p.step(ct, opt, atr, trailTF) {
+					trades = append(trades, p.trade)
+					delete(open, ct.InstID)
+				}
+			}
+		}
+
+		var sigs []models.Signal
+		for _, e := range engs {
+			if sig, ok, _ := e.OnCandle(ct); ok && opt.Settings.TradingSettings.Subscribed(e.Type()) {
+				sig.Strategy = e.Type()
+				sigs = append(sigs, sig)
+			}
+		}
+		atr.OnCandle(ct)
+
+		// как в боте: по символу одна позиция, первый сигнал занимает слот
+		for _, sig := range sigs {
+			sig.ATR = atr.Value(sig.InstID, sig.TF)
+			if open[sig.InstID] != nil || ct.End.Before(opt.From) {
+				continue
+			}
+			if limit := opt.Settings.TradingSettings.MaxOpenPositions; limit > 0 && len(open) >= limit {
+				continue
+			}
+			if p := openPosition(sig, ct.End, opt); p != nil {
+				open[sig.InstID] = p
+			}
+		}
+	}
+
+	// хвосты закрываем по последней цене
+	var endAt time.Time
+	if n := len(sorted); n > 0 {
+		endAt = sorted[n-1].End
+	}
+	insts := make([]string, 0, len(open))
+	for inst := range open {
+		insts = append(insts, inst)
+	}
+	sort.Strings(insts)
+	for _, inst := range insts {
+		p := open[inst]
+		p.fill(last[inst], p.st.Size, opt)
+		p.finish(endAt, last[inst], "END")
+		trades = append(trades, p.trade)
+	}
+
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].OpenedAt.Before(trades[j].OpenedAt) })
+	return Result{Trades: trades, Stats: CalcStats(trades)}
+}
+
+// Sorted — копия свечей в порядке прогона. Для многократных прогонов
+// (оптимизатор) сортируем один раз: Run не копирует уже упорядоченный срез.
+func Sorted(candles []models.CandleTick) []models.CandleTick {
+	out := append([]models.CandleTick(nil), candles...)
+	sort.SliceStable(out, candleLess(out))
+	return out
+}
+
+func candleLess(cs []models.CandleTick) func(i, j int) bool {
+	return func(i, j int) bool {
+		if !cs[i].End.Equal(cs[j].End) {
+			return cs[i].End.Before(cs[j].End)
+		}
+		return tfDuration(cs[i].TimeframeRaw) > tfDuration(cs[j].TimeframeRaw)
+	}
+}
+
+type position struct {
+	trade Trade
+	st    *models.PositionTrailState
+	dir   float64 // +1 long, -1 short
+
+	exitNotional float64 // sum(px*size) по выходам
+	exitSize     float64
+	pnlR         float64
+	feeR         float64
+}
+
+func openPosition(sig models.Signal, at time.Time, opt Options) *position {
+	ts := opt.Settings.TradingSettings
+	rr := ts.TakeProfitRR
+	if rr <= 0 {
+		rr = 2.0
+	}
+	side := strings.ToUpper(string(sig.Side))
+	stopPct, err := sessions.StopPct(ts, side, sig.Price, sig)
+	if err != nil || stopPct > 0.20 { // тот же safety-guard, что в calcTradeParams
+		return nil
+	}
+	sl, tp, riskDist, err := sessions.CalcSLTP(side, sig.Price, stopPct, rr, opt.TickSz[sig.InstID])
+	if err != nil {
+		return nil
+	}
+
+	posSide, dir := "long", 1.0
+	if side == "SELL" {
+		posSide, dir = "short", -1.0
+	}
+
+	p := &position{
+		trade: Trade{
+			InstID:   sig.InstID,
+			Side:     sig.Side,
+			Strategy: sig.Strategy,
+			Entry:    sig.Price,
+			SL:       sl,
+			TP:       tp,
+			RiskDist: riskDist,
+			OpenedAt: at,
+		},
+		st: &models.PositionTrailState{
+			InstID:   sig.InstID,
+			PosSide:  posSide,
+			Entry:    sig.Price,
+			SL:       sl,
+			TP:       tp,
+			RiskDist: riskDist,
+			TickSz:   opt.TickSz[sig.InstID],
+			Size:     1,
+			MFE:      sig.Price,
+			OpenedAt: at,
+		},
+		dir: dir,
+	}
+	// лестница: ступени вместо одного TP (объём нормирован к 1, без lotSz)
+	if tps := sessions.TPLadder(side, sig.Price, riskDist, 1, opt.TickSz[sig.InstID], 0, 0, ts.TPLadder); len(tps) > 0 {
+		copy(p.st.TPs[:], tps)
+		p.st.TP = 0
+		p.trade.TP = tps[len(tps)-1].Price
+	}
+	p.feeR = opt.FeePct / 100 * sig.Price / riskDist
+	return p
+}
+
+// step — одна закрытая 1m свеча: сначала биржевые SL/TP внутри свечи
+// (при касании обоих считаем, что первым был SL), потом трейлинг как в trailOne.
+// true — позиция закрыта.
+func (p *position) step(ct models.CandleTick, opt Options, atr sessions.ATRSource, trailTF string) bool {
+	st := p.st
+	long := st.PosSide == "long"
+
+	slHit := (long && ct.Low <= st.SL) || (!long && ct.High >= st.SL)
+	tpHit := st.TP > 0 && ((long && ct.High >= st.TP) || (!long && ct.Low <= st.TP))
+	switch {
+	case slHit:
+		p.fill(st.SL, st.Size, opt)
+		p.finish(ct.End, st.SL, "SL")
+		return true
+	case tpHit:
+		p.fill(st.TP, st.Size, opt)
+		p.finish(ct.End, st.TP, "TP")
+		return true
+	}
+
+	// ступени лестницы TP
+	for i := range st.TPs {
+		tp := &st.TPs[i]
+		if tp.Size <= 0 || !((long && ct.High >= tp.Price) || (!long && ct.Low <= tp.Price)) {
+			continue
+		}
+		sz := min(tp.Size, st.Size)
+		p.fill(tp.Price, sz, opt)
+		p.trade.Partial = true
+		st.Size -= sz
+		tp.Size = 0
+		if st.Size <= 1e-12 {
+			p.finish(ct.End, tp.Price, "TP")
+			return true
+		}
+	}
+
+	st.UpdateMFE(ct.High, ct.Low)
+
+	mkt := sessions.TrailMarketFrom(atr, st.InstID, trailTF, ct.Close, opt.Settings.TrailingConfig)
+	dec := sessions.DecideTrail15m(st, opt.Settings, mkt, ct.End)
+	if !dec.MoveSL && !dec.Close {
+		return false
+	}
+	if !st.LastTrailAt.IsZero() && ct.End.Sub(st.LastTrailAt) < 60*time.Second {
+		return false
+	}
+
+	if dec.CloseSize > 0 {
+		sz := dec.CloseSize
+		if sz > st.Size {
+			sz = st.Size
+		}
+		p.fill(ct.Close, sz, opt)
+		p.trade.Partial = true
+		st.Size -= sz
+		st.LastTrailAt = ct.End
+		if st.Size <= 1e-12 {
+			p.finish(ct.End, ct.Close, dec.Reason)
+			return true
+		}
+		return false
+	}
+
+	if dec.Close {
+		p.fill(ct.Close, st.Size, opt)
+		p.finish(ct.End, ct.Close, dec.Reason)
+		return true
+	}
+
+	newSL := dec.NewSL
+	if st.TickSz > 0 {
+		if long {
+			newSL = helper.RoundUpToTick(newSL, st.TickSz)
+		} else {
+			newSL = helper.RoundDownToTick(newSL, st.TickSz)
+		}
+	}
+	st.SL = newSL
+	st.LastTrailAt = ct.End
+	return false
+}
+
+func (p *position) fill(px, size float64, opt Options) {
+	if size <= 0 || px <= 0 {
+		return
+	}
+	p.pnlR += p.dir * (px - p.trade.Entry) * size / p.trade.RiskDist
+	p.feeR += opt.FeePct / 100 * px * size / p.trade.RiskDist
+	p.exitNotional += px * size
+	p.exitSize += size
+}
+
+func (p *position) finish(at time.Time, px float64, reason string) {
+	p.trade.ClosedAt = at
+	p.trade.Exit = px
+	if p.exitSize > 0 {
+		p.trade.Exit = p.exitNotional / p.exitSize
+	}
+	p.trade.FeeR = p.feeR
+	p.trade.R = p.pnlR - p.feeR
+	p.trade.Reason = reason
+}
+
+func tfDuration(tf string) time.Duration {
+	switch helper.NormTF(tf) {
+	case "1m":
+		return time.Minute
+	case "3m":
+		return 3 * time.Minute
+	case "5m":
+		return 5 * time.Minute
+	case "15m":
+		return 15 * time.Minute
+	case "30m":
+		return 30 * time.Minute
+	case "1h":
+		return time.Hour
+	case "2h":
+		return 2 * time.Hour
+	case "4h":
+		return 4 * time.Hour
+	case "1d":
+		return 24 * time.Hour
+	}
+	return 0
+}
@@ -0,0 +1,146 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+// CSV: ts,open,high,low,close,volume — ts = начало свечи, unix ms (как у OKX).
+var csvHeader = []string{"ts", "open", "high", "low", "close", "volume"}
+
+// CSVPath — <dir>/<instId>_<tf>.csv, например data/BTC-USDT-SWAP_15m.csv.
+func CSVPath(dir, instID, tf string) string {
+	return filepath.Join(dir, instID+"_"+helper.NormTF(tf)+".csv")
+}
+
+func LoadCSV(path, instID, tf string) ([]models.CandleTick, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dur := tfDuration(tf)
+	r := csv.NewReader(f)
+	var out []models.CandleTick
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if line == 1 && rec[0] == csvHeader[0] {
+			continue
+		}
+		if len(rec) < 5 {
+			return nil, fmt.Errorf("%s:%d: want ts,open,high,low,close[,volume]", path, line)
+		}
+
+		ts, err := strconv.ParseInt(rec[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: ts: %w", path, line, err)
+		}
+		var px [4]float64
+		for i := range px {
+			if px[i], err = strconv.ParseFloat(rec[i+1], 64); err != nil {
+				return nil, fmt.Errorf("%s:%d: %s: %w", path, line, csvHeader[i+1], err)
+			}
+		}
+		var vol float64
+		if len(rec) > 5 {
+			vol, _ = strconv.ParseFloat(rec[5], 64)
+		}
+
+		start := time.UnixMilli(ts)
+		out = append(out, models.CandleTick{
+			InstID:       instID,
+			Open:         px[0],
+			High:         px[1],
+			Low:          px[2],
+			Close:        px[3],
+			Volume:       vol,
+			Start:        start,
+			End:          start.Add(dur),
+			TimeframeRaw: helper.NormTF(tf),
+		})
+	}
+	return out, nil
+}
+
+func SaveCSV(path string, candles []models.CandleTick) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	_ = w.Write(csvHeader)
+	for _, c := range candles {
+		_ = w.Write([]string{
+			strconv.FormatInt(c.Start.UnixMilli(), 10),
+			strconv.FormatFloat(c.Open, 'f', -1, 64),
+			strconv.FormatFloat(c.High, 'f', -1, 64),
+			strconv.FormatFloat(c.Low, 'f', -1, 64),
+			strconv.FormatFloat(c.Close, 'f', -1, 64),
+			strconv.FormatFloat(c.Volume, 'f', -1, 64),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Resample собирает старший ТФ из 1m (если в CSV есть только минутки).
+// Незакрытый хвост (неполное окно в конце) отбрасывается.
+func Resample(m1 []models.CandleTick, tf string) []models.CandleTick {
+	dur := tfDuration(tf)
+	if dur <= time.Minute {
+		return m1
+	}
+
+	var out []models.CandleTick
+	var cur models.CandleTick
+	var n int
+	for _, c := range m1 {
+		start := c.Start.Truncate(dur)
+		if n > 0 && !start.Equal(cur.Start) {
+			if n == int(dur/time.Minute) {
+				out = append(out, cur)
+			}
+			n = 0
+		}
+		if n == 0 {
+			cur = models.CandleTick{
+				InstID:       c.InstID,
+				Open:         c.Open,
+				High:         c.High,
+				Low:          c.Low,
+				Start:        start,
+				End:          start.Add(dur),
+				TimeframeRaw: helper.NormTF(tf),
+			}
+		}
+		cur.High = max(cur.High, c.High)
+		cur.Low = min(cur.Low, c.Low)
+		cur.Close = c.Close
+		cur.Volume += c.Volume
+		cur.QuoteVolume += c.QuoteVolume
+		n++
+	}
+	if n == int(dur/time.Minute) {
+		out = append(out, cur)
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	okxws "trade_bot/internal/modules/okx_websocket/service"
+)
+
+// Loader — откуда брать историю: CSV из Dir или OKX /market/history-candles.
+type Loader struct {
+	Dir     string        // если задан — читаем <Dir>/<instId>_<tf>.csv
+	SaveDir string        // если задан — скачанное с OKX кладём сюда в CSV
+	Mkt     *okxws.Client // REST OKX (нужен, если Dir пуст)
+}
+
+// Load — 1m за [from, to) и LTF/HTF с запасом под прогрев стратегии.
+// Если в CSV нет файла LTF/HTF — собираем его из 1m.
+func (l Loader) Load(ctx context.Context, cfg *config.Config, insts []string, from, to time.Time) ([]models.CandleTick, error) {
+	tfs := []struct {
+		tf     string
+		warmup int
+	}{
+		{"1m", 0},
+		{cfg.Strategy.LTF, cfg.Strategy.MinWarmupLTF + cfg.Strategy.DonchianPeriod},
+		{cfg.Strategy.HTF, cfg.Strategy.MinWarmupHTF + cfg.Strategy.HTFEmaSlow},
+	}
+
+	var out []models.CandleTick
+	for _, inst := range insts {
+		var m1 []models.CandleTick
+		for _, x := range tfs {
+			tf := helper.NormTF(x.tf)
+			start := from.Add(-time.Duration(x.warmup) * tfDuration(tf))
+
+			var cs []models.CandleTick
+			var err error
+			switch {
+			case l.Dir != "":
+				cs, err = LoadCSV(CSVPath(l.Dir, inst, tf), inst, tf)
+				if errors.Is(err, fs.ErrNotExist) && tf != "1m" {
+					log.Printf("[BACKTEST] %s %s: нет CSV, собираем из 1m", inst, tf)
+					cs, err = Resample(m1, tf), nil
+				}
+			case l.Mkt != nil:
+				cs, err = l.Mkt.GetHistoryCandles(ctx, inst, tf, start, to)
+				if err == nil && l.SaveDir != "" {
+					err = SaveCSV(CSVPath(l.SaveDir, inst, tf), cs)
+				}
+			default:
+				err = fmt.Errorf("no data source")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("load %s %s: %w", inst, tf, err)
+			}
+
+			if tf == "1m" {
+				m1 = cs
+			}
+			cs = window(cs, start, to)
+			for i := range cs {
+				cs[i].InstID = inst
+				cs[i].TimeframeRaw = tf
+			}
+			log.Printf("[BACKTEST] %s %s: %d свечей", inst, tf, len(cs))
+			out = append(out, cs...)
+		}
+	}
+	return out, nil
+}
+
+// window — свечи с началом в [from, to).
+func window(cs []models.CandleTick, from, to time.Time) []models.CandleTick {
+	out := cs[:0:0]
+	for _, c := range cs {
+		if !c.Start.Before(from) && c.Start.Before(to) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+func PrintTrades(w io.Writer, trades []Trade) {
+	fmt.Fprintf(w, "%-4s %-18s %-4s %-16s %-16s %12s %12s %12s %8s  %s\n",
+		"#", "inst", "side", "opened", "closed", "entry", "sl", "exit", "R", "reason")
+	for i, t := range trades {
+		reason := t.Reason
+		if t.Partial {
+			reason += " +partial"
+		}
+		fmt.Fprintf(w, "%-4d %-18s %-4s %-16s %-16s %12.6g %12.6g %12.6g %8.2f  %s\n",
+			i+1, t.InstID, t.Side,
+			t.OpenedAt.UTC().Format("2006-01-02 15:04"),
+			t.ClosedAt.UTC().Format("2006-01-02 15:04"),
+			t.Entry, t.SL, t.Exit, t.R, reason,
+		)
+	}
+}
+
+func PrintStats(w io.Writer, s Stats) {
+	fmt.Fprintf(w,
+		"trades=%d wins=%d losses=%d\n"+
+			"win rate:      %.1f%%\n"+
+			"expectancy:    %.3fR\n"+
+			"total:         %.2fR\n"+
+			"profit factor: %.2f\n"+
+			"max drawdown:  %.2fR\n"+
+			"sharpe (R):    %.2f\n",
+		s.Trades, s.Wins, s.Losses,
+		s.WinRate*100,
+		s.ExpectancyR,
+		s.TotalR,
+		s.ProfitFactor,
+		s.MaxDrawdownR,
+		s.SharpeR,
+	)
+}
+
+func WriteTradesCSV(path string, trades []Trade) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ff := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"inst", "side", "strategy", "opened", "closed", "entry", "sl", "tp", "exit", "r", "fee_r", "reason", "partial"})
+	for _, t := range trades {
+		_ = w.Write([]string{
+			t.InstID, string(t.Side), string(t.Strategy),
+			t.OpenedAt.UTC().Format(time.RFC3339), t.ClosedAt.UTC().Format(time.RFC3339),
+			ff(t.Entry), ff(t.SL), ff(t.TP), ff(t.Exit), ff(t.R), ff(t.FeeR),
+			t.Reason, strconv.FormatBool(t.Partial),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
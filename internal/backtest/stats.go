@@ -0,0 +1,77 @@
+package backtest
+
+import (
+	"math"
+	"sort"
+)
+
+type Stats struct {
+	Trades int
+	Wins   int
+	Losses int
+
+	WinRate      float64 // доля, 0..1
+	ExpectancyR  float64 // средний R на сделку
+	TotalR       float64
+	ProfitFactor float64 // sum(win R) / |sum(loss R)|, +Inf если убыточных нет
+	MaxDrawdownR float64 // по кривой накопленного R (в порядке закрытия)
+	SharpeR      float64 // mean/std по R сделок (без годовой нормировки)
+}
+
+func CalcStats(trades []Trade) Stats {
+	var s Stats
+	s.Trades = len(trades)
+	if s.Trades == 0 {
+		return s
+	}
+
+	var grossWin, grossLoss, peak, equity float64
+	for _, t := range byClose(trades) {
+		s.TotalR += t.R
+		if t.R > 0 {
+			s.Wins++
+			grossWin += t.R
+		} else {
+			s.Losses++
+			grossLoss += -t.R
+		}
+
+		equity += t.R
+		if equity > peak {
+			peak = equity
+		}
+		if dd := peak - equity; dd > s.MaxDrawdownR {
+			s.MaxDrawdownR = dd
+		}
+	}
+
+	n := float64(s.Trades)
+	s.WinRate = float64(s.Wins) / n
+	s.ExpectancyR = s.TotalR / n
+
+	switch {
+	case grossLoss > 0:
+		s.ProfitFactor = grossWin / grossLoss
+	case grossWin > 0:
+		s.ProfitFactor = math.Inf(1)
+	}
+
+	if s.Trades > 1 {
+		var ss float64
+		for _, t := range trades {
+			d := t.R - s.ExpectancyR
+			ss += d * d
+		}
+		if std := math.Sqrt(ss / (n - 1)); std > 0 {
+			s.SharpeR = s.ExpectancyR / std
+		}
+	}
+	return s
+}
+
+// byClose — просадка считается по мере закрытия сделок, а не открытия.
+func byClose(trades []Trade) []Trade {
+	out := append([]Trade(nil), trades...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ClosedAt.Before(out[j].ClosedAt) })
+	return out
+}
@@ -0,0 +1,74 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalcStats(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return t0.Add(time.Duration(h) * time.Hour) }
+
+	tests := []struct {
+		name   string
+		trades []Trade
+		want   Stats
+	}{
+		{name: "empty"},
+		{
+			// по закрытию: +1 -1 +2 -1 -> кривая 1 0 2 1, просадка 1
+			// (в порядке списка было бы 2)
+			name: "mixed",
+			trades: []Trade{
+				{R: -1, ClosedAt: at(2)},
+				{R: -1, ClosedAt: at(4)},
+				{R: 1, ClosedAt: at(1)},
+				{R: 2, ClosedAt: at(3)},
+			},
+			want: Stats{
+				Trades: 4, Wins: 2, Losses: 2,
+				WinRate: 0.5, ExpectancyR: 0.25, TotalR: 1,
+				ProfitFactor: 1.5, MaxDrawdownR: 1,
+				SharpeR: 0.25 / 1.5, // std по выборке: sqrt(6.75/3)
+			},
+		},
+		{
+			name:   "no losses",
+			trades: []Trade{{R: 1, ClosedAt: at(1)}, {R: 3, ClosedAt: at(2)}},
+			want: Stats{
+				Trades: 2, Wins: 2, WinRate: 1, ExpectancyR: 2, TotalR: 4,
+				ProfitFactor: math.Inf(1), SharpeR: 2 / math.Sqrt2,
+			},
+		},
+		{
+			// нулевой R — не выигрыш
+			name:   "single flat",
+			trades: []Trade{{R: 0, ClosedAt: at(1)}},
+			want:   Stats{Trades: 1, Losses: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalcStats(tt.trades)
+			if got.Trades != tt.want.Trades || got.Wins != tt.want.Wins || got.Losses != tt.want.Losses {
+				t.Fatalf("counts = %d/%d/%d, want %d/%d/%d", got.Trades, got.Wins, got.Losses, tt.want.Trades, tt.want.Wins, tt.want.Losses)
+			}
+			for _, f := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"WinRate", got.WinRate, tt.want.WinRate},
+				{"ExpectancyR", got.ExpectancyR, tt.want.ExpectancyR},
+				{"TotalR", got.TotalR, tt.want.TotalR},
+				{"ProfitFactor", got.ProfitFactor, tt.want.ProfitFactor},
+				{"MaxDrawdownR", got.MaxDrawdownR, tt.want.MaxDrawdownR},
+				{"SharpeR", got.SharpeR, tt.want.SharpeR},
+			} {
+				if f.got != f.want && math.Abs(f.got-f.want) > 1e-9 {
+					t.Fatalf("%s = %v, want %v", f.name, f.got, f.want)
+				}
+			}
+		})
+	}
+}
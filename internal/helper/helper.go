@@ -23,6 +23,33 @@ func NormTF(raw string) string {
 	}
 }
 
+// TFDuration — длительность свечи таймфрейма (0 — неизвестный).
+func TFDuration(tf string) time.Duration {
+	switch NormTF(tf) {
+	case "1m":
+		return time.Minute
+	case "3m":
+		return 3 * time.Minute
+	case "5m":
+		return 5 * time.Minute
+	case "10m":
+		return 10 * time.Minute
+	case "15m":
+		return 15 * time.Minute
+	case "30m":
+		return 30 * time.Minute
+	case "1h":
+		return time.Hour
+	case "2h":
+		return 2 * time.Hour
+	case "4h":
+		return 4 * time.Hour
+	case "1d":
+		return 24 * time.Hour
+	}
+	return 0
+}
+
 func TrailKey(instId, posSide string) string { return instId + ":" + posSide }
 
 func TrailSlot15m(t time.Time) time.Time {
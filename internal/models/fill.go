@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Fill — исполнение ордера из /trade/fills-history.
+type Fill struct {
+	BillID  string
+	TradeID string
+	OrdID   string
+	ClOrdID string
+	InstID  string
+	Side    string // buy/sell
+	PosSide string // long/short
+	Px      float64
+	Sz      float64
+	Fee     float64 // как на OKX: отрицательная — списана, положительная — ребейт
+	Pnl     float64 // fillPnl: реализованный PnL закрывающего исполнения, без комиссии
+	Ts      time.Time
+}
+
+// Bill — движение по счёту из /account/bills. Бот берёт только funding (type=8).
+type Bill struct {
+	BillID string
+	InstID string
+	Type   string
+	BalChg float64 // USDT, со знаком
+	Ts     time.Time
+}
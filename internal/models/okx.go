@@ -55,4 +55,11 @@ type TradeParams struct {
 	RiskDist  float64
 	Leverage  int
 	Direction string // "BUY" или "SELL"
+
+	CtVal    float64 // номинал контракта
+	RiskUSDT float64 // денежный риск до стартового SL (0 — не посчитан, inverse)
+
+	LotSz float64
+	MinSz float64
+	TPs   []TPOrder // лестница TP без AlgoID (пусто — один TP на весь объём)
 }
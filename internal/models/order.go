@@ -0,0 +1,29 @@
+package models
+
+// OrderState — состояние ордера, как state в /trade/order OKX.
+type OrderState string
+
+const (
+	OrderLive            OrderState = "live"
+	OrderPartiallyFilled OrderState = "partially_filled"
+	OrderFilled          OrderState = "filled"
+	OrderCanceled        OrderState = "canceled"
+)
+
+// OrderInfo — ордер из /trade/order: сколько и по какой средней исполнено.
+type OrderInfo struct {
+	OrdID     string
+	ClOrdID   string
+	InstID    string
+	OrdType   string // market / limit / post_only
+	State     OrderState
+	Px        float64 // лимитная цена (0 — market)
+	Sz        float64
+	AvgPx     float64 // средняя цена исполнения (0 — ничего не исполнено)
+	AccFillSz float64 // исполнено контрактов
+}
+
+// Done — ордер больше не исполняется (filled или canceled).
+func (o OrderInfo) Done() bool {
+	return o.State == OrderFilled || o.State == OrderCanceled
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// BotClOrdPrefix — префикс clOrdId/algoClOrdId всех ордеров бота.
+// По нему при старте сессии отличаем свои позиции и алго от ручных.
+const BotClOrdPrefix = "tb"
+
+// Виды ордеров в теге.
+const (
+	ClOrdOpen = "op"
+	ClOrdSL   = "sl"
+	ClOrdTP   = "tp"
+)
+
+var clOrdSeq atomic.Uint32
+
+// NewClOrdID — tb<kind><время base36><seq>: OKX допускает только [a-zA-Z0-9], до 32 символов.
+func NewClOrdID(kind string) string {
+	return BotClOrdPrefix + kind +
+		strconv.FormatInt(time.Now().UnixMilli(), 36) +
+		strconv.FormatUint(uint64(clOrdSeq.Add(1)%1296), 36)
+}
+
+func IsBotClOrdID(id string) bool {
+	return strings.HasPrefix(id, BotClOrdPrefix)
+}
+
+// ClOrdKind — вид ордера бота из тега ("" — не наш).
+func ClOrdKind(id string) string {
+	if !IsBotClOrdID(id) || len(id) < len(BotClOrdPrefix)+2 {
+		return ""
+	}
+	return id[len(BotClOrdPrefix) : len(BotClOrdPrefix)+2]
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// PaperAccount — снимок paper-счёта юзера: переживает рестарт бота,
+// чтобы пробный прогон шёл на том же балансе и с теми же позициями.
+type PaperAccount struct {
+	Seq       int             `json:"seq"`
+	Balance   float64         `json:"balance"`
+	Lever     map[string]int  `json:"lever"` // instId:posSide -> плечо
+	Positions []PaperPosition `json:"positions"`
+	Algos     []PaperAlgo     `json:"algos"`
+	Orders    []PaperOrder    `json:"orders"` // только живые ордера на открытие
+	Fills     []Fill          `json:"fills"`
+}
+
+type PaperPosition struct {
+	InstID   string    `json:"inst_id"`
+	PosSide  string    `json:"pos_side"`
+	Size     float64   `json:"size"`
+	AvgPx    float64   `json:"avg_px"`
+	CtVal    float64   `json:"ct_val"`
+	Lever    int       `json:"lever"`
+	Last     float64   `json:"last"`
+	Realized float64   `json:"realized"`
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+type PaperAlgo struct {
+	ID        string  `json:"id"`
+	ClOrdID   string  `json:"cl_ord_id"`
+	Seq       int     `json:"seq"`
+	InstID    string  `json:"inst_id"`
+	PosSide   string  `json:"pos_side"`
+	Size      float64 `json:"size"`
+	TriggerPx float64 `json:"trigger_px"`
+	IsTP      bool    `json:"is_tp"`
+}
+
+type PaperOrder struct {
+	ID        string    `json:"id"`
+	ClOrdID   string    `json:"cl_ord_id"`
+	InstID    string    `json:"inst_id"`
+	PosSide   string    `json:"pos_side"`
+	OrdType   string    `json:"ord_type"`
+	Px        float64   `json:"px"`
+	Lever     int       `json:"lever"`
+	Sz        float64   `json:"sz"`
+	CtVal     float64   `json:"ct_val"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -10,14 +10,16 @@ type OpenPosition struct {
 	HoldVol      float64 // pos
 	HoldAvgPrice float64 // avgPx
 	Leverage     int     // lever
-	Realised     float64 // пока не заполняем (0)
+	Realised     float64 // realizedPnl позиции (OKX)
 
 	Size             float64
 	EntryPrice       float64
 	LastPrice        float64
 	UnrealizedPnl    float64
 	UnrealizedPnlPct float64
+	NotionalUSD      float64 // notionalUsd
 	Side             string
+	OpenedAt         time.Time // cTime
 
 	Qty     float64
 	Entry   float64
@@ -27,29 +29,69 @@ type OpenPosition struct {
 	Updated time.Time
 }
 
+// AlgoOrder — висящий условный ордер (SL/TP) из /trade/orders-algo-pending.
+type AlgoOrder struct {
+	AlgoID      string
+	AlgoClOrdID string // у ордеров бота — с префиксом BotClOrdPrefix
+	InstID      string
+	PosSide     string // long/short
+	Side        string // buy/sell
+	Size        float64
+	SLTriggerPx float64
+	TPTriggerPx float64
+	CreatedAt   time.Time
+}
+
+// Closing — ордер закрывает позицию posSide (sell для long, buy для short).
+func (a AlgoOrder) Closing() bool {
+	return (a.PosSide == "long" && a.Side == "sell") || (a.PosSide == "short" && a.Side == "buy")
+}
+
+// MaxTPLevels — ступеней TP-лестницы на позицию (массив, чтобы стейт оставался comparable).
+const MaxTPLevels = 4
+
+// TPOrder — ступень TP-лестницы, выставленная на бирже.
+type TPOrder struct {
+	AlgoID string  `json:"algo_id"`
+	Price  float64 `json:"price"`
+	Size   float64 `json:"size"`
+	Frac   float64 `json:"frac"` // доля стартового объёма
+}
+
 type PositionTrailState struct {
-	InstID  string
-	PosSide string // "long"/"short"
+	InstID  string `json:"inst_id"`
+	PosSide string `json:"pos_side"` // "long"/"short"
 
-	Entry    float64
-	SL       float64
-	TP       float64
-	RiskDist float64
-	TickSz   float64
+	Entry    float64 `json:"entry"`
+	SL       float64 `json:"sl"`
+	TP       float64 `json:"tp"`
+	RiskDist float64 `json:"risk_dist"`
+	TickSz   float64 `json:"tick_sz"`
 
-	AlgoID string
-	Size   float64
+	AlgoID string  `json:"algo_id"`
+	Size   float64 `json:"size"`
 
-	MFE float64 // long: max price; short: min price
+	MFE float64 `json:"mfe"` // long: max price; short: min price
 
-	MovedToBE    bool
-	LockedProfit bool
+	MovedToBE    bool `json:"moved_to_be"`
+	LockedProfit bool `json:"locked_profit"`
 
-	OpenedAt time.Time // ✅ когда открыли позицию (для тайм-стопа)
+	OpenedAt time.Time `json:"opened_at"` // ✅ когда открыли позицию (для тайм-стопа)
 
-	LastTrailEnd time.Time
-	LastTrailAt  time.Time
-	TookPartial  bool
+	LastTrailEnd time.Time `json:"last_trail_end"`
+	LastTrailAt  time.Time `json:"last_trail_at"`
+	TookPartial  bool      `json:"took_partial"`
+
+	// лестница TP: ступени на бирже (Size == 0 — ступень исполнена или её нет)
+	TPs   [MaxTPLevels]TPOrder `json:"tps"`
+	LotSz float64              `json:"lot_sz"`
+	MinSz float64              `json:"min_sz"`
+
+	// журнал сделок
+	TradeID   int64   `json:"trade_id"`
+	InitSize  float64 `json:"init_size"`  // стартовый объём — база для R частичных выходов
+	RiskUSDT  float64 `json:"risk_usdt"`  // 1R в USDT на стартовый объём
+	RealizedR float64 `json:"realized_r"` // уже зафиксировано частичными выходами
 }
 
 func (st *PositionTrailState) UpdateMFE(high, low float64) {
@@ -65,6 +107,22 @@ func (st *PositionTrailState) UpdateMFE(high, low float64) {
 	}
 }
 
+// ExitR — результат выхода size контрактов по px в R стартового объёма.
+func (st *PositionTrailState) ExitR(px, size float64) float64 {
+	init := st.InitSize
+	if init <= 0 {
+		init = st.Size
+	}
+	if st.RiskDist <= 0 || init <= 0 {
+		return 0
+	}
+	dir := 1.0
+	if st.PosSide == "short" {
+		dir = -1.0
+	}
+	return dir * (px - st.Entry) * size / (st.RiskDist * init)
+}
+
 func (st *PositionTrailState) MaybeTrailOnClosedCandle(
 	high float64,
 	low float64,
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// PrivateEventKind — канал приватного WS OKX.
+type PrivateEventKind string
+
+const (
+	PrivConnected PrivateEventKind = "connected" // login + подписки прошли (и после реконнекта)
+	PrivPositions PrivateEventKind = "positions"
+	PrivOrders    PrivateEventKind = "orders"
+	PrivAlgos     PrivateEventKind = "orders-algo"
+	PrivAccount   PrivateEventKind = "account"
+)
+
+// PrivateEvent — одно сообщение приватного WS.
+type PrivateEvent struct {
+	Kind PrivateEventKind
+
+	// positions: только изменившиеся позиции; HoldVol == 0 — позиция закрыта
+	Positions []OpenPosition
+	Orders    []OrderUpdate
+	Algos     []AlgoUpdate
+	// account: equity в USDT
+	Equity float64
+}
+
+// OrderUpdate — состояние ордера из канала orders.
+type OrderUpdate struct {
+	OrdID       string
+	ClOrdID     string
+	AlgoID      string // ордер, порождённый сработавшим алго
+	AlgoClOrdID string
+	InstID      string
+	Side        string // buy/sell
+	PosSide     string // long/short
+	State       string // live / partially_filled / filled / canceled
+	FillPx      float64
+	FillSz      float64 // последнее исполнение
+	AccFillSz   float64
+	AvgPx       float64
+	Fee         float64 // последнее исполнение, со знаком OKX
+	Pnl         float64 // fillPnl последнего исполнения
+	ReduceOnly  bool
+	UpdatedAt   time.Time
+}
+
+// Closing — ордер закрывает позицию posSide.
+func (o OrderUpdate) Closing() bool {
+	return (o.PosSide == "long" && o.Side == "sell") || (o.PosSide == "short" && o.Side == "buy")
+}
+
+// AlgoUpdate — состояние алго-ордера из канала orders-algo.
+type AlgoUpdate struct {
+	AlgoOrder
+	State    string  // live / effective / canceled / order_failed
+	ActualPx float64 // цена исполнения после срабатывания
+	ActualSz float64
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RiskState — счётчики риск-лимитов юзера: переживают рестарт бота,
+// чтобы риск-стоп, серия убытков и пик equity не обнулялись перезапуском.
+type RiskState struct {
+	Day        time.Time `json:"day"` // UTC-день, к которому относится DayStartEq
+	DayStartEq float64   `json:"day_start_eq"`
+	PeakEq     float64   `json:"peak_eq"`
+	LossStreak int       `json:"loss_streak"`
+
+	HaltedUntil time.Time `json:"halted_until"`
+	HaltReason  string    `json:"halt_reason"`
+}
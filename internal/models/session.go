@@ -16,11 +16,19 @@ type CachedPos struct {
 	Entry     float64
 	LastPx    float64
 	UpdatedAt time.Time
+
+	// с биржи на момент обновления кеша
+	Upl      float64 // нереализованный PnL, USDT
+	UplPct   float64 // % от маржи
+	Realised float64 // реализованный PnL позиции (частичные выходы, комиссии, funding)
+	Notional float64 // notional в USD (0 — биржа не прислала)
 }
 
 type OpenResult struct {
 	PosSide  string  // "long"/"short"
 	TPAlgoID string  // TP algoId
 	SLAlgoID string  // SL algoId
-	Entry    float64 // если уточнил, иначе params.Entry
+	Entry    float64 // фактическая средняя входа (avgPx), если биржа её отдала, иначе params.Entry
+
+	TPs [MaxTPLevels]TPOrder // лестница TP с algoId (если задана)
 }
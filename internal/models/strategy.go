@@ -10,14 +10,23 @@ const (
 	StrategyDonchianV2 StrategyType = "donchianV2"
 )
 
+// DefaultStrategy — на неё подписан юзер с пустым списком стратегий
+// (так было до того, как стратегий стало несколько).
+const DefaultStrategy = StrategyDonchianV2
+
 type Signal struct {
 	InstID    string
 	TF        string // "15m"
 	Side      Side   // "BUY" / "SELL"
 	Price     float64
-	Strategy  StrategyType // "donchian_v2_htf1h"
+	Strategy  StrategyType // какой движок дал сигнал (Hub проставляет Engine.Type())
 	Reason    string
 	CreatedAt time.Time
+
+	// для стопа от волатильности/канала (0 — нет данных)
+	ATR    float64 // ATR сигнального ТФ на момент сигнала
+	ChHigh float64 // верх канала Donchian до пробоя
+	ChLow  float64 // низ канала Donchian до пробоя
 }
 
 // Side как у тебя в раннере: "BUY"/"SELL" или пустая строка.
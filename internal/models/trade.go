@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// TradeStatus — стадия сделки в журнале.
+type TradeStatus string
+
+const (
+	TradeSignal   TradeStatus = "signal"   // сигнал дошёл до сессии
+	TradeRejected TradeStatus = "rejected" // лимит / отказ / таймаут подтверждения
+	TradeFailed   TradeStatus = "failed"   // ошибка расчёта или открытия
+	TradeOpen     TradeStatus = "open"
+	TradeClosed   TradeStatus = "closed"
+)
+
+// TradeEventKind — что произошло со сделкой.
+type TradeEventKind string
+
+const (
+	EvSignal    TradeEventKind = "signal"
+	EvConfirmed TradeEventKind = "confirmed"
+	EvRejected  TradeEventKind = "rejected"
+	EvDownsized TradeEventKind = "downsized" // риск урезан (корреляция)
+	EvEntry     TradeEventKind = "entry"     // итог лимитного входа: исполнено, добор по рынку
+	EvOpened    TradeEventKind = "opened"
+	EvSLPlaced  TradeEventKind = "sl_placed"
+	EvTPPlaced  TradeEventKind = "tp_placed"
+	EvSLMoved   TradeEventKind = "sl_moved"
+	EvPartial   TradeEventKind = "partial"
+	EvTimeStop  TradeEventKind = "time_stop"
+	EvAlgoFired TradeEventKind = "algo_fired" // SL/TP сработал на бирже
+	EvClosed    TradeEventKind = "closed"
+	EvError     TradeEventKind = "error"
+)
+
+// Trade — строка журнала сделок.
+type Trade struct {
+	ID     int64
+	UserID int64
+
+	InstID      string
+	PosSide     string
+	Side        Side
+	Strategy    StrategyType
+	TF          string
+	Reason      string  // причина сигнала
+	SignalPrice float64 // цена в сигнале
+
+	Status TradeStatus
+	Params *TradeParams
+
+	OrderID  string
+	SLAlgoID string
+	TPAlgoID string
+	// все ордера входа: в limit_market это лимит и добор по рынку
+	// (пусто у старых сделок — только OrderID)
+	EntryOrderIDs []string
+
+	EntryPrice float64
+	ExitPrice  float64
+	ExitReason string  // SL / TP / TIME_STOP / ...
+	PnlUSDT    float64 // до сверки — оценка по R, после — чистый реализованный
+	PnlR       float64
+
+	// сверка с исполнениями OKX (fills + funding)
+	GrossUSDT   float64 // PnL исполнений без комиссий
+	FeeUSDT     float64 // комиссии, со знаком (минус — списано)
+	FundingUSDT float64 // funding за время удержания, со знаком
+
+	OpenedAt time.Time
+	ClosedAt time.Time
+}
+
+// PnLTotals — итог закрытых сделок за период.
+type PnLTotals struct {
+	From        time.Time
+	Trades      int
+	Wins        int
+	PnlUSDT     float64
+	PnlR        float64
+	FeeUSDT     float64
+	FundingUSDT float64
+	Unsettled   int // ещё не сверены с биржей — в сумме по оценке
+}
+
+// TradeEvent — одно событие сделки; Data пишется в jsonb как есть.
+type TradeEvent struct {
+	TradeID int64
+	Kind    TradeEventKind
+	Data    map[string]any
+}
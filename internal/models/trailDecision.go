@@ -8,3 +8,11 @@ type TrailDecision struct {
 	CloseSize float64 // ✅ частичное закрытие
 
 }
+
+// TrailMarket — рынок для стадии Trail (0 — нет данных, стадия пропускается).
+type TrailMarket struct {
+	Last    float64 // последняя цена: SL не ставим по другую сторону от неё
+	ATR     float64 // ATR 15m
+	BarLow  float64 // min low последних TrailBars закрытых 15m баров
+	BarHigh float64 // max high
+}
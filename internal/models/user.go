@@ -14,6 +14,10 @@ type UserSettings struct {
 	Name     string   `json:"name"`
 	Step     string   `json:"step"`
 	Settings Settings `json:"settings"`
+
+	// Enabled — бот запущен кнопкой «▶️ Запустить бота»; после рестарта сервиса
+	// такие юзеры включаются автоматически. Пишется только через SetEnabled.
+	Enabled bool `json:"enabled"`
 }
 type Settings struct {
 	TradingSettings TradingSettings
@@ -33,6 +37,10 @@ type TradingSettings struct {
 	OKXAPIKey     string `json:"okx_api_key"`
 	OKXAPISecret  string `json:"okx_api_secret"`
 	OKXPassphrase string `json:"okx_passphrase"`
+	// demo trading OKX (paper-аккаунт, ключи тоже demo)
+	OKXDemo bool `json:"okx_demo"`
+	// paper trading: виртуальный счёт бота, ключи OKX не нужны
+	Paper bool `json:"paper"`
 
 	// исполнение/риск (юзер правит)
 	Leverage         int     `json:"leverage"`
@@ -43,10 +51,84 @@ type TradingSettings struct {
 	StopPct      float64 `json:"stop_pct"`       // расстояние SL (%)
 	TakeProfitRR float64 `json:"take_profit_rr"` // TP в R
 
+	// лестница TP (пусто — один TP на TakeProfitRR на весь объём);
+	// доли от стартового объёма, остаток без TP уходит трейлингу
+	TPLadder []TPLevel `json:"tp_ladder"`
+
+	// режим стопа: от StopPct, k·ATR сигнального ТФ или за противоположной границей канала
+	StopMode    StopMode `json:"stop_mode"`     // "" = StopModePercent
+	StopATRMult float64  `json:"stop_atr_mult"` // k для StopModeATR, напр. 2.0
+
+	// вход: по рынку или лимитом от цены сигнала с таймаутом
+	EntryMode      EntryMode     `json:"entry_mode"`       // "" = EntryMarket
+	EntryOffsetPct float64       `json:"entry_offset_pct"` // сдвиг лимита, %: >0 — уступаем рынку, <0 — ждём отката
+	EntryTimeout   time.Duration `json:"entry_timeout"`    // сколько ждём исполнения лимита
+
+	// стратегии, чьи сигналы берём (пусто — только DefaultStrategy)
+	Strategies []StrategyType `json:"strategies"`
+
 	// подтверждения
 	ConfirmRequired   bool          `json:"confirm_required"`
 	ConfirmTimeout    time.Duration `json:"confirm_timeout"`
 	CooldownPerSymbol time.Duration `json:"cooldown_per_symbol"`
+
+	// портфельные лимиты (0 — выключено), считаются по кешу позиций сессии
+	MaxTotalNotionalX float64 `json:"max_total_notional_x"` // суммарный notional, в equity
+	MaxNetNotionalX   float64 `json:"max_net_notional_x"`   // |long − short| notional, в equity
+	MaxSameDirection  int     `json:"max_same_direction"`   // позиций в одну сторону
+
+	// корреляция с уже открытыми позициями той же стороны (0 — выключено)
+	MaxCorrelation float64 `json:"max_correlation"` // порог, напр. 0.8
+	CorrSizeMult   float64 `json:"corr_size_mult"`  // 0 — пропустить сигнал, иначе доля риска (0.5)
+
+	// риск-лимиты сессии (0 — выключено): при нарушении бот не берёт сигналы
+	// до следующего дня UTC или ручного сброса
+	MaxDailyLossPct  float64 `json:"max_daily_loss_pct"`  // % от equity на начало дня
+	MaxDailyLossUSDT float64 `json:"max_daily_loss_usdt"` // USDT за день
+	MaxConsecLosses  int     `json:"max_consec_losses"`   // убыточных сделок подряд
+	MaxDrawdownPct   float64 `json:"max_drawdown_pct"`    // % от пика equity
+	FlattenOnHalt    bool    `json:"flatten_on_halt"`     // закрыть все позиции при срабатывании
+}
+
+// TPLevel — ступень TP-лестницы: закрыть Frac стартового объёма на R.
+type TPLevel struct {
+	R    float64 `json:"r"`
+	Frac float64 `json:"frac"`
+}
+
+type StopMode string
+
+const (
+	StopModePercent StopMode = "percent"
+	StopModeATR     StopMode = "atr"
+	StopModeChannel StopMode = "channel" // противоположная граница Donchian
+)
+
+type EntryMode string
+
+const (
+	EntryMarket      EntryMode = "market"
+	EntryLimit       EntryMode = "limit"        // лимит, по таймауту остаток отменяется
+	EntryPostOnly    EntryMode = "post_only"    // только maker, по таймауту остаток отменяется
+	EntryLimitMarket EntryMode = "limit_market" // лимит, по таймауту остаток по рынку
+)
+
+// Subscribed — юзер берёт сигналы стратегии st.
+func (ts TradingSettings) Subscribed(st StrategyType) bool {
+	if len(ts.Strategies) == 0 {
+		return st == DefaultStrategy
+	}
+	for _, s := range ts.Strategies {
+		if s == st {
+			return true
+		}
+	}
+	return false
+}
+
+// RiskLimitsEnabled — задан хотя бы один риск-лимит.
+func (ts TradingSettings) RiskLimitsEnabled() bool {
+	return ts.MaxDailyLossPct > 0 || ts.MaxDailyLossUSDT > 0 || ts.MaxConsecLosses > 0 || ts.MaxDrawdownPct > 0
 }
 
 type TrailingConfig struct {
@@ -65,8 +147,23 @@ type TrailingConfig struct {
 	PartialEnabled   bool    `yaml:"partial_enabled"`    // true
 	PartialTriggerR  float64 `yaml:"partial_trigger_r"`  // 0.9
 	PartialCloseFrac float64 `yaml:"partial_close_frac"` // 0.5
+
+	// --- Trail после Lock: SL тянется за ценой каждый 15m слот ---
+	TrailMode    TrailMode `yaml:"trail_mode"`     // "" выкл | atr | r | bars
+	TrailATRMult float64   `yaml:"trail_atr_mult"` // atr: SL = MFE ∓ k×ATR(15m)
+	TrailDistR   float64   `yaml:"trail_dist_r"`   // r: SL = MFE ∓ d×R
+	TrailBars    int       `yaml:"trail_bars"`     // bars: SL за min low / max high N прошлых 15m баров
 }
 
+type TrailMode string
+
+const (
+	TrailModeOff  TrailMode = ""
+	TrailModeATR  TrailMode = "atr"  // chandelier: от MFE на k×ATR
+	TrailModeR    TrailMode = "r"    // от MFE на фиксированные d×R
+	TrailModeBars TrailMode = "bars" // за экстремум N прошлых баров
+)
+
 func NewTradingSettingsFromDefaults(userID int64, cfg *config.Config) *UserSettings {
 	return &UserSettings{
 		UserID: userID,
@@ -79,6 +176,12 @@ func NewTradingSettingsFromDefaults(userID int64, cfg *config.Config) *UserSetti
 
 				StopPct:      cfg.UserDefaults.DefaultStopPct,
 				TakeProfitRR: cfg.UserDefaults.DefaultTakeProfitRR,
+				StopMode:     StopMode(cfg.UserDefaults.DefaultStopMode),
+				StopATRMult:  cfg.UserDefaults.DefaultStopATRMult,
+
+				EntryMode:      EntryMode(cfg.UserDefaults.DefaultEntryMode),
+				EntryOffsetPct: cfg.UserDefaults.DefaultEntryOffsetPct,
+				EntryTimeout:   cfg.UserDefaults.DefaultEntryTimeout,
 
 				ConfirmRequired:   cfg.UserDefaults.DefaultConfirmRequired,
 				CooldownPerSymbol: cfg.UserDefaults.DefaultCooldownPerSymbol,
@@ -94,6 +197,10 @@ func NewTradingSettingsFromDefaults(userID int64, cfg *config.Config) *UserSetti
 				PartialEnabled:   cfg.DefaultTrailing.PartialEnabled,
 				PartialTriggerR:  cfg.DefaultTrailing.PartialTriggerR,
 				PartialCloseFrac: cfg.DefaultTrailing.PartialCloseFrac,
+				TrailMode:        TrailMode(cfg.DefaultTrailing.TrailMode),
+				TrailATRMult:     cfg.DefaultTrailing.TrailATRMult,
+				TrailDistR:       cfg.DefaultTrailing.TrailDistR,
+				TrailBars:        cfg.DefaultTrailing.TrailBars,
 			},
 		},
 	}
@@ -14,8 +14,9 @@ func Module() fx.Option {
 		fx.Provide(
 			bootstrap.NewWatchlist, // -> bootstrap.Watchlist
 			bootstrap.NewWarmuper,  // -> bootstrap.Warmuper
+			bootstrap.NewResumer,   // -> bootstrap.Resumer
 		),
-		fx.Invoke(func(lc fx.Lifecycle, cfg *config.Config, wl *bootstrap.OkxWatchlist, wu *bootstrap.Warmuper) {
+		fx.Invoke(func(lc fx.Lifecycle, cfg *config.Config, wl *bootstrap.OkxWatchlist, wu *bootstrap.Warmuper, rs *bootstrap.Resumer) {
 			lc.Append(fx.Hook{
 				OnStart: func(ctx context.Context) error {
 					// тут твой func1 скорее всего и был
@@ -23,9 +24,15 @@ func Module() fx.Option {
 						syms := wl.TopVolatile(cfg.Strategy.WatchTopN)
 						if err := wu.Warmup(ctx, syms); err != nil {
 							log.Printf("[BOOT] warmup error: %v", err)
-							return
+						} else {
+							log.Printf("[BOOT] warmup done: %d symbols", len(syms))
+						}
+
+						// юзеры, у которых бот был запущен до рестарта; даже без
+						// прогрева — открытым позициям нужен трейлинг
+						if err := rs.Resume(ctx); err != nil {
+							log.Printf("[BOOT] resume users error: %v", err)
 						}
-						log.Printf("[BOOT] warmup done: %d symbols", len(syms))
 					}()
 					return nil
 				},
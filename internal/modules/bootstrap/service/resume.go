@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"log"
+	"trade_bot/internal/modules/telegram_bot/service"
+	"trade_bot/internal/modules/telegram_bot/service/pg"
+	"trade_bot/internal/runner/router"
+)
+
+// Resumer — после рестарта сервиса заново включает юзеров,
+// у которых бот был запущен (user_settings.enabled).
+type Resumer struct {
+	users  *pg.User
+	router *router.Router
+	n      *service.Telegram
+}
+
+func NewResumer(users *pg.User, r *router.Router, n *service.Telegram) *Resumer {
+	return &Resumer{
+		users:  users,
+		router: r,
+		n:      n,
+	}
+}
+
+func (r *Resumer) Resume(ctx context.Context) error {
+	users, err := r.users.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	resumed := 0
+	for _, u := range users {
+		if !u.Enabled {
+			continue
+		}
+		if !r.router.EnableUser(u, r.n) {
+			continue
+		}
+		_, _ = r.n.Send(ctx, u.UserID, "▶️ Сервис перезапущен — бот снова работает для этого аккаунта.")
+		resumed++
+	}
+
+	log.Printf("[BOOT] resumed %d users", resumed)
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
+	"trade_bot/internal/helper"
 	"trade_bot/internal/models"
 	"trade_bot/internal/modules/config"
 	okxws "trade_bot/internal/modules/okx_websocket/service"
@@ -11,10 +13,13 @@ import (
 	"trade_bot/internal/modules/telegram_bot/service"
 )
 
+// warmupMargin — свечей сверх WarmupBars движков.
+const warmupMargin = 30
+
 type Warmuper struct {
 	mx  *okxws.Client
 	hub *strategy.Hub
-	n   *service.Telegram
+	n   strategy.ServiceNotifier
 
 	cfg *config.Config
 
@@ -37,8 +42,9 @@ func (w *Warmuper) Warmup(ctx context.Context, symbols []string) error {
 		return nil
 	}
 
-	ltfNeed := w.cfg.Strategy.DonchianPeriod + 30
-	htfNeed := w.cfg.Strategy.HTFEmaSlow + 30
+	// история под самый «долгий» из включённых движков, с запасом
+	ltfNeed := w.hub.WarmupBars(w.cfg.Strategy.LTF) + warmupMargin
+	htfNeed := w.hub.WarmupBars(w.cfg.Strategy.HTF) + warmupMargin
 
 	// Публичное сообщение в канал (на русском)
 	w.n.SendService(ctx, fmt.Sprintf(
@@ -49,6 +55,25 @@ func (w *Warmuper) Warmup(ctx context.Context, symbols []string) error {
 		len(symbols), w.cfg.Strategy.LTF, ltfNeed, w.cfg.Strategy.HTF, htfNeed,
 	))
 
+	// есть снапшот движков — докачиваем только разрыв с ним
+	restored := 0
+	for _, sym := range symbols {
+		if !w.hub.RestoredEnd(sym, w.cfg.Strategy.LTF).IsZero() {
+			restored++
+		}
+	}
+	if restored > 0 {
+		w.n.SendService(ctx,
+			"♻️ Состояние стратегий восстановлено из снапшота: %d из %d инструментов — докачиваем только пропущенные свечи",
+			restored, len(symbols),
+		)
+	}
+
+	// живые свечи до конца докачки символа ждут в Hub: история идёт первой
+	for _, sym := range symbols {
+		w.hub.BeginBackfill(sym)
+	}
+
 	var wg sync.WaitGroup
 	var firstErr error
 	var mu sync.Mutex
@@ -59,13 +84,14 @@ func (w *Warmuper) Warmup(ctx context.Context, symbols []string) error {
 
 		go func() {
 			defer wg.Done()
+			defer w.hub.EndBackfill(ctx, sym)
 
 			// ограничитель параллелизма
 			w.sem <- struct{}{}
 			defer func() { <-w.sem }()
 
 			// 1) HTF
-			htf, err := w.mx.GetCandles(ctx, sym, w.cfg.Strategy.HTF, htfNeed)
+			htf, err := w.mx.GetCandles(ctx, sym, w.cfg.Strategy.HTF, w.need(sym, w.cfg.Strategy.HTF, htfNeed))
 			if err != nil {
 				mu.Lock()
 				if firstErr == nil {
@@ -75,7 +101,7 @@ func (w *Warmuper) Warmup(ctx context.Context, symbols []string) error {
 				return
 			}
 			for _, c := range htf {
-				w.hub.OnTick(ctx, okxws.OutTick{
+				w.hub.Backfill(ctx, okxws.OutTick{
 					InstID:    sym,
 					Timeframe: w.cfg.Strategy.HTF,
 					Candle: models.CandleTick{
@@ -90,7 +116,7 @@ func (w *Warmuper) Warmup(ctx context.Context, symbols []string) error {
 			}
 
 			// 2) LTF
-			ltf, err := w.mx.GetCandles(ctx, sym, w.cfg.Strategy.LTF, ltfNeed)
+			ltf, err := w.mx.GetCandles(ctx, sym, w.cfg.Strategy.LTF, w.need(sym, w.cfg.Strategy.LTF, ltfNeed))
 			if err != nil {
 				mu.Lock()
 				if firstErr == nil {
@@ -100,7 +126,7 @@ func (w *Warmuper) Warmup(ctx context.Context, symbols []string) error {
 				return
 			}
 			for _, c := range ltf {
-				w.hub.OnTick(ctx, okxws.OutTick{
+				w.hub.Backfill(ctx, okxws.OutTick{
 					InstID:    sym,
 					Timeframe: w.cfg.Strategy.LTF,
 					Candle: models.CandleTick{
@@ -135,3 +161,14 @@ func (w *Warmuper) Warmup(ctx context.Context, symbols []string) error {
 	)
 	return nil
 }
+
+// need — сколько свечей tf запросить: full без снапшота, иначе разрыв с ним
+// (+2 на незакрытую и граничную; лишнее Hub отбросит по End).
+func (w *Warmuper) need(sym, tf string, full int) int {
+	last := w.hub.RestoredEnd(sym, tf)
+	d := helper.TFDuration(tf)
+	if last.IsZero() || d <= 0 {
+		return full
+	}
+	return min(int(time.Since(last)/d)+2, full)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	okxws "trade_bot/internal/modules/okx_websocket/service"
+	strategy "trade_bot/internal/modules/strategy/service"
+	"trade_bot/internal/okxfake"
+)
+
+const inst = "BTC-USDT-SWAP"
+
+type nopNotifier struct{}
+
+func (nopNotifier) SendService(context.Context, string, ...any) {}
+
+// recEngine — запоминает свечи, дошедшие до движка.
+type recEngine struct {
+	mu   sync.Mutex
+	seen map[string][]time.Time // tf -> End по порядку
+}
+
+func (e *recEngine) OnCandle(ct models.CandleTick) (models.Signal, bool, bool) {
+	e.mu.Lock()
+	e.seen[ct.TimeframeRaw] = append(e.seen[ct.TimeframeRaw], ct.End)
+	e.mu.Unlock()
+	return models.Signal{}, false, false
+}
+func (e *recEngine) IsReady(string) bool       { return false }
+func (e *recEngine) Dump(string) string        { return "" }
+func (e *recEngine) Name() string              { return "rec" }
+func (e *recEngine) Type() models.StrategyType { return models.DefaultStrategy }
+func (e *recEngine) WarmupBars(string) int     { return 5 } // с запасом — 35 свечей
+
+func history(n int, tf time.Duration) []models.CandleTick {
+	start := time.Now().Truncate(tf).Add(-time.Duration(n) * tf)
+	out := make([]models.CandleTick, n)
+	for i := range out {
+		s := start.Add(time.Duration(i) * tf)
+		out[i] = models.CandleTick{InstID: inst, Open: 100, High: 101, Low: 99, Close: 100, Start: s, End: s.Add(tf)}
+	}
+	return out
+}
+
+// Прогрев с /market/candles стенда: движки получают последние need свечей
+// LTF и HTF по порядку, живая свеча во время прогрева — после истории.
+func TestWarmupFromMarketCandles(t *testing.T) {
+	srv := okxfake.New()
+	defer srv.Close()
+	srv.AddInstrument(okxfake.Instrument{InstID: inst, TickSz: 0.1, Last: 100})
+	ltf, htf := history(40, 15*time.Minute), history(40, time.Hour)
+	srv.SetHistory(inst, "15m", ltf)
+	srv.SetHistory(inst, "1H", htf)
+
+	cfg := &config.Config{}
+	cfg.OKX.RestURL = srv.URL()
+	cfg.Strategy.LTF, cfg.Strategy.HTF = "15m", "1h"
+
+	eng := &recEngine{seen: make(map[string][]time.Time)}
+	hub := strategy.NewHub(cfg, nil, make(chan models.Signal, 8), make(chan models.CandleTick, 8),
+		strategy.Engines{eng}, strategy.NewCorrelation(cfg), strategy.NewATR(cfg))
+	w := &Warmuper{mx: okxws.NewClient(cfg, nil), hub: hub, n: nopNotifier{}, cfg: cfg, sem: make(chan struct{}, 8)}
+
+	// живая свеча из WS, пока REST ещё не ответил
+	last := ltf[len(ltf)-1]
+	live := models.CandleTick{Open: 100, High: 101, Low: 99, Close: 100, Start: last.End, End: last.End.Add(15 * time.Minute)}
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		hub.OnTick(context.Background(), okxws.OutTick{InstID: inst, Timeframe: "15m", Candle: live})
+	}()
+
+	if err := w.Warmup(context.Background(), []string{inst}); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(tf string, want []time.Time) {
+		t.Helper()
+		got := eng.seen[tf]
+		if len(got) != len(want) {
+			t.Fatalf("%s: engine got %d candles, want %d", tf, len(got), len(want))
+		}
+		for i := range want {
+			if !got[i].Equal(want[i]) {
+				t.Fatalf("%s: candle %d End = %s, want %s", tf, i, got[i], want[i])
+			}
+		}
+	}
+	ends := func(cs []models.CandleTick) []time.Time {
+		out := make([]time.Time, len(cs))
+		for i, c := range cs {
+			out[i] = c.End
+		}
+		return out
+	}
+	check("1h", ends(htf[5:]))
+	check("15m", append(ends(ltf[5:]), live.End))
+}
@@ -43,6 +43,12 @@ type Config struct {
 		Passphrase string `yaml:"passphrase"`
 	} `yaml:"okx_ws"`
 
+	// ✅ Адреса OKX (можно подменить на локальный стенд в тестах)
+	OKX OKXEndpoints `yaml:"okx"`
+
+	// ✅ Paper trading (виртуальная биржа для юзеров без ключей)
+	Paper PaperConfig `yaml:"paper"`
+
 	// ✅ Стратегия (общая для сервиса, одинаковая для всех юзеров)
 	Strategy StrategyConfig `yaml:"strategy"`
 
@@ -51,7 +57,42 @@ type Config struct {
 	DefaultTrailing TrailingDefaultsConfig `yaml:"default_trailing"`
 }
 
+type OKXEndpoints struct {
+	RestURL string `yaml:"rest_url"` // https://www.okx.com
+	WSURL   string `yaml:"ws_url"`   // wss://ws.okx.com:8443
+
+	// demo trading: REST тот же (+ заголовок x-simulated-trading: 1), WS — отдельные хосты
+	DemoRestURL string `yaml:"demo_rest_url"` // https://www.okx.com
+	DemoWSURL   string `yaml:"demo_ws_url"`   // wss://wspap.okx.com:8443
+}
+
+// Rest — базовый REST-адрес (без /api/v5).
+func (e OKXEndpoints) Rest(demo bool) string {
+	if demo {
+		return e.DemoRestURL
+	}
+	return e.RestURL
+}
+
+// WS — базовый WS-адрес (без /ws/v5/...).
+func (e OKXEndpoints) WS(demo bool) string {
+	if demo {
+		return e.DemoWSURL
+	}
+	return e.WSURL
+}
+
+type PaperConfig struct {
+	StartBalance float64 `yaml:"start_balance"` // 10000 USDT
+	SlippagePct  float64 `yaml:"slippage_pct"`  // 0.05 (%), против нас
+	TakerFeePct  float64 `yaml:"taker_fee_pct"` // 0.05 (%), как taker на OKX
+	MakerFeePct  float64 `yaml:"maker_fee_pct"` // 0.02 (%), исполнение лимитных ордеров
+}
+
 type StrategyConfig struct {
+	// движки, которые Hub гоняет на общем потоке свечей (имена из реестра стратегий)
+	Engines []string `yaml:"engines"` // напр [donchianV2]
+
 	LTF string `yaml:"ltf"` // напр "15m"
 	HTF string `yaml:"htf"` // напр "1h"
 
@@ -70,6 +111,42 @@ type StrategyConfig struct {
 	ProgressEvery   time.Duration `yaml:"progress_every"`
 
 	WatchTopN int `yaml:"watch_top_n"`
+
+	// окно корреляции доходностей, в LTF-барах (96 × 15m = сутки)
+	CorrWindow int `yaml:"corr_window"`
+
+	// период ATR для стопа от волатильности (по Уайлдеру)
+	ATRPeriod int `yaml:"atr_period"`
+
+	// EMA + RSI pullback (движок emarsi)
+	EMARSI EMARSIConfig `yaml:"emarsi"`
+
+	// снапшот состояния движков между рестартами
+	State StrategyStateConfig `yaml:"state"`
+}
+
+// StrategyStateConfig — куда и как часто Hub сохраняет состояние движков.
+// На старте снапшот восстанавливается, и прогрев докачивает только разрыв.
+type StrategyStateConfig struct {
+	Path   string        `yaml:"path"`    // "" — не сохраняем, полный прогрев
+	Every  time.Duration `yaml:"every"`   // 5m: периодическое сохранение (плюс на остановке)
+	MaxAge time.Duration `yaml:"max_age"` // 2h: снапшот старше — игнорируем
+}
+
+// EMARSIConfig — тренд по EMA старшего ТФ, вход по откату RSI на LTF
+// и его возврату через уровень. LTF/HTF — общие из StrategyConfig.
+type EMARSIConfig struct {
+	HTFEmaFast int `yaml:"htf_ema_fast"` // 50
+	HTFEmaSlow int `yaml:"htf_ema_slow"` // 200
+
+	RSIPeriod     int     `yaml:"rsi_period"`        // 14
+	PullbackLong  float64 `yaml:"pullback_long"`     // 40: в аптренде RSI ниже — откат, возврат выше — вход
+	PullbackShort float64 `yaml:"pullback_short"`    // 60: зеркально для даунтренда
+	MaxPullback   int     `yaml:"max_pullback_bars"` // 8: сколько LTF-баров откат ждёт возврата
+	MinBodyPct    float64 `yaml:"min_body_pct"`      // 0.001: тело сигнальной свечи в сторону входа
+
+	MinWarmupLTF int `yaml:"min_warmup_ltf"` // 30
+	MinWarmupHTF int `yaml:"min_warmup_htf"` // 200
 }
 
 type UserDefaultsConfig struct {
@@ -80,6 +157,12 @@ type UserDefaultsConfig struct {
 	DefaultRiskPct          float64 `yaml:"default_risk_pct"`
 	DefaultStopPct          float64 `yaml:"default_stop_pct"`
 	DefaultTakeProfitRR     float64 `yaml:"default_take_profit_rr"`
+	DefaultStopMode         string  `yaml:"default_stop_mode"`     // percent | atr | channel
+	DefaultStopATRMult      float64 `yaml:"default_stop_atr_mult"` // k для atr
+
+	DefaultEntryMode      string        `yaml:"default_entry_mode"`       // market | limit | post_only | limit_market
+	DefaultEntryOffsetPct float64       `yaml:"default_entry_offset_pct"` // сдвиг лимита от цены сигнала, %
+	DefaultEntryTimeout   time.Duration `yaml:"default_entry_timeout"`    // ожидание исполнения лимита
 
 	DefaultConfirmRequired   bool          `yaml:"default_confirm_required"`
 	DefaultConfirmTimeout    time.Duration `yaml:"default_confirm_timeout"`
@@ -102,6 +185,12 @@ type TrailingDefaultsConfig struct {
 	PartialEnabled   bool    `yaml:"partial_enabled"`    // true
 	PartialTriggerR  float64 `yaml:"partial_trigger_r"`  // 0.9
 	PartialCloseFrac float64 `yaml:"partial_close_frac"` // 0.5
+
+	// --- Trail после Lock: SL тянется за ценой каждый 15m слот ---
+	TrailMode    string  `yaml:"trail_mode"`     // "" выкл | atr | r | bars
+	TrailATRMult float64 `yaml:"trail_atr_mult"` // atr: SL = MFE ∓ k×ATR(15m)
+	TrailDistR   float64 `yaml:"trail_dist_r"`   // r: SL = MFE ∓ d×R
+	TrailBars    int     `yaml:"trail_bars"`     // bars: SL за min low / max high N прошлых 15m баров
 }
 
 func NewConfig() (*Config, error) {
@@ -117,6 +206,9 @@ func NewConfig() (*Config, error) {
 			PartialEnabled:   true,
 			PartialTriggerR:  0.9,
 			PartialCloseFrac: 0.5,
+			TrailATRMult:     3.0,
+			TrailDistR:       1.0,
+			TrailBars:        3,
 		},
 	}
 
@@ -126,7 +218,20 @@ func NewConfig() (*Config, error) {
 	cfg.Service.AdminPort = 3001
 	cfg.Service.Workers = 5
 
+	// OKX defaults
+	cfg.OKX.RestURL = "https://www.okx.com"
+	cfg.OKX.WSURL = "wss://ws.okx.com:8443"
+	cfg.OKX.DemoRestURL = "https://www.okx.com"
+	cfg.OKX.DemoWSURL = "wss://wspap.okx.com:8443"
+
+	// Paper defaults
+	cfg.Paper.StartBalance = 10_000
+	cfg.Paper.SlippagePct = 0.05
+	cfg.Paper.TakerFeePct = 0.05
+	cfg.Paper.MakerFeePct = 0.02
+
 	// Strategy defaults
+	cfg.Strategy.Engines = []string{"donchianV2"}
 	cfg.Strategy.LTF = "15m"
 	cfg.Strategy.HTF = "1h"
 	cfg.Strategy.DonchianPeriod = 20
@@ -140,6 +245,24 @@ func NewConfig() (*Config, error) {
 	cfg.Strategy.ExpectedSymbols = 100
 	cfg.Strategy.ProgressEvery = 2 * time.Minute
 	cfg.Strategy.WatchTopN = 100
+	cfg.Strategy.CorrWindow = 96
+	cfg.Strategy.ATRPeriod = 14
+	cfg.Strategy.State = StrategyStateConfig{
+		Path:   "data/strategy_state.json",
+		Every:  5 * time.Minute,
+		MaxAge: 2 * time.Hour,
+	}
+	cfg.Strategy.EMARSI = EMARSIConfig{
+		HTFEmaFast:    50,
+		HTFEmaSlow:    200,
+		RSIPeriod:     14,
+		PullbackLong:  40,
+		PullbackShort: 60,
+		MaxPullback:   8,
+		MinBodyPct:    0.001,
+		MinWarmupLTF:  30,
+		MinWarmupHTF:  200,
+	}
 
 	// User defaults (только стартовые)
 	cfg.UserDefaults.DefaultLeverage = 15
@@ -148,6 +271,11 @@ func NewConfig() (*Config, error) {
 	cfg.UserDefaults.DefaultRiskPct = 0.5
 	cfg.UserDefaults.DefaultStopPct = 3.0
 	cfg.UserDefaults.DefaultTakeProfitRR = 2.0
+	cfg.UserDefaults.DefaultStopMode = "percent"
+	cfg.UserDefaults.DefaultStopATRMult = 2.0
+	cfg.UserDefaults.DefaultEntryMode = "market"
+	cfg.UserDefaults.DefaultEntryOffsetPct = 0.05
+	cfg.UserDefaults.DefaultEntryTimeout = 20 * time.Second
 	cfg.UserDefaults.DefaultConfirmRequired = true
 	cfg.UserDefaults.DefaultConfirmTimeout = 30 * time.Second
 	cfg.UserDefaults.DefaultCooldownPerSymbol = 6 * time.Hour
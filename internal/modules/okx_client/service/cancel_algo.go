@@ -21,8 +21,8 @@ func (c *Client) CancelAlgo(ctx context.Context, instId, algoId string) error {
 	ts := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
 	sign := c.sign(ts, http.MethodPost, requestPath, string(payload))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
-		"https://www.okx.com"+requestPath, bytes.NewReader(payload))
+	req, err := c.newRequest(ctx, http.MethodPost,
+		requestPath, bytes.NewReader(payload))
 	if err != nil {
 		return fmt.Errorf("CancelAlgo new request: %w", err)
 	}
@@ -92,7 +92,7 @@ func (c *Client) CloseMarket(ctx context.Context, instID, posSide string, size f
 	ts := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
 	sign := c.sign(ts, method, requestPath, bodyStr)
 
-	req, _ := http.NewRequestWithContext(ctx, method, "https://www.okx.com"+requestPath, strings.NewReader(bodyStr))
+	req, _ := c.newRequest(ctx, method, requestPath, strings.NewReader(bodyStr))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("OK-ACCESS-KEY", c.apiKey)
 	req.Header.Set("OK-ACCESS-SIGN", sign)
@@ -15,6 +15,7 @@ import (
 	"sync"
 	"time"
 	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
 
 	"github.com/gorilla/websocket"
 )
@@ -27,17 +28,37 @@ type Client struct {
 	apiKey    string
 	apiSecret string
 	passph    string
+
+	restURL string
+	wsURL   string
+	demo    bool // demo trading: x-simulated-trading: 1
 }
 
-func NewClient(cfg *models.UserSettings) *Client {
+func NewClient(cfg *config.Config, user *models.UserSettings) *Client {
+	demo := user.Settings.TradingSettings.OKXDemo
 	return &Client{
 		//prices:    make(map[string]float64),
 		http:      &http.Client{Timeout: 10 * time.Second},
 		wsDialer:  &websocket.Dialer{},
-		apiKey:    cfg.Settings.TradingSettings.OKXAPIKey,
-		apiSecret: cfg.Settings.TradingSettings.OKXAPISecret,
-		passph:    cfg.Settings.TradingSettings.OKXPassphrase,
+		apiKey:    user.Settings.TradingSettings.OKXAPIKey,
+		apiSecret: user.Settings.TradingSettings.OKXAPISecret,
+		passph:    user.Settings.TradingSettings.OKXPassphrase,
+		restURL:   cfg.OKX.Rest(demo),
+		wsURL:     cfg.OKX.WS(demo),
+		demo:      demo,
+	}
+}
+
+// newRequest — запрос к REST OKX (базовый адрес + demo-заголовок).
+func (c *Client) newRequest(ctx context.Context, method, requestPath string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.restURL+requestPath, body)
+	if err != nil {
+		return nil, err
 	}
+	if c.demo {
+		req.Header.Set("x-simulated-trading", "1")
+	}
+	return req, nil
 }
 
 func (c *Client) SetPrice(symbol string, price float64) {
@@ -53,7 +74,7 @@ func (c *Client) StreamPrices(ctx context.Context, instID string) <-chan float64
 	go func() {
 		defer close(ch)
 
-		url := "wss://ws.okx.com:8443/ws/v5/public"
+		url := c.wsURL + "/ws/v5/public"
 		retry := 0
 
 		for {
@@ -159,7 +180,7 @@ func (c *Client) SetLeverage(ctx context.Context, instID string, lever int, posS
 	ts := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
 	sign := c.sign(ts, method, requestPath, bodyStr)
 
-	req, _ := http.NewRequestWithContext(ctx, method, "https://www.okx.com"+requestPath, strings.NewReader(bodyStr))
+	req, _ := c.newRequest(ctx, method, requestPath, strings.NewReader(bodyStr))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("OK-ACCESS-KEY", c.apiKey)
 	req.Header.Set("OK-ACCESS-SIGN", sign)
@@ -233,6 +254,7 @@ func (c *Client) PlaceMarket(
 		"posSide": posSide,
 		"ordType": "market",
 		"sz":      sz,
+		"clOrdId": models.NewClOrdID(models.ClOrdOpen), // тег бота для сверки после рестарта
 	}
 
 	// ⚠️ ВАЖНО: здесь НЕТ tp/sl полей, чтобы избежать 54070
@@ -245,10 +267,10 @@ func (c *Client) PlaceMarket(
 	ts := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
 	sign := c.sign(ts, method, requestPath, bodyStr)
 
-	req, _ := http.NewRequestWithContext(
+	req, _ := c.newRequest(
 		ctx,
 		method,
-		"https://www.okx.com"+requestPath,
+		requestPath,
 		strings.NewReader(bodyStr),
 	)
 	req.Header.Set("Content-Type", "application/json")
@@ -295,8 +317,71 @@ func (c *Client) PlaceMarket(
 }
 
 func (c *Client) USDTBalance(ctx context.Context) (float64, error) {
+	bal, err := c.balance(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// сначала пытаемся взять availEq по USDT
+	for _, d := range bal.Details {
+		if d.Ccy != "USDT" {
+			continue
+		}
+		if d.AvailEq != "" {
+			if v, err := strconv.ParseFloat(d.AvailEq, 64); err == nil {
+				return v, nil
+			}
+		}
+		if d.Eq != "" {
+			if v, err := strconv.ParseFloat(d.Eq, 64); err == nil {
+				return v, nil
+			}
+		}
+	}
+
+	// fallback: totalEq
+	if bal.TotalEq != "" {
+		if v, err := strconv.ParseFloat(bal.TotalEq, 64); err == nil {
+			return v, nil
+		}
+	}
+	return 0, errors.New("okx balance: USDT not found")
+}
+
+// Equity — equity по USDT (с нереализованным PnL), в отличие от USDTBalance (свободные средства).
+func (c *Client) Equity(ctx context.Context) (float64, error) {
+	bal, err := c.balance(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range bal.Details {
+		if d.Ccy == "USDT" && d.Eq != "" {
+			if v, err := strconv.ParseFloat(d.Eq, 64); err == nil {
+				return v, nil
+			}
+		}
+	}
+	if bal.TotalEq != "" {
+		if v, err := strconv.ParseFloat(bal.TotalEq, 64); err == nil {
+			return v, nil
+		}
+	}
+	return 0, errors.New("okx balance: USDT not found")
+}
+
+type balanceData struct {
+	TotalEq string `json:"totalEq"`
+	Details []struct {
+		Ccy     string `json:"ccy"`
+		Eq      string `json:"eq"`
+		AvailEq string `json:"availEq"`
+	} `json:"details"`
+}
+
+// balance — /account/balance по USDT.
+func (c *Client) balance(ctx context.Context) (*balanceData, error) {
 	if c.apiKey == "" || c.apiSecret == "" || c.passph == "" {
-		return 0, errors.New("okx creds empty (ключ/секрет/пасфраза)")
+		return nil, errors.New("okx creds empty (ключ/секрет/пасфраза)")
 	}
 
 	requestPath := "/api/v5/account/balance?ccy=USDT"
@@ -305,7 +390,7 @@ func (c *Client) USDTBalance(ctx context.Context) (float64, error) {
 	ts := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
 	sign := c.sign(ts, method, requestPath, bodyStr)
 
-	req, _ := http.NewRequestWithContext(ctx, method, "https://www.okx.com"+requestPath, nil)
+	req, _ := c.newRequest(ctx, method, requestPath, nil)
 	req.Header.Set("OK-ACCESS-KEY", c.apiKey)
 	req.Header.Set("OK-ACCESS-SIGN", sign)
 	req.Header.Set("OK-ACCESS-TIMESTAMP", ts)
@@ -313,58 +398,27 @@ func (c *Client) USDTBalance(ctx context.Context) (float64, error) {
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	rb, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode/100 != 2 {
-		return 0, fmt.Errorf("http %d (balance): %s", resp.StatusCode, string(rb))
+		return nil, fmt.Errorf("http %d (balance): %s", resp.StatusCode, string(rb))
 	}
 
 	var wrap struct {
-		Code string `json:"code"`
-		Msg  string `json:"msg"`
-		Data []struct {
-			TotalEq string `json:"totalEq"`
-			Details []struct {
-				Ccy     string `json:"ccy"`
-				Eq      string `json:"eq"`
-				AvailEq string `json:"availEq"`
-			} `json:"details"`
-		} `json:"data"`
+		Code string        `json:"code"`
+		Msg  string        `json:"msg"`
+		Data []balanceData `json:"data"`
 	}
 	if err := json.Unmarshal(rb, &wrap); err != nil {
-		return 0, err
+		return nil, err
 	}
 	if wrap.Code != "0" || len(wrap.Data) == 0 {
-		return 0, fmt.Errorf("okx balance error: code=%s msg=%s", wrap.Code, wrap.Msg)
-	}
-
-	// сначала пытаемся взять availEq по USDT
-	for _, d := range wrap.Data[0].Details {
-		if d.Ccy != "USDT" {
-			continue
-		}
-		if d.AvailEq != "" {
-			if v, err := strconv.ParseFloat(d.AvailEq, 64); err == nil {
-				return v, nil
-			}
-		}
-		if d.Eq != "" {
-			if v, err := strconv.ParseFloat(d.Eq, 64); err == nil {
-				return v, nil
-			}
-		}
+		return nil, fmt.Errorf("okx balance error: code=%s msg=%s", wrap.Code, wrap.Msg)
 	}
-
-	// fallback: totalEq
-	if wrap.Data[0].TotalEq != "" {
-		if v, err := strconv.ParseFloat(wrap.Data[0].TotalEq, 64); err == nil {
-			return v, nil
-		}
-	}
-	return 0, errors.New("okx balance: USDT not found")
+	return &wrap.Data[0], nil
 }
 
 func formatPx(v float64) string {
@@ -492,8 +546,8 @@ func (c *Client) sign(ts, method, requestPath, body string) string {
 }
 
 func (c *Client) getLastPrice(ctx context.Context, instID string) (float64, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
-		"https://www.okx.com/api/v5/market/ticker?instId="+instID,
+	req, err := c.newRequest(ctx, http.MethodGet,
+		"/api/v5/market/ticker?instId="+instID,
 		nil)
 	if err != nil {
 		return 0, fmt.Errorf("build ticker request: %w", err)
@@ -537,7 +591,7 @@ func (c *Client) getLastPrice(ctx context.Context, instID string) (float64, erro
 //		defer close(ch)
 //
 //		channel := "candle" + timeframe
-//		url := "wss://ws.okx.com:8443/ws/v5/public"
+//		url := c.wsURL + "/ws/v5/public"
 //
 //		for {
 //			log.Printf("[WS] connect %s %s", channel, instID)
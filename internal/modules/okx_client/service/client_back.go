@@ -39,55 +39,68 @@ func (c *Client) OpenPositions(ctx context.Context) ([]models.OpenPosition, erro
 
 	res := make([]models.OpenPosition, 0, len(respData.Data))
 	for _, d := range respData.Data {
-		// размер позиции (контракты)
-		pos, _ := strconv.ParseFloat(d.Pos, 64)
-		// средняя цена входа
-		avgPx, _ := strconv.ParseFloat(d.AvgPx, 64)
-		// последнее значение (last или mark)
-		lastPx, _ := strconv.ParseFloat(d.Last, 64)
-		if lastPx == 0 {
-			lastPx, _ = strconv.ParseFloat(d.MarkPx, 64)
-		}
-		// нереализованный PnL
-		upl, _ := strconv.ParseFloat(d.UplLastPx, 64)
-		if upl == 0 {
-			upl, _ = strconv.ParseFloat(d.Upl, 64)
-		}
-		// нереализованный PnL в доле (0.0123 → 1.23%)
-		uplRatio, _ := strconv.ParseFloat(d.UplRatioLastPx, 64)
-		if uplRatio == 0 {
-			uplRatio, _ = strconv.ParseFloat(d.UplRatio, 64)
-		}
-		uplPct := uplRatio * 100.0
+		res = append(res, d.toModel())
+	}
+	return res, nil
+}
 
-		// реализованный PnL (можно взять settledPnl + realizedPnl, но оставим одно)
-		realised, _ := strconv.ParseFloat(d.RealizedPnl, 64)
+// toModel — позиция OKX в упрощённую структуру бота.
+func (d PositionData) toModel() models.OpenPosition {
+	// размер позиции (контракты)
+	pos, _ := strconv.ParseFloat(d.Pos, 64)
+	// средняя цена входа
+	avgPx, _ := strconv.ParseFloat(d.AvgPx, 64)
+	// последнее значение (last или mark)
+	lastPx, _ := strconv.ParseFloat(d.Last, 64)
+	if lastPx == 0 {
+		lastPx, _ = strconv.ParseFloat(d.MarkPx, 64)
+	}
+	// нереализованный PnL
+	upl, _ := strconv.ParseFloat(d.UplLastPx, 64)
+	if upl == 0 {
+		upl, _ = strconv.ParseFloat(d.Upl, 64)
+	}
+	// нереализованный PnL в доле (0.0123 → 1.23%)
+	uplRatio, _ := strconv.ParseFloat(d.UplRatioLastPx, 64)
+	if uplRatio == 0 {
+		uplRatio, _ = strconv.ParseFloat(d.UplRatio, 64)
+	}
+	uplPct := uplRatio * 100.0
 
-		lev, _ := strconv.Atoi(d.Lever)
+	// реализованный PnL (можно взять settledPnl + realizedPnl, но оставим одно)
+	realised, _ := strconv.ParseFloat(d.RealizedPnl, 64)
 
-		side := "long"
-		pt := 1
-		if d.PosSide == "short" {
-			side = "short"
-			pt = 2
-		}
+	lev, _ := strconv.Atoi(d.Lever)
+	notional, _ := strconv.ParseFloat(d.NotionalUsd, 64)
 
-		res = append(res, models.OpenPosition{
-			Symbol:           d.InstId,
-			PositionType:     pt,
-			HoldVol:          pos,
-			HoldAvgPrice:     avgPx,
-			Leverage:         lev,
-			Realised:         realised,
-			Size:             pos, // для удобства — то же, что HoldVol
-			EntryPrice:       avgPx,
-			LastPrice:        lastPx,
-			UnrealizedPnl:    upl,
-			UnrealizedPnlPct: uplPct, // в процентах
-			Side:             side,   // "long" / "short"
-		})
+	var openedAt time.Time
+	if ms, err := strconv.ParseInt(d.CTime, 10, 64); err == nil && ms > 0 {
+		openedAt = time.UnixMilli(ms)
+	}
+
+	side := "long"
+	pt := 1
+	if d.PosSide == "short" {
+		side = "short"
+		pt = 2
+	}
+
+	return models.OpenPosition{
+		Symbol:           d.InstId,
+		PositionType:     pt,
+		HoldVol:          pos,
+		HoldAvgPrice:     avgPx,
+		Leverage:         lev,
+		Realised:         realised,
+		Size:             pos, // для удобства — то же, что HoldVol
+		EntryPrice:       avgPx,
+		LastPrice:        lastPx,
+		UnrealizedPnl:    upl,
+		UnrealizedPnlPct: uplPct, // в процентах
+		NotionalUSD:      notional,
+		Side:             side, // "long" / "short"
+		OpenedAt:         openedAt,
 	}
-	return res, nil
 }
 
 func (c *Client) generateRequest(ctx context.Context, method string, requestPath string, body string) *http.Request {
@@ -95,7 +108,7 @@ func (c *Client) generateRequest(ctx context.Context, method string, requestPath
 	msg := ts + strings.ToUpper(method) + requestPath + body
 	h := hmac.New(sha256.New, []byte(c.apiSecret))
 	h.Write([]byte(msg))
-	req, _ := http.NewRequestWithContext(ctx, method, "https://www.okx.com"+requestPath, nil)
+	req, _ := c.newRequest(ctx, method, requestPath, nil)
 	req.Header.Set("OK-ACCESS-KEY", c.apiKey)
 	req.Header.Set("OK-ACCESS-SIGN", base64.StdEncoding.EncodeToString(h.Sum(nil)))
 	req.Header.Set("OK-ACCESS-TIMESTAMP", ts)
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// Fills — исполнения SWAP с since (/api/v5/trade/fills-history, до 3 месяцев),
+// постранично по 100, новые первыми.
+func (c *Client) Fills(ctx context.Context, since time.Time) ([]models.Fill, error) {
+	var (
+		res   []models.Fill
+		after string
+	)
+	for page := 0; page < 50; page++ {
+		requestPath := "/api/v5/trade/fills-history?instType=SWAP&limit=100&begin=" +
+			strconv.FormatInt(since.UnixMilli(), 10)
+		if after != "" {
+			requestPath += "&after=" + after
+		}
+
+		resp, err := c.http.Do(c.generateRequest(ctx, http.MethodGet, requestPath, ""))
+		if err != nil {
+			return nil, fmt.Errorf("Fills do: %w", err)
+		}
+		rb, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return nil, fmt.Errorf("Fills http %d: %s", resp.StatusCode, string(rb))
+		}
+
+		var wrap struct {
+			Code string `json:"code"`
+			Msg  string `json:"msg"`
+			Data []struct {
+				BillID  string `json:"billId"`
+				TradeID string `json:"tradeId"`
+				OrdID   string `json:"ordId"`
+				ClOrdID string `json:"clOrdId"`
+				InstID  string `json:"instId"`
+				Side    string `json:"side"`
+				PosSide string `json:"posSide"`
+				FillPx  string `json:"fillPx"`
+				FillSz  string `json:"fillSz"`
+				Fee     string `json:"fee"`
+				FillPnl string `json:"fillPnl"`
+				Ts      string `json:"ts"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(rb, &wrap); err != nil {
+			return nil, fmt.Errorf("Fills decode: %w; body=%s", err, string(rb))
+		}
+		if wrap.Code != "0" {
+			return nil, fmt.Errorf("Fills error: code=%s msg=%s", wrap.Code, wrap.Msg)
+		}
+
+		for _, d := range wrap.Data {
+			px, _ := strconv.ParseFloat(d.FillPx, 64)
+			sz, _ := strconv.ParseFloat(d.FillSz, 64)
+			fee, _ := strconv.ParseFloat(d.Fee, 64)
+			pnl, _ := strconv.ParseFloat(d.FillPnl, 64)
+			var ts time.Time
+			if ms, err := strconv.ParseInt(d.Ts, 10, 64); err == nil && ms > 0 {
+				ts = time.UnixMilli(ms)
+			}
+			res = append(res, models.Fill{
+				BillID:  d.BillID,
+				TradeID: d.TradeID,
+				OrdID:   d.OrdID,
+				ClOrdID: d.ClOrdID,
+				InstID:  d.InstID,
+				Side:    d.Side,
+				PosSide: d.PosSide,
+				Px:      px,
+				Sz:      sz,
+				Fee:     fee,
+				Pnl:     pnl,
+				Ts:      ts,
+			})
+		}
+
+		if len(wrap.Data) < 100 {
+			break
+		}
+		after = wrap.Data[len(wrap.Data)-1].BillID
+	}
+	return res, nil
+}
+
+// FundingBills — списания/начисления funding по SWAP с since
+// (/api/v5/account/bills?type=8, последние 7 дней), постранично по 100.
+func (c *Client) FundingBills(ctx context.Context, since time.Time) ([]models.Bill, error) {
+	var (
+		res   []models.Bill
+		after string
+	)
+	for page := 0; page < 50; page++ {
+		requestPath := "/api/v5/account/bills?instType=SWAP&type=8&limit=100&begin=" +
+			strconv.FormatInt(since.UnixMilli(), 10)
+		if after != "" {
+			requestPath += "&after=" + after
+		}
+
+		resp, err := c.http.Do(c.generateRequest(ctx, http.MethodGet, requestPath, ""))
+		if err != nil {
+			return nil, fmt.Errorf("FundingBills do: %w", err)
+		}
+		rb, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return nil, fmt.Errorf("FundingBills http %d: %s", resp.StatusCode, string(rb))
+		}
+
+		var wrap struct {
+			Code string `json:"code"`
+			Msg  string `json:"msg"`
+			Data []struct {
+				BillID string `json:"billId"`
+				InstID string `json:"instId"`
+				Type   string `json:"type"`
+				BalChg string `json:"balChg"`
+				Ts     string `json:"ts"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(rb, &wrap); err != nil {
+			return nil, fmt.Errorf("FundingBills decode: %w; body=%s", err, string(rb))
+		}
+		if wrap.Code != "0" {
+			return nil, fmt.Errorf("FundingBills error: code=%s msg=%s", wrap.Code, wrap.Msg)
+		}
+
+		for _, d := range wrap.Data {
+			chg, _ := strconv.ParseFloat(d.BalChg, 64)
+			var ts time.Time
+			if ms, err := strconv.ParseInt(d.Ts, 10, 64); err == nil && ms > 0 {
+				ts = time.UnixMilli(ms)
+			}
+			res = append(res, models.Bill{
+				BillID: d.BillID,
+				InstID: d.InstID,
+				Type:   d.Type,
+				BalChg: chg,
+				Ts:     ts,
+			})
+		}
+
+		if len(wrap.Data) < 100 {
+			break
+		}
+		after = wrap.Data[len(wrap.Data)-1].BillID
+	}
+	return res, nil
+}
@@ -13,10 +13,10 @@ import (
 )
 
 func (c *Client) GetInstrumentMeta(ctx context.Context, instID string) (models.Instrument, error) {
-	req, err := http.NewRequestWithContext(
+	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
-		"https://www.okx.com/api/v5/public/instruments?instType=SWAP&instId="+url.QueryEscape(instID),
+		"/api/v5/public/instruments?instType=SWAP&instId="+url.QueryEscape(instID),
 		nil,
 	)
 	if err != nil {
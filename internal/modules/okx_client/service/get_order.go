@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"trade_bot/internal/models"
+)
+
+// GetOrder — состояние ордера (/api/v5/trade/order): state, avgPx, accFillSz.
+func (c *Client) GetOrder(ctx context.Context, instID, ordID string) (models.OrderInfo, error) {
+	q := url.Values{}
+	q.Set("instId", instID)
+	q.Set("ordId", ordID)
+	requestPath := "/api/v5/trade/order?" + q.Encode()
+
+	resp, err := c.http.Do(c.generateRequest(ctx, http.MethodGet, requestPath, ""))
+	if err != nil {
+		return models.OrderInfo{}, fmt.Errorf("GetOrder do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	rb, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return models.OrderInfo{}, fmt.Errorf("GetOrder http %d: %s", resp.StatusCode, string(rb))
+	}
+
+	var wrap struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			OrdID     string `json:"ordId"`
+			ClOrdID   string `json:"clOrdId"`
+			InstID    string `json:"instId"`
+			OrdType   string `json:"ordType"`
+			State     string `json:"state"`
+			Px        string `json:"px"`
+			Sz        string `json:"sz"`
+			AvgPx     string `json:"avgPx"`
+			AccFillSz string `json:"accFillSz"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rb, &wrap); err != nil {
+		return models.OrderInfo{}, fmt.Errorf("GetOrder decode: %w; body=%s", err, string(rb))
+	}
+	if wrap.Code != "0" || len(wrap.Data) == 0 {
+		return models.OrderInfo{}, fmt.Errorf("GetOrder error: code=%s msg=%s", wrap.Code, wrap.Msg)
+	}
+
+	d := wrap.Data[0]
+	px, _ := strconv.ParseFloat(d.Px, 64)
+	sz, _ := strconv.ParseFloat(d.Sz, 64)
+	avgPx, _ := strconv.ParseFloat(d.AvgPx, 64)
+	fillSz, _ := strconv.ParseFloat(d.AccFillSz, 64)
+
+	state := models.OrderState(d.State)
+	if state == "mmp_canceled" {
+		state = models.OrderCanceled
+	}
+	return models.OrderInfo{
+		OrdID:     d.OrdID,
+		ClOrdID:   d.ClOrdID,
+		InstID:    d.InstID,
+		OrdType:   d.OrdType,
+		State:     state,
+		Px:        px,
+		Sz:        sz,
+		AvgPx:     avgPx,
+		AccFillSz: fillSz,
+	}, nil
+}
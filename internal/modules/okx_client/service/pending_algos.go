@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// PendingAlgos — все висящие conditional/oco алго-ордера SWAP
+// (/api/v5/trade/orders-algo-pending), постранично по 100.
+func (c *Client) PendingAlgos(ctx context.Context) ([]models.AlgoOrder, error) {
+	var (
+		res   []models.AlgoOrder
+		after string
+	)
+	for page := 0; page < 50; page++ {
+		requestPath := "/api/v5/trade/orders-algo-pending?instType=SWAP&ordType=conditional,oco&limit=100"
+		if after != "" {
+			requestPath += "&after=" + after
+		}
+
+		resp, err := c.http.Do(c.generateRequest(ctx, http.MethodGet, requestPath, ""))
+		if err != nil {
+			return nil, fmt.Errorf("PendingAlgos do: %w", err)
+		}
+		rb, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return nil, fmt.Errorf("PendingAlgos http %d: %s", resp.StatusCode, string(rb))
+		}
+
+		var wrap struct {
+			Code string `json:"code"`
+			Msg  string `json:"msg"`
+			Data []struct {
+				AlgoID      string `json:"algoId"`
+				AlgoClOrdID string `json:"algoClOrdId"`
+				InstID      string `json:"instId"`
+				Side        string `json:"side"`
+				PosSide     string `json:"posSide"`
+				Sz          string `json:"sz"`
+				SlTriggerPx string `json:"slTriggerPx"`
+				TpTriggerPx string `json:"tpTriggerPx"`
+				CTime       string `json:"cTime"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(rb, &wrap); err != nil {
+			return nil, fmt.Errorf("PendingAlgos decode: %w; body=%s", err, string(rb))
+		}
+		if wrap.Code != "0" {
+			return nil, fmt.Errorf("PendingAlgos error: code=%s msg=%s", wrap.Code, wrap.Msg)
+		}
+
+		for _, d := range wrap.Data {
+			sz, _ := strconv.ParseFloat(d.Sz, 64)
+			sl, _ := strconv.ParseFloat(d.SlTriggerPx, 64)
+			tp, _ := strconv.ParseFloat(d.TpTriggerPx, 64)
+			var created time.Time
+			if ms, err := strconv.ParseInt(d.CTime, 10, 64); err == nil && ms > 0 {
+				created = time.UnixMilli(ms)
+			}
+			res = append(res, models.AlgoOrder{
+				AlgoID:      d.AlgoID,
+				AlgoClOrdID: d.AlgoClOrdID,
+				InstID:      d.InstID,
+				PosSide:     d.PosSide,
+				Side:        d.Side,
+				Size:        sz,
+				SLTriggerPx: sl,
+				TPTriggerPx: tp,
+				CreatedAt:   created,
+			})
+		}
+
+		if len(wrap.Data) < 100 {
+			break
+		}
+		after = wrap.Data[len(wrap.Data)-1].AlgoID
+	}
+	return res, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// PlaceLimit — лимитный ордер на открытие (/api/v5/trade/order, ordType limit/post_only).
+// side: 1 = открыть long, 3 = открыть short. Post-only, который исполнился бы
+// как taker, OKX сразу отменяет — это видно по GetOrder.
+func (c *Client) PlaceLimit(
+	ctx context.Context,
+	instID string,
+	vol, px float64,
+	side, leverage int,
+	postOnly bool,
+) (string, error) {
+	if c.apiKey == "" || c.apiSecret == "" || c.passph == "" {
+		return "", errors.New("okx creds empty (ключ/секрет/пасфраза)")
+	}
+	if vol <= 0 {
+		return "", fmt.Errorf("PlaceLimit: size <= 0")
+	}
+	if px <= 0 {
+		return "", fmt.Errorf("PlaceLimit: px <= 0")
+	}
+
+	var sideStr, posSide string
+	switch side {
+	case 1:
+		sideStr, posSide = "buy", "long"
+	case 3:
+		sideStr, posSide = "sell", "short"
+	default:
+		return "", fmt.Errorf("unsupported side %d", side)
+	}
+
+	if leverage > 0 {
+		_ = c.SetLeverage(ctx, instID, leverage, posSide)
+	}
+
+	ordType := "limit"
+	if postOnly {
+		ordType = "post_only"
+	}
+
+	bodyMap := map[string]any{
+		"instId":  instID,
+		"tdMode":  "cross",
+		"side":    sideStr,
+		"posSide": posSide,
+		"ordType": ordType,
+		"px":      formatPrice(px),
+		"sz":      formatSize(vol),
+		"clOrdId": models.NewClOrdID(models.ClOrdOpen),
+	}
+
+	bodyBytes, _ := json.Marshal(bodyMap)
+	bodyStr := string(bodyBytes)
+
+	requestPath := "/api/v5/trade/order"
+	method := "POST"
+	ts := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	sign := c.sign(ts, method, requestPath, bodyStr)
+
+	req, _ := c.newRequest(ctx, method, requestPath, strings.NewReader(bodyStr))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("OK-ACCESS-KEY", c.apiKey)
+	req.Header.Set("OK-ACCESS-SIGN", sign)
+	req.Header.Set("OK-ACCESS-TIMESTAMP", ts)
+	req.Header.Set("OK-ACCESS-PASSPHRASE", c.passph)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	rb, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("PlaceLimit http %d: %s", resp.StatusCode, string(rb))
+	}
+
+	var wrap struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			OrdID string `json:"ordId"`
+			SCode string `json:"sCode"`
+			SMsg  string `json:"sMsg"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rb, &wrap); err != nil {
+		return "", err
+	}
+	if len(wrap.Data) == 0 {
+		return "", fmt.Errorf("PlaceLimit: empty data code=%s msg=%s", wrap.Code, wrap.Msg)
+	}
+	d := wrap.Data[0]
+	if wrap.Code != "0" || d.SCode != "0" {
+		return "", fmt.Errorf("PlaceLimit okx error: code=%s msg=%s sCode=%s sMsg=%s", wrap.Code, wrap.Msg, d.SCode, d.SMsg)
+	}
+	return d.OrdID, nil
+}
+
+// CancelOrder — отмена невыполненного остатка (/api/v5/trade/cancel-order).
+func (c *Client) CancelOrder(ctx context.Context, instID, ordID string) error {
+	bodyBytes, _ := json.Marshal(map[string]string{"instId": instID, "ordId": ordID})
+	bodyStr := string(bodyBytes)
+
+	const requestPath = "/api/v5/trade/cancel-order"
+	ts := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	sign := c.sign(ts, http.MethodPost, requestPath, bodyStr)
+
+	req, err := c.newRequest(ctx, http.MethodPost, requestPath, strings.NewReader(bodyStr))
+	if err != nil {
+		return fmt.Errorf("CancelOrder new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("OK-ACCESS-KEY", c.apiKey)
+	req.Header.Set("OK-ACCESS-SIGN", sign)
+	req.Header.Set("OK-ACCESS-TIMESTAMP", ts)
+	req.Header.Set("OK-ACCESS-PASSPHRASE", c.passph)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("CancelOrder do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	rb, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("CancelOrder http %d: %s", resp.StatusCode, string(rb))
+	}
+
+	var wrap struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			OrdID string `json:"ordId"`
+			SCode string `json:"sCode"`
+			SMsg  string `json:"sMsg"`
+		} `json:"data"`
+	}
+	_ = json.Unmarshal(rb, &wrap)
+	if wrap.Code != "0" || len(wrap.Data) == 0 || wrap.Data[0].SCode != "0" {
+		return fmt.Errorf("CancelOrder reject: code=%s msg=%s RAW=%s", wrap.Code, wrap.Msg, string(rb))
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strings"
 	"time"
+	"trade_bot/internal/models"
 
 	"github.com/bytedance/sonic"
 )
@@ -49,6 +50,13 @@ func (c *Client) PlaceSingleAlgo(
 		"sz":      formatSize(size),
 	}
 
+	// тег бота: по нему сверка при старте находит свои SL/TP
+	if isTP {
+		body["algoClOrdId"] = models.NewClOrdID(models.ClOrdTP)
+	} else {
+		body["algoClOrdId"] = models.NewClOrdID(models.ClOrdSL)
+	}
+
 	if isTP {
 		body["tpTriggerPx"] = formatPrice(triggerPx)
 		body["tpOrdPx"] = "-1"
@@ -69,10 +77,10 @@ func (c *Client) PlaceSingleAlgo(
 	ts := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
 	sign := c.sign(ts, http.MethodPost, requestPath, string(payload))
 
-	req, err := http.NewRequestWithContext(
+	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
-		"https://www.okx.com"+requestPath,
+		requestPath,
 		bytes.NewReader(payload),
 	)
 	if err != nil {
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"trade_bot/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// ===== WebSocket: private channels (orders, orders-algo, positions, account) =====
+
+const (
+	privatePing       = 20 * time.Second
+	privateMaxBackoff = 30 * time.Second
+)
+
+// StreamPrivate — приватный WS OKX с ключами юзера. Переподключается сам,
+// пока жив ctx; после каждого успешного login+subscribe шлёт PrivConnected —
+// за время разрыва могли пропустить события, получатель должен пересинхронизироваться.
+func (c *Client) StreamPrivate(ctx context.Context) <-chan models.PrivateEvent {
+	ch := make(chan models.PrivateEvent, 64)
+	go func() {
+		defer close(ch)
+
+		url := c.wsURL + "/ws/v5/private"
+		retry := 0
+
+		for {
+			err := c.privateSession(ctx, url, ch)
+			if ctx.Err() != nil {
+				return
+			}
+			retry++
+			backoff := min(time.Duration(retry)*time.Second, privateMaxBackoff)
+			log.Printf("[WS PRIVATE] %v; reconnect in %s", err, backoff)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+		}
+	}()
+	return ch
+}
+
+// privateSession — одно подключение: login, подписки, чтение до ошибки.
+func (c *Client) privateSession(ctx context.Context, url string, ch chan<- models.PrivateEvent) error {
+	if c.apiKey == "" || c.apiSecret == "" || c.passph == "" {
+		return errors.New("okx creds empty (ключ/секрет/пасфраза)")
+	}
+
+	conn, _, err := c.wsDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	defer conn.Close()
+
+	// закрываем сокет по ctx, чтобы ReadMessage не висел
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-stop:
+		}
+	}()
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	login := map[string]any{
+		"op": "login",
+		"args": []map[string]string{{
+			"apiKey":     c.apiKey,
+			"passphrase": c.passph,
+			"timestamp":  ts,
+			"sign":       c.sign(ts, "GET", "/users/self/verify", ""),
+		}},
+	}
+	if err := conn.WriteJSON(login); err != nil {
+		return fmt.Errorf("login write: %w", err)
+	}
+
+	// gorilla: одна горутина на запись — после login пишут только пинги
+	var subscribed bool
+	pingStop := make(chan struct{})
+	defer close(pingStop)
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * privatePing))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+		if string(msg) == "pong" {
+			continue
+		}
+
+		var frame struct {
+			Event string `json:"event"`
+			Code  string `json:"code"`
+			Msg   string `json:"msg"`
+			Arg   struct {
+				Channel string `json:"channel"`
+			} `json:"arg"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(msg, &frame); err != nil {
+			continue
+		}
+
+		switch frame.Event {
+		case "login":
+			if frame.Code != "0" {
+				return fmt.Errorf("login: code=%s msg=%s", frame.Code, frame.Msg)
+			}
+			sub := map[string]any{
+				"op": "subscribe",
+				"args": []map[string]string{
+					{"channel": "orders", "instType": "SWAP"},
+					{"channel": "orders-algo", "instType": "SWAP"},
+					{"channel": "positions", "instType": "SWAP"},
+					{"channel": "account", "ccy": "USDT"},
+				},
+			}
+			if err := conn.WriteJSON(sub); err != nil {
+				return fmt.Errorf("subscribe write: %w", err)
+			}
+			go func() {
+				t := time.NewTicker(privatePing)
+				defer t.Stop()
+				for {
+					select {
+					case <-pingStop:
+						return
+					case <-t.C:
+						_ = conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+					}
+				}
+			}()
+			continue
+		case "subscribe":
+			if !subscribed {
+				subscribed = true
+				sendPrivate(ctx, ch, models.PrivateEvent{Kind: models.PrivConnected})
+			}
+			continue
+		case "error":
+			return fmt.Errorf("ws error: code=%s msg=%s", frame.Code, frame.Msg)
+		case "":
+		default:
+			continue
+		}
+
+		ev, ok := parsePrivate(frame.Arg.Channel, frame.Data)
+		if ok {
+			sendPrivate(ctx, ch, ev)
+		}
+	}
+}
+
+func sendPrivate(ctx context.Context, ch chan<- models.PrivateEvent, ev models.PrivateEvent) {
+	select {
+	case ch <- ev:
+	case <-ctx.Done():
+	}
+}
+
+func parsePrivate(channel string, data json.RawMessage) (models.PrivateEvent, bool) {
+	switch channel {
+	case "positions":
+		var rows []PositionData
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return models.PrivateEvent{}, false
+		}
+		ev := models.PrivateEvent{Kind: models.PrivPositions}
+		for _, d := range rows {
+			ev.Positions = append(ev.Positions, d.toModel())
+		}
+		return ev, true
+
+	case "orders":
+		var rows []struct {
+			OrdID       string `json:"ordId"`
+			ClOrdID     string `json:"clOrdId"`
+			AlgoID      string `json:"algoId"`
+			AlgoClOrdID string `json:"algoClOrdId"`
+			InstID      string `json:"instId"`
+			Side        string `json:"side"`
+			PosSide     string `json:"posSide"`
+			State       string `json:"state"`
+			FillPx      string `json:"fillPx"`
+			FillSz      string `json:"fillSz"`
+			AccFillSz   string `json:"accFillSz"`
+			AvgPx       string `json:"avgPx"`
+			FillFee     string `json:"fillFee"`
+			FillPnl     string `json:"fillPnl"`
+			ReduceOnly  string `json:"reduceOnly"`
+			UTime       string `json:"uTime"`
+		}
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return models.PrivateEvent{}, false
+		}
+		ev := models.PrivateEvent{Kind: models.PrivOrders}
+		for _, d := range rows {
+			ev.Orders = append(ev.Orders, models.OrderUpdate{
+				OrdID:       d.OrdID,
+				ClOrdID:     d.ClOrdID,
+				AlgoID:      d.AlgoID,
+				AlgoClOrdID: d.AlgoClOrdID,
+				InstID:      d.InstID,
+				Side:        d.Side,
+				PosSide:     d.PosSide,
+				State:       d.State,
+				FillPx:      parseF(d.FillPx),
+				FillSz:      parseF(d.FillSz),
+				AccFillSz:   parseF(d.AccFillSz),
+				AvgPx:       parseF(d.AvgPx),
+				Fee:         parseF(d.FillFee),
+				Pnl:         parseF(d.FillPnl),
+				ReduceOnly:  d.ReduceOnly == "true",
+				UpdatedAt:   parseMs(d.UTime),
+			})
+		}
+		return ev, true
+
+	case "orders-algo":
+		var rows []struct {
+			AlgoID      string `json:"algoId"`
+			AlgoClOrdID string `json:"algoClOrdId"`
+			InstID      string `json:"instId"`
+			Side        string `json:"side"`
+			PosSide     string `json:"posSide"`
+			Sz          string `json:"sz"`
+			SlTriggerPx string `json:"slTriggerPx"`
+			TpTriggerPx string `json:"tpTriggerPx"`
+			State       string `json:"state"`
+			ActualPx    string `json:"actualPx"`
+			ActualSz    string `json:"actualSz"`
+			CTime       string `json:"cTime"`
+		}
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return models.PrivateEvent{}, false
+		}
+		ev := models.PrivateEvent{Kind: models.PrivAlgos}
+		for _, d := range rows {
+			ev.Algos = append(ev.Algos, models.AlgoUpdate{
+				AlgoOrder: models.AlgoOrder{
+					AlgoID:      d.AlgoID,
+					AlgoClOrdID: d.AlgoClOrdID,
+					InstID:      d.InstID,
+					PosSide:     d.PosSide,
+					Side:        d.Side,
+					Size:        parseF(d.Sz),
+					SLTriggerPx: parseF(d.SlTriggerPx),
+					TPTriggerPx: parseF(d.TpTriggerPx),
+					CreatedAt:   parseMs(d.CTime),
+				},
+				State:    d.State,
+				ActualPx: parseF(d.ActualPx),
+				ActualSz: parseF(d.ActualSz),
+			})
+		}
+		return ev, true
+
+	case "account":
+		var rows []struct {
+			TotalEq string `json:"totalEq"`
+			Details []struct {
+				Ccy string `json:"ccy"`
+				Eq  string `json:"eq"`
+			} `json:"details"`
+		}
+		if err := json.Unmarshal(data, &rows); err != nil || len(rows) == 0 {
+			return models.PrivateEvent{}, false
+		}
+		eq := parseF(rows[0].TotalEq)
+		for _, d := range rows[0].Details {
+			if d.Ccy == "USDT" {
+				eq = parseF(d.Eq)
+			}
+		}
+		return models.PrivateEvent{Kind: models.PrivAccount, Equity: eq}, true
+	}
+	return models.PrivateEvent{}, false
+}
+
+func parseF(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+func parseMs(s string) time.Time {
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
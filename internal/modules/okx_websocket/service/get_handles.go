@@ -25,8 +25,8 @@ func (c *Client) GetCandles(ctx context.Context, instID, bar string, limit int)
 		return nil, err
 	}
 
-	u := fmt.Sprintf("https://www.okx.com/api/v5/market/candles?instId=%s&bar=%s&limit=%d",
-		url.QueryEscape(instID), url.QueryEscape(bar), limit,
+	u := fmt.Sprintf("%s/api/v5/market/candles?instId=%s&bar=%s&limit=%d",
+		c.cfg.OKX.RestURL, url.QueryEscape(instID), url.QueryEscape(bar), limit,
 	)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
@@ -56,12 +56,17 @@ func (c *Client) GetCandles(ctx context.Context, instID, bar string, limit int)
 		return nil, fmt.Errorf("okx candles error: code=%s msg=%s", r.Code, r.Msg)
 	}
 
+	return parseCandleRows(instID, bar, r.Data), nil
+}
+
+// parseCandleRows — строки OKX (newest-first) в свечи по времени.
+func parseCandleRows(instID, bar string, rows [][]string) []models.CandleTick {
 	tfDur := timeframeToDuration(bar)
 
 	// OKX обычно отдаёт newest-first → разворачиваем, чтобы прогрев шёл по времени
-	out := make([]models.CandleTick, 0, len(r.Data))
-	for i := len(r.Data) - 1; i >= 0; i-- {
-		row := r.Data[i]
+	out := make([]models.CandleTick, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
 		if len(row) < 5 {
 			continue
 		}
@@ -104,5 +109,5 @@ func (c *Client) GetCandles(ctx context.Context, instID, bar string, limit int)
 		})
 	}
 
-	return out, nil
+	return out
 }
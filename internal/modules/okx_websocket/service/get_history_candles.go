@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// GetHistoryCandles — закрытые свечи [from, to) из /market/history-candles.
+// OKX отдаёт максимум 100 штук за запрос, поэтому листаем назад от to через after.
+func (c *Client) GetHistoryCandles(ctx context.Context, instID, bar string, from, to time.Time) ([]models.CandleTick, error) {
+	bar, err := okxBar(bar)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []models.CandleTick
+	after := to.UnixMilli()
+	for {
+		page, err := c.historyPage(ctx, instID, bar, after)
+		if err != nil {
+			return nil, fmt.Errorf("history %s %s: %w", instID, bar, err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		oldest := page[0].Start
+		for _, ct := range page {
+			if !ct.Start.Before(from) && ct.Start.Before(to) {
+				out = append(out, ct)
+			}
+		}
+		if !oldest.After(from) || oldest.UnixMilli() >= after {
+			break
+		}
+		after = oldest.UnixMilli()
+
+		// лимит OKX: 20 запросов / 2с
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(120 * time.Millisecond):
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, nil
+}
+
+func (c *Client) historyPage(ctx context.Context, instID, bar string, after int64) ([]models.CandleTick, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	u := fmt.Sprintf("%s/api/v5/market/history-candles?instId=%s&bar=%s&after=%s&limit=100",
+		c.cfg.OKX.RestURL, url.QueryEscape(instID), url.QueryEscape(bar), strconv.FormatInt(after, 10),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("http %d: %s", resp.StatusCode, string(b))
+	}
+
+	var r struct {
+		Code string     `json:"code"`
+		Msg  string     `json:"msg"`
+		Data [][]string `json:"data"`
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+	if r.Code != "0" {
+		return nil, fmt.Errorf("okx history candles error: code=%s msg=%s", r.Code, r.Msg)
+	}
+
+	return parseCandleRows(instID, bar, r.Data), nil
+}
@@ -12,7 +12,7 @@ import (
 
 // Проверка: доступны ли свечи для инструмента
 func (c *Client) HasCandles(instID, tf string) bool {
-	url := fmt.Sprintf("https://www.okx.com/api/v5/market/candles?instId=%s&bar=%s", instID, tf)
+	url := fmt.Sprintf("%s/api/v5/market/candles?instId=%s&bar=%s", c.cfg.OKX.RestURL, instID, tf)
 
 	req, _ := http.NewRequest("GET", url, nil)
 	resp, err := c.http.Do(req)
@@ -110,7 +110,7 @@ type okxTickerResp struct {
 }
 
 func (c *Client) fetchSwapTickers() ([]okxTicker, error) {
-	req, _ := http.NewRequest("GET", "https://www.okx.com/api/v5/market/tickers?instType=SWAP", nil)
+	req, _ := http.NewRequest("GET", c.cfg.OKX.RestURL+"/api/v5/market/tickers?instType=SWAP", nil)
 	resp, err := c.http.Do(req)
 	if err != nil {
 		return nil, err
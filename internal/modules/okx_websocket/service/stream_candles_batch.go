@@ -14,6 +14,8 @@ import (
 
 // StreamCandlesBatch — один WebSocket на таймфрейм с пачкой инструментов в args.
 // Возвращает поток CandleTick: instId + полная информация по закрытой свече.
+// Свечи по инструменту идут строго по времени и без пропусков: разрыв
+// (обрыв WS, потерянный кадр) докачивается через REST до выдачи новой свечи.
 func (c *Client) StreamCandlesBatch(ctx context.Context, instIDs []string, timeframe string) <-chan models.CandleTick {
 	out := make(chan models.CandleTick, 1024) // буфер помогает не стопорить WS
 	go func() {
@@ -23,8 +25,10 @@ func (c *Client) StreamCandlesBatch(ctx context.Context, instIDs []string, timef
 		}
 
 		channel := "candle" + timeframe
-		url := "wss://ws.okx.com:8443/ws/v5/business"
+		url := c.cfg.OKX.WSURL + "/ws/v5/business"
 		tfDur := timeframeToDuration(timeframe)
+		gaps := newCandleGaps(timeframe)
+		defer gaps.wait() // до close(out): докачки пишут в out
 
 		args := make([]map[string]string, 0, len(instIDs))
 		for _, id := range instIDs {
@@ -41,6 +45,11 @@ func (c *Client) StreamCandlesBatch(ctx context.Context, instIDs []string, timef
 			default:
 			}
 
+			// после обрыва: сначала то, что закрылось, пока WS лежал
+			if !c.catchUp(ctx, gaps, out) {
+				return
+			}
+
 			log.Printf("[WS] batch connect %s %d symbols", channel, len(instIDs))
 			conn, _, err := c.wsDialer.Dial(url, nil)
 			if err != nil {
@@ -167,9 +176,7 @@ func (c *Client) StreamCandlesBatch(ctx context.Context, instIDs []string, timef
 							TimeframeRaw: timeframe,
 						}
 
-						select {
-						case out <- tick:
-						case <-connCtx.Done():
+						if !c.release(ctx, gaps, tick, out) {
 							return nil
 						}
 					}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// okxCandlesMax — сколько последних свечей отдаёт /market/candles за раз;
+// разрыв длиннее докачиваем из history-candles.
+const okxCandlesMax = 300
+
+// candleGaps — Start последней принятой подтверждённой свечи по
+// инструменту. Живёт всё время стрима (через переподключения), чтобы
+// замечать пропуски и повторы.
+//
+// Разрыв докачивается в фоне, чтобы не стопорить чтение WS: пока по
+// инструменту идёт докачка, его новые свечи копятся в held и выходят
+// после докачанных. Остальные инструменты идут в out сразу.
+type candleGaps struct {
+	timeframe string
+	dur       time.Duration
+
+	mu   sync.Mutex
+	last map[string]time.Time
+	held map[string][]models.CandleTick // инструменты с докачкой в работе
+	wg   sync.WaitGroup
+}
+
+func newCandleGaps(timeframe string) *candleGaps {
+	return &candleGaps{
+		timeframe: timeframe,
+		dur:       timeframeToDuration(timeframe),
+		last:      make(map[string]time.Time),
+		held:      make(map[string][]models.CandleTick),
+	}
+}
+
+// wait — дождаться фоновых докачек (перед catchUp и закрытием out).
+func (g *candleGaps) wait() { g.wg.Wait() }
+
+// release отдаёт свечу в out по порядку: повтор/старая — отбрасывается,
+// после разрыва — уходит в фоновую докачку (fill). false — ctx отменён.
+func (c *Client) release(ctx context.Context, g *candleGaps, tick models.CandleTick, out chan<- models.CandleTick) bool {
+	g.mu.Lock()
+	last, seen := g.last[tick.InstID]
+	if seen && !tick.Start.After(last) {
+		g.mu.Unlock()
+		return true // повтор после переподключения или пришла не по порядку
+	}
+	g.last[tick.InstID] = tick.Start
+
+	if q, busy := g.held[tick.InstID]; busy {
+		g.held[tick.InstID] = append(q, tick)
+		g.mu.Unlock()
+		return true
+	}
+	if seen && g.dur > 0 && tick.Start.Sub(last) > g.dur {
+		g.held[tick.InstID] = []models.CandleTick{tick}
+		g.wg.Add(1)
+		g.mu.Unlock()
+		go c.fill(ctx, g, tick.InstID, last, out)
+		return true
+	}
+	g.mu.Unlock()
+
+	return send(ctx, out, tick)
+}
+
+// fill — фоновая докачка по инструменту: отдаёт held по порядку, перед
+// каждой свечой после разрыва — докачанные пропущенные. Пока докачиваем,
+// reader докладывает в held; выходим, когда очередь опустела.
+func (c *Client) fill(ctx context.Context, g *candleGaps, instID string, prev time.Time, out chan<- models.CandleTick) {
+	defer g.wg.Done()
+	for {
+		g.mu.Lock()
+		q := g.held[instID]
+		if len(q) == 0 {
+			delete(g.held, instID)
+			g.mu.Unlock()
+			return
+		}
+		g.held[instID] = q[:0:0]
+		g.mu.Unlock()
+
+		for _, tick := range q {
+			if tick.Start.Sub(prev) > g.dur {
+				missed := c.backfill(ctx, instID, g.timeframe, prev, tick.Start)
+				log.Printf("[WS] gap %s %s: %s..%s, backfilled %d/%d",
+					instID, g.timeframe, prev.Add(g.dur).Format(time.RFC3339), tick.Start.Format(time.RFC3339),
+					len(missed), int(tick.Start.Sub(prev)/g.dur)-1)
+				for _, m := range missed {
+					if !send(ctx, out, m) {
+						g.drop(instID)
+						return
+					}
+				}
+			}
+			if !send(ctx, out, tick) {
+				g.drop(instID)
+				return
+			}
+			prev = tick.Start
+		}
+	}
+}
+
+// drop — ctx отменён: очередь инструмента больше никому не нужна.
+func (g *candleGaps) drop(instID string) {
+	g.mu.Lock()
+	delete(g.held, instID)
+	g.mu.Unlock()
+}
+
+// catchUp — после обрыва, до переподключения: докачиваем по всем известным
+// инструментам свечи, закрывшиеся, пока WS лежал. Запросы параллельно
+// (как в прогреве), отдаём по инструментам, внутри — по времени.
+// Reader стоит, фоновые докачки дожидаемся — дальше last только наш.
+func (c *Client) catchUp(ctx context.Context, g *candleGaps, out chan<- models.CandleTick) bool {
+	g.wait()
+	if g.dur <= 0 || len(g.last) == 0 {
+		return true
+	}
+
+	now := time.Now()
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, 8)
+		missed = make(map[string][]models.CandleTick)
+	)
+	for inst, last := range g.last {
+		// следующая свеча ещё не закрылась — догонять нечего
+		if last.Add(2 * g.dur).After(now) {
+			continue
+		}
+		wg.Add(1)
+		go func(inst string, last time.Time) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			cs := c.backfill(ctx, inst, g.timeframe, last, now)
+			mu.Lock()
+			missed[inst] = cs
+			mu.Unlock()
+		}(inst, last)
+	}
+	wg.Wait()
+
+	insts := make([]string, 0, len(missed))
+	n := 0
+	for inst, cs := range missed {
+		insts = append(insts, inst)
+		n += len(cs)
+	}
+	sort.Strings(insts)
+	if n > 0 {
+		log.Printf("[WS] catch-up candle%s: %d candles for %d symbols", g.timeframe, n, len(insts))
+	}
+
+	for _, inst := range insts {
+		for _, ct := range missed[inst] {
+			if !send(ctx, out, ct) {
+				return false
+			}
+			g.last[inst] = ct.Start
+		}
+	}
+	return true
+}
+
+// backfill — закрытые свечи со Start в (after, before), по времени.
+// Ошибку REST только логируем: лучше отдать поток с дырой, чем встать.
+func (c *Client) backfill(ctx context.Context, instID, timeframe string, after, before time.Time) []models.CandleTick {
+	dur := timeframeToDuration(timeframe)
+	if dur <= 0 || !before.After(after.Add(dur)) {
+		return nil
+	}
+
+	var (
+		cs  []models.CandleTick
+		err error
+	)
+	// /market/candles отдаёт последние N от текущего момента, не от before
+	now := time.Now()
+	need := int(now.Sub(after)/dur) + 2
+	if need <= okxCandlesMax {
+		cs, err = c.GetCandles(ctx, instID, timeframe, need)
+	} else {
+		cs, err = c.GetHistoryCandles(ctx, instID, timeframe, after.Add(dur), before)
+	}
+	if err != nil {
+		log.Printf("[WS] backfill %s %s: %v", instID, timeframe, err)
+		return nil
+	}
+
+	out := cs[:0]
+	for _, ct := range cs {
+		// последняя строка /market/candles — ещё формирующаяся свеча
+		if !ct.Start.After(after) || !ct.Start.Before(before) || ct.End.After(now) {
+			continue
+		}
+		ct.TimeframeRaw = timeframe
+		out = append(out, ct)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+
+	// по Start без повторов
+	uniq := out[:0]
+	for _, ct := range out {
+		if n := len(uniq); n > 0 && ct.Start.Equal(uniq[n-1].Start) {
+			continue
+		}
+		uniq = append(uniq, ct)
+	}
+	return uniq
+}
+
+func send(ctx context.Context, out chan<- models.CandleTick, ct models.CandleTick) bool {
+	select {
+	case out <- ct:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
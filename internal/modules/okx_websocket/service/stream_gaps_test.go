@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+)
+
+// Докачка разрыва идёт в фоне: reader не ждёт REST, другие инструменты
+// проходят сразу, а свечи инструмента с разрывом выходят по порядку.
+func TestReleaseBackfillsGapWithoutBlocking(t *testing.T) {
+	t0 := time.Now().Truncate(time.Minute).Add(-10 * time.Minute)
+	at := func(i int) time.Time { return t0.Add(time.Duration(i) * time.Minute) }
+
+	gate := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-gate
+		var rows [][]string
+		for i := 8; i >= 0; i-- { // newest-first, как OKX
+			ts := strconv.FormatInt(at(i).UnixMilli(), 10)
+			rows = append(rows, []string{ts, "1", "2", "0.5", strconv.Itoa(i + 1), "10", "10", "10", "1"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"code": "0", "data": rows})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{}
+	cfg.OKX.RestURL = srv.URL
+	c := NewClient(cfg, nil)
+	g := newCandleGaps("1m")
+	out := make(chan models.CandleTick, 16)
+	ctx := context.Background()
+
+	tick := func(inst string, i int) models.CandleTick {
+		return models.CandleTick{InstID: inst, Close: float64(i + 1), Start: at(i), End: at(i + 1), TimeframeRaw: "1m"}
+	}
+	recv := func() models.CandleTick {
+		t.Helper()
+		select {
+		case ct := <-out:
+			return ct
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for candle")
+			return models.CandleTick{}
+		}
+	}
+
+	c.release(ctx, g, tick("A", 0), out)
+	c.release(ctx, g, tick("B", 0), out)
+	c.release(ctx, g, tick("A", 3), out) // разрыв: 1, 2 пропущены
+	c.release(ctx, g, tick("A", 4), out) // ждёт докачки
+	c.release(ctx, g, tick("B", 1), out) // не ждёт
+
+	for _, want := range []struct {
+		inst string
+		i    int
+	}{{"A", 0}, {"B", 0}, {"B", 1}} {
+		if ct := recv(); ct.InstID != want.inst || !ct.Start.Equal(at(want.i)) {
+			t.Fatalf("got %s %s, want %s %s", ct.InstID, ct.Start, want.inst, at(want.i))
+		}
+	}
+
+	close(gate)
+	for i := 1; i <= 4; i++ {
+		if ct := recv(); ct.InstID != "A" || !ct.Start.Equal(at(i)) {
+			t.Fatalf("got %s %s, want A %s", ct.InstID, ct.Start, at(i))
+		}
+	}
+	g.wait()
+	if len(g.held) != 0 {
+		t.Fatalf("held not drained: %v", g.held)
+	}
+}
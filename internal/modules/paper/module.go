@@ -0,0 +1,22 @@
+package paper
+
+import (
+	"trade_bot/internal/modules/paper/service"
+	"trade_bot/internal/modules/paper/service/pg"
+
+	"go.uber.org/fx"
+)
+
+// Module — paper trading: виртуальная биржа на живых ценах.
+// Свечи ей отдаёт runner (тот же 1m поток, что идёт на трейлинг).
+func Module() fx.Option {
+	return fx.Module("paper",
+		fx.Provide(
+			service.NewEngine,  // *service.Engine
+			pg.NewAccountStore, // *pg.AccountStore
+			func(s *pg.AccountStore) service.AccountStore {
+				return s
+			},
+		),
+	)
+}
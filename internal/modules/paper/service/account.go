@@ -0,0 +1,698 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// Account — paper-счёт одного юзера. Реализует sessions.Exchange.
+type Account struct {
+	e      *Engine
+	userID int64
+	store  AccountStore // nil — счёт не сохраняется
+	fresh  bool         // счёт не восстановлен из БД: открытых позиций на нём нет
+
+	saveMu    sync.Mutex // порядок записей снимков
+	mu        sync.Mutex
+	seq       int
+	balance   float64 // USDT: старт + реализованный PnL − комиссии
+	lever     map[string]int
+	positions map[string]*position // key = instId:posSide
+	algos     map[string]*algo
+	orders    map[string]*order // ордера на открытие, для GetOrder и лимитов
+	fills     []models.Fill     // последние maxFills исполнений
+}
+
+const (
+	maxFills = 1000
+	orderTTL = time.Hour // завершённые ордера храним для GetOrder не дольше
+)
+
+type position struct {
+	instID   string
+	posSide  string
+	size     float64 // контракты
+	avgPx    float64
+	ctVal    float64
+	lever    int
+	last     float64
+	realized float64
+	openedAt time.Time
+}
+
+type algo struct {
+	id        string
+	clOrdID   string
+	seq       int
+	instID    string
+	posSide   string
+	size      float64
+	triggerPx float64
+	isTP      bool
+}
+
+// order — ордер на открытие: market исполняется по следующей 1m свече,
+// limit ждёт свечу, задевшую px (оба — в onCandle).
+type order struct {
+	id        string
+	clOrdID   string
+	instID    string
+	posSide   string
+	ordType   string  // market / limit / post_only
+	px        float64 // цена лимита; для market — last на момент выставления (для маржи)
+	lever     int
+	sz        float64
+	avgPx     float64
+	filled    float64
+	ctVal     float64
+	state     models.OrderState
+	createdAt time.Time
+}
+
+func newAccount(e *Engine, userID int64, balance float64) *Account {
+	return &Account{
+		e:         e,
+		userID:    userID,
+		balance:   balance,
+		lever:     make(map[string]int),
+		positions: make(map[string]*position),
+		algos:     make(map[string]*algo),
+		orders:    make(map[string]*order),
+	}
+}
+
+func posKey(instID, posSide string) string { return instID + ":" + posSide }
+
+// PlaceMarket — открытие по рынку. side: 1 = long, 3 = short.
+// Ордер ждёт следующую закрытую 1m свечу и исполняется по её close
+// (см. onCandle); маржа под него резервируется сразу.
+func (a *Account) PlaceMarket(ctx context.Context, instID string, vol float64, side, leverage, openType int) (string, error) {
+	var posSide string
+	switch side {
+	case 1:
+		posSide = "long"
+	case 3:
+		posSide = "short"
+	default:
+		return "", fmt.Errorf("paper: unsupported side %d", side)
+	}
+
+	inst, err := a.e.instrument(ctx, instID)
+	if err != nil {
+		return "", fmt.Errorf("paper PlaceMarket meta: %w", err)
+	}
+	sz := roundLot(vol, inst.LotSz)
+	if sz < inst.MinSz || sz <= 0 {
+		return "", fmt.Errorf("paper PlaceMarket: size %.8f < minSz %.8f", vol, inst.MinSz)
+	}
+	ref := inst.LastPx
+	if ref <= 0 {
+		ref = a.e.lastPrice(instID)
+	}
+
+	defer a.persist()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if leverage > 0 {
+		a.lever[posKey(instID, posSide)] = leverage
+	}
+	lever := a.lever[posKey(instID, posSide)]
+	if err := a.checkMarginLocked(ref, sz, inst.CtVal, lever); err != nil {
+		return "", fmt.Errorf("paper PlaceMarket: %w", err)
+	}
+
+	o := a.newOrderLocked(instID, posSide, "market", ref, sz, inst.CtVal)
+	o.lever = lever
+	return o.id, nil
+}
+
+// PlaceLimit — лимит на открытие. Лимит, пересекающий last, исполняется сразу
+// как taker; post-only в этом случае отменяется, как на OKX.
+// Остальные ждут 1m свечу, задевшую px, и исполняются по px с maker-комиссией.
+func (a *Account) PlaceLimit(ctx context.Context, instID string, vol, px float64, side, leverage int, postOnly bool) (string, error) {
+	var posSide string
+	switch side {
+	case 1:
+		posSide = "long"
+	case 3:
+		posSide = "short"
+	default:
+		return "", fmt.Errorf("paper: unsupported side %d", side)
+	}
+	if px <= 0 {
+		return "", fmt.Errorf("paper PlaceLimit: px <= 0")
+	}
+
+	inst, err := a.e.instrument(ctx, instID)
+	if err != nil {
+		return "", fmt.Errorf("paper PlaceLimit meta: %w", err)
+	}
+	sz := roundLot(vol, inst.LotSz)
+	if sz < inst.MinSz || sz <= 0 {
+		return "", fmt.Errorf("paper PlaceLimit: size %.8f < minSz %.8f", vol, inst.MinSz)
+	}
+
+	defer a.persist()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if leverage > 0 {
+		a.lever[posKey(instID, posSide)] = leverage
+	}
+
+	lever := a.lever[posKey(instID, posSide)]
+	if err := a.checkMarginLocked(px, sz, inst.CtVal, lever); err != nil {
+		return "", fmt.Errorf("paper PlaceLimit: %w", err)
+	}
+
+	ordType := "limit"
+	if postOnly {
+		ordType = "post_only"
+	}
+	o := a.newOrderLocked(instID, posSide, ordType, px, sz, inst.CtVal)
+	o.lever = lever
+
+	long := posSide == "long"
+	crosses := inst.LastPx > 0 && ((long && px >= inst.LastPx) || (!long && px <= inst.LastPx))
+	switch {
+	case crosses && postOnly:
+		o.state = models.OrderCanceled
+	case crosses:
+		fill := a.e.fillPrice(inst.LastPx, long)
+		if long {
+			fill = math.Min(fill, px)
+		} else {
+			fill = math.Max(fill, px)
+		}
+		a.fillOrderLocked(o, fill, a.e.fee(fill*sz*inst.CtVal))
+	}
+	return o.id, nil
+}
+
+// GetOrder — ордер на открытие этого счёта (завершённые живут orderTTL).
+func (a *Account) GetOrder(ctx context.Context, instID, ordID string) (models.OrderInfo, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	o, ok := a.orders[ordID]
+	if !ok {
+		return models.OrderInfo{}, fmt.Errorf("paper GetOrder: order %s not found", ordID)
+	}
+	return models.OrderInfo{
+		OrdID:     o.id,
+		ClOrdID:   o.clOrdID,
+		InstID:    o.instID,
+		OrdType:   o.ordType,
+		State:     o.state,
+		Px:        o.px,
+		Sz:        o.sz,
+		AvgPx:     o.avgPx,
+		AccFillSz: o.filled,
+	}, nil
+}
+
+// CancelOrder — снять живой лимит; исполненный отменить нельзя.
+func (a *Account) CancelOrder(ctx context.Context, instID, ordID string) error {
+	defer a.persist()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	o, ok := a.orders[ordID]
+	if !ok {
+		return fmt.Errorf("paper CancelOrder: order %s not found", ordID)
+	}
+	if o.state != models.OrderLive && o.state != models.OrderPartiallyFilled {
+		return fmt.Errorf("paper CancelOrder: order %s already %s", ordID, o.state)
+	}
+	o.state = models.OrderCanceled
+	return nil
+}
+
+// CloseMarket — закрыть size контрактов позиции по свежей цене.
+func (a *Account) CloseMarket(ctx context.Context, instID, posSide string, size float64) (string, error) {
+	if size <= 0 {
+		return "", fmt.Errorf("paper CloseMarket: size <= 0")
+	}
+
+	inst, err := a.e.instrument(ctx, instID)
+	if err != nil {
+		return "", fmt.Errorf("paper CloseMarket meta: %w", err)
+	}
+	px := a.e.fillPrice(inst.LastPx, posSide == "short")
+
+	defer a.persist()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := a.nextIDLocked()
+	if !a.closeLocked(id, instID, posSide, size, px) {
+		return "", fmt.Errorf("paper CloseMarket: no %s position on %s", posSide, instID)
+	}
+	return id, nil
+}
+
+// PlaceSingleAlgo — условный reduce-ордер, срабатывает по high/low 1m свечи.
+func (a *Account) PlaceSingleAlgo(ctx context.Context, instID, posSide string, size, triggerPx float64, isTP bool) (string, error) {
+	posSide = strings.ToLower(posSide)
+	if posSide != "long" && posSide != "short" {
+		return "", fmt.Errorf("paper PlaceSingleAlgo: unsupported posSide=%q", posSide)
+	}
+	if size <= 0 {
+		return "", fmt.Errorf("paper PlaceSingleAlgo: size <= 0")
+	}
+	if triggerPx <= 0 {
+		return "", fmt.Errorf("paper PlaceSingleAlgo: triggerPx <= 0")
+	}
+
+	defer a.persist()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	kind := models.ClOrdSL
+	if isTP {
+		kind = models.ClOrdTP
+	}
+	id := a.nextIDLocked()
+	a.algos[id] = &algo{
+		id:        id,
+		clOrdID:   models.NewClOrdID(kind),
+		seq:       a.seq,
+		instID:    instID,
+		posSide:   posSide,
+		size:      size,
+		triggerPx: triggerPx,
+		isTP:      isTP,
+	}
+	return id, nil
+}
+
+func (a *Account) CancelAlgo(ctx context.Context, instID, algoID string) error {
+	defer a.persist()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.algos[algoID]; !ok {
+		return fmt.Errorf("paper CancelAlgo: algo %s not found", algoID)
+	}
+	delete(a.algos, algoID)
+	return nil
+}
+
+func (a *Account) OpenPositions(ctx context.Context) ([]models.OpenPosition, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	keys := make([]string, 0, len(a.positions))
+	for k, p := range a.positions {
+		if p.size > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	res := make([]models.OpenPosition, 0, len(keys))
+	for _, k := range keys {
+		p := a.positions[k]
+		upl := p.upl()
+
+		var uplPct float64
+		if margin := p.avgPx * p.size * p.ctVal / float64(max(p.lever, 1)); margin > 0 {
+			uplPct = upl / margin * 100
+		}
+
+		pt := 1
+		if p.posSide == "short" {
+			pt = 2
+		}
+		res = append(res, models.OpenPosition{
+			Symbol:           p.instID,
+			PositionType:     pt,
+			HoldVol:          p.size,
+			HoldAvgPrice:     p.avgPx,
+			Leverage:         p.lever,
+			Realised:         p.realized,
+			Size:             p.size,
+			EntryPrice:       p.avgPx,
+			LastPrice:        p.last,
+			UnrealizedPnl:    upl,
+			UnrealizedPnlPct: uplPct,
+			NotionalUSD:      p.last * p.size * p.ctVal,
+			Side:             p.posSide,
+			OpenedAt:         p.openedAt,
+		})
+	}
+	return res, nil
+}
+
+// PendingAlgos — живые SL/TP счёта, старые первыми.
+func (a *Account) PendingAlgos(ctx context.Context) ([]models.AlgoOrder, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	list := make([]*algo, 0, len(a.algos))
+	for _, al := range a.algos {
+		list = append(list, al)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].seq < list[j].seq })
+
+	res := make([]models.AlgoOrder, 0, len(list))
+	for _, al := range list {
+		o := models.AlgoOrder{
+			AlgoID:      al.id,
+			AlgoClOrdID: al.clOrdID,
+			InstID:      al.instID,
+			PosSide:     al.posSide,
+			Side:        "sell",
+			Size:        al.size,
+		}
+		if al.posSide == "short" {
+			o.Side = "buy"
+		}
+		if al.isTP {
+			o.TPTriggerPx = al.triggerPx
+		} else {
+			o.SLTriggerPx = al.triggerPx
+		}
+		res = append(res, o)
+	}
+	return res, nil
+}
+
+// Fills — исполнения с since, новые первыми (как fills-history).
+func (a *Account) Fills(ctx context.Context, since time.Time) ([]models.Fill, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	res := make([]models.Fill, 0)
+	for i := len(a.fills) - 1; i >= 0; i-- {
+		if a.fills[i].Ts.Before(since) {
+			break
+		}
+		res = append(res, a.fills[i])
+	}
+	return res, nil
+}
+
+// FundingBills — на paper-счёте funding не начисляется.
+func (a *Account) FundingBills(ctx context.Context, since time.Time) ([]models.Bill, error) {
+	return nil, nil
+}
+
+// USDTBalance — equity: баланс + нереализованный PnL открытых позиций.
+func (a *Account) USDTBalance(ctx context.Context) (float64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	eq := a.balance
+	for _, p := range a.positions {
+		eq += p.upl()
+	}
+	return eq, nil
+}
+
+// Equity — на paper-счёте свободные средства и equity не различаем.
+func (a *Account) Equity(ctx context.Context) (float64, error) {
+	return a.USDTBalance(ctx)
+}
+
+func (a *Account) GetInstrumentMeta(ctx context.Context, instID string) (models.Instrument, error) {
+	return a.e.instrument(ctx, instID)
+}
+
+func (a *Account) SettleCcyToUSDT(ctx context.Context, settleCcy string) (float64, error) {
+	return a.e.okx.SettleCcyToUSDT(ctx, settleCcy)
+}
+
+func (a *Account) SetLeverage(ctx context.Context, instID string, lever int, posSide string) error {
+	if lever <= 0 {
+		return fmt.Errorf("paper SetLeverage: lever <= 0")
+	}
+
+	defer a.persist()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, side := range []string{"long", "short"} {
+		if posSide != "" && posSide != side {
+			continue
+		}
+		a.lever[posKey(instID, side)] = lever
+		if p := a.positions[posKey(instID, side)]; p != nil {
+			p.lever = lever
+		}
+	}
+	return nil
+}
+
+// onCandle — mark-to-market и срабатывание SL/TP.
+// Если в одной свече задеты и SL, и TP — считаем, что первым был SL.
+func (a *Account) onCandle(ct models.CandleTick) {
+	dirty := false // сохраняем только исполнения, не каждый mark-to-market
+	defer func() {
+		if dirty {
+			a.persist()
+		}
+	}()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, p := range a.positions {
+		if p.instID == ct.InstID {
+			p.last = ct.Close
+		}
+	}
+
+	for _, o := range a.orders {
+		if o.instID != ct.InstID || o.state != models.OrderLive {
+			continue
+		}
+		if o.ordType == "market" {
+			// свеча должна закрыться после выставления — это и есть «следующая цена»
+			if !ct.End.After(o.createdAt) {
+				continue
+			}
+			px := a.e.fillPrice(ct.Close, o.posSide == "long")
+			a.fillOrderLocked(o, px, a.e.fee(px*o.sz*o.ctVal))
+			dirty = true
+			log.Printf("[PAPER] user=%d %s %s market filled @ %.6f", a.userID, o.instID, o.posSide, px)
+			continue
+		}
+		if (o.posSide == "long" && ct.Low <= o.px) || (o.posSide == "short" && ct.High >= o.px) {
+			a.fillOrderLocked(o, o.px, a.e.makerFee(o.px*o.sz*o.ctVal))
+			dirty = true
+			log.Printf("[PAPER] user=%d %s %s limit filled @ %.6f", a.userID, o.instID, o.posSide, o.px)
+		}
+	}
+
+	hit := make([]*algo, 0)
+	for _, al := range a.algos {
+		if al.instID == ct.InstID && al.triggered(ct) {
+			hit = append(hit, al)
+		}
+	}
+	sort.Slice(hit, func(i, j int) bool {
+		if hit[i].isTP != hit[j].isTP {
+			return !hit[i].isTP
+		}
+		return hit[i].seq < hit[j].seq
+	})
+
+	for _, al := range hit {
+		if _, alive := a.algos[al.id]; !alive {
+			continue // отменён при закрытии позиции предыдущим алго
+		}
+		delete(a.algos, al.id)
+		dirty = true
+
+		px := a.e.fillPrice(al.triggerPx, al.posSide == "short")
+		if a.closeLocked(al.id, al.instID, al.posSide, al.size, px) {
+			kind := "SL"
+			if al.isTP {
+				kind = "TP"
+			}
+			log.Printf("[PAPER] user=%d %s %s %s hit @ %.6f, balance=%.2f",
+				a.userID, al.instID, al.posSide, kind, px, a.balance)
+		}
+	}
+}
+
+func (al *algo) triggered(ct models.CandleTick) bool {
+	long := al.posSide == "long"
+	if al.isTP {
+		if long {
+			return ct.High >= al.triggerPx
+		}
+		return ct.Low <= al.triggerPx
+	}
+	if long {
+		return ct.Low <= al.triggerPx
+	}
+	return ct.High >= al.triggerPx
+}
+
+// newOrderLocked регистрирует ордер на открытие и чистит старые завершённые.
+func (a *Account) newOrderLocked(instID, posSide, ordType string, px, sz, ctVal float64) *order {
+	now := time.Now()
+	for id, o := range a.orders {
+		if o.state != models.OrderLive && now.Sub(o.createdAt) > orderTTL {
+			delete(a.orders, id)
+		}
+	}
+
+	o := &order{
+		id:        a.nextIDLocked(),
+		clOrdID:   models.NewClOrdID(models.ClOrdOpen),
+		instID:    instID,
+		posSide:   posSide,
+		ordType:   ordType,
+		px:        px,
+		sz:        sz,
+		ctVal:     ctVal,
+		state:     models.OrderLive,
+		createdAt: now,
+	}
+	a.orders[o.id] = o
+	return o
+}
+
+// fillOrderLocked исполняет ордер целиком по px: наращивает позицию и списывает комиссию.
+func (a *Account) fillOrderLocked(o *order, px, fee float64) {
+	k := posKey(o.instID, o.posSide)
+	p := a.positions[k]
+	if p == nil {
+		p = &position{instID: o.instID, posSide: o.posSide, ctVal: o.ctVal, openedAt: time.Now()}
+		a.positions[k] = p
+	}
+	p.avgPx = (p.avgPx*p.size + px*o.sz) / (p.size + o.sz)
+	p.size += o.sz
+	p.last = px
+	p.lever = a.lever[k]
+	a.balance -= fee
+
+	o.avgPx, o.filled, o.state = px, o.sz, models.OrderFilled
+
+	fillSide := "buy"
+	if o.posSide == "short" {
+		fillSide = "sell"
+	}
+	a.addFillLocked(o.id, o.instID, fillSide, o.posSide, px, o.sz, fee, 0)
+}
+
+// closeLocked уменьшает позицию, фиксирует PnL и комиссию.
+// Полностью закрытая позиция снимает свои алго, как на OKX.
+func (a *Account) closeLocked(ordID, instID, posSide string, size, px float64) bool {
+	p := a.positions[posKey(instID, posSide)]
+	if p == nil || p.size <= 0 {
+		return false
+	}
+	if size > p.size {
+		size = p.size
+	}
+
+	pnl := (px - p.avgPx) * size * p.ctVal
+	if posSide == "short" {
+		pnl = -pnl
+	}
+	fee := a.e.fee(px * size * p.ctVal)
+
+	p.size -= size
+	p.realized += pnl - fee
+	p.last = px
+	a.balance += pnl - fee
+
+	side := "sell"
+	if posSide == "short" {
+		side = "buy"
+	}
+	a.addFillLocked(ordID, instID, side, posSide, px, size, fee, pnl)
+
+	if p.size <= 1e-12 {
+		delete(a.positions, posKey(instID, posSide))
+		for id, al := range a.algos {
+			if al.instID == instID && al.posSide == posSide {
+				delete(a.algos, id)
+			}
+		}
+	}
+	return true
+}
+
+// checkMarginLocked — хватит ли свободной маржи на ордер px*sz с плечом lever
+// (плюс taker-комиссия на вход).
+func (a *Account) checkMarginLocked(px, sz, ctVal float64, lever int) error {
+	if px <= 0 {
+		return fmt.Errorf("no price for margin check")
+	}
+	notional := px * sz * ctVal
+	need := notional/float64(max(lever, 1)) + a.e.fee(notional)
+	if free := a.freeMarginLocked(); need > free {
+		return fmt.Errorf("insufficient margin: need %.2f USDT, free %.2f", need, free)
+	}
+	return nil
+}
+
+// freeMarginLocked — equity минус маржа открытых позиций и живых ордеров на открытие.
+func (a *Account) freeMarginLocked() float64 {
+	free := a.balance
+	for _, p := range a.positions {
+		free += p.upl() - p.avgPx*p.size*p.ctVal/float64(max(p.lever, 1))
+	}
+	for _, o := range a.orders {
+		if o.state == models.OrderLive {
+			free -= o.px * o.sz * o.ctVal / float64(max(o.lever, 1))
+		}
+	}
+	return free
+}
+
+// addFillLocked пишет исполнение в формате OKX: комиссия со знаком минус.
+func (a *Account) addFillLocked(ordID, instID, side, posSide string, px, sz, fee, pnl float64) {
+	a.fills = append(a.fills, models.Fill{
+		BillID:  ordID,
+		TradeID: ordID,
+		OrdID:   ordID,
+		InstID:  instID,
+		Side:    side,
+		PosSide: posSide,
+		Px:      px,
+		Sz:      sz,
+		Fee:     -fee,
+		Pnl:     pnl,
+		Ts:      time.Now(),
+	})
+	if n := len(a.fills); n > maxFills {
+		a.fills = append(a.fills[:0], a.fills[n-maxFills:]...)
+	}
+}
+
+func (p *position) upl() float64 {
+	if p.size <= 0 || p.last <= 0 {
+		return 0
+	}
+	upl := (p.last - p.avgPx) * p.size * p.ctVal
+	if p.posSide == "short" {
+		return -upl
+	}
+	return upl
+}
+
+func (a *Account) nextIDLocked() string {
+	a.seq++
+	return "paper-" + strconv.FormatInt(time.Now().UnixMilli(), 10) + "-" + strconv.Itoa(a.seq)
+}
+
+func roundLot(v, lot float64) float64 {
+	if lot <= 0 {
+		return v
+	}
+	return math.Floor(v/lot+1e-9) * lot
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	okx_client "trade_bot/internal/modules/okx_client/service"
+)
+
+// Engine — виртуальная биржа на живых ценах OKX.
+// Цены берёт из того же потока свечей, что и стратегия (1m),
+// спецификации инструментов и свежий тикер — из публичного REST OKX.
+// Счета сохраняются в store после каждого изменения и поднимаются из него
+// при первом обращении после рестарта.
+type Engine struct {
+	cfg   config.PaperConfig
+	okx   *okx_client.Client // только публичные эндпоинты, ключи не нужны
+	store AccountStore
+
+	mu       sync.Mutex
+	last     map[string]float64 // instId -> close последней 1m свечи
+	meta     map[string]models.Instrument
+	accounts map[int64]*Account
+}
+
+func NewEngine(cfg *config.Config, store AccountStore) *Engine {
+	return &Engine{
+		cfg:      cfg.Paper,
+		okx:      okx_client.NewClient(cfg, &models.UserSettings{}),
+		store:    store,
+		last:     make(map[string]float64),
+		meta:     make(map[string]models.Instrument),
+		accounts: make(map[int64]*Account),
+	}
+}
+
+// Account — paper-счёт юзера. При первом обращении поднимается из store,
+// если его там нет — создаётся со стартовым балансом.
+func (e *Engine) Account(userID int64) *Account {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	acc, ok := e.accounts[userID]
+	if !ok {
+		acc = e.loadAccount(userID)
+		e.accounts[userID] = acc
+	}
+	return acc
+}
+
+// loadAccount — счёт из store. Если снимок не прочитался, счёт работает
+// только в памяти: пустым счётом сохранённый не затираем.
+func (e *Engine) loadAccount(userID int64) *Account {
+	acc := newAccount(e, userID, e.cfg.StartBalance)
+	acc.fresh = true
+	if e.store == nil {
+		return acc
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	snap, err := e.store.LoadAccount(ctx, userID)
+	if err != nil {
+		log.Printf("[PAPER] user=%d load: %v, account is in-memory until restart", userID, err)
+		return acc
+	}
+	acc.store = e.store
+	if snap != nil {
+		acc.restore(snap)
+		acc.fresh = false
+	}
+	return acc
+}
+
+// OnCandle — закрытая 1m свеча из WS: обновляем цену и проверяем лимиты и SL/TP всех счетов.
+func (e *Engine) OnCandle(ct models.CandleTick) {
+	if helper.NormTF(ct.TimeframeRaw) != "1m" || ct.Close <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	e.last[ct.InstID] = ct.Close
+	accs := make([]*Account, 0, len(e.accounts))
+	for _, a := range e.accounts {
+		accs = append(accs, a)
+	}
+	e.mu.Unlock()
+
+	for _, a := range accs {
+		a.onCandle(ct)
+	}
+}
+
+func (e *Engine) lastPrice(instID string) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.last[instID]
+}
+
+// instrument — спецификация + свежий last. При ошибке REST отдаём кеш
+// с ценой последней свечи, чтобы ордер всё равно исполнился.
+func (e *Engine) instrument(ctx context.Context, instID string) (models.Instrument, error) {
+	inst, err := e.okx.GetInstrumentMeta(ctx, instID)
+	if err == nil {
+		e.mu.Lock()
+		e.meta[instID] = inst
+		e.mu.Unlock()
+		return inst, nil
+	}
+
+	e.mu.Lock()
+	cached, ok := e.meta[instID]
+	last := e.last[instID]
+	e.mu.Unlock()
+	if !ok || last <= 0 {
+		return models.Instrument{}, err
+	}
+
+	log.Printf("[PAPER] %s meta: %v, fallback to last candle %.6f", instID, err, last)
+	cached.LastPx = last
+	return cached, nil
+}
+
+// fillPrice — цена исполнения с проскальзыванием против нас.
+func (e *Engine) fillPrice(px float64, buy bool) float64 {
+	slip := e.cfg.SlippagePct / 100
+	if buy {
+		return px * (1 + slip)
+	}
+	return px * (1 - slip)
+}
+
+func (e *Engine) fee(notional float64) float64 {
+	return notional * e.cfg.TakerFeePct / 100
+}
+
+// makerFee — комиссия исполнения лимитного ордера из книги.
+func (e *Engine) makerFee(notional float64) float64 {
+	return notional * e.cfg.MakerFeePct / 100
+}
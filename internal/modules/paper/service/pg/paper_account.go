@@ -0,0 +1,60 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/paper/service/pg/paper_account"
+	"trade_bot/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// AccountStore — снимки paper-счетов, реализует service.AccountStore.
+type AccountStore struct {
+	db      *db.PgTxManager
+	account *paper_account.PaperAccount
+}
+
+// NewAccountStore instance
+func NewAccountStore(db *db.PgTxManager) *AccountStore {
+	return &AccountStore{
+		db:      db,
+		account: paper_account.New(),
+	}
+}
+
+// SaveAccount upsert по юзеру
+func (s *AccountStore) SaveAccount(
+	ctx context.Context,
+	userID int64,
+	acc *models.PaperAccount,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.SaveAccount: %w", err)
+		}
+	}()
+	return s.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return s.account.Upsert(ctx, tx, userID, acc)
+		})
+}
+
+// LoadAccount — сохранённый счёт юзера, nil если его нет
+func (s *AccountStore) LoadAccount(
+	ctx context.Context,
+	userID int64,
+) (acc *models.PaperAccount, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.LoadAccount: %w", err)
+		}
+	}()
+	err = s.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			acc, err = s.account.Get(ctx, tx, userID)
+			return err
+		})
+	return acc, err
+}
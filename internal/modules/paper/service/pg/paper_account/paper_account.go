@@ -0,0 +1,64 @@
+package paper_account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/paper/service/pg/paper_account/sql"
+
+	"github.com/bytedance/sonic"
+	"github.com/jackc/pgx/v5"
+)
+
+// PaperAccount implement db store
+type PaperAccount struct {
+	sql *sql.Queries
+}
+
+// New instance
+func New() *PaperAccount {
+	return &PaperAccount{
+		sql: sql.New(),
+	}
+}
+
+func (p *PaperAccount) Upsert(ctx context.Context, tx pgx.Tx, userID int64, acc *models.PaperAccount) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("PaperAccount.Upsert: %w", err)
+		}
+	}()
+
+	var data []byte
+	data, err = sonic.Marshal(acc)
+	if err != nil {
+		return err
+	}
+	return p.sql.Upsert(ctx, tx, &sql.UpsertParams{
+		Chatid: userID,
+		State:  data,
+	})
+}
+
+// Get — nil без ошибки, если счёта ещё нет
+func (p *PaperAccount) Get(ctx context.Context, tx pgx.Tx, userID int64) (acc *models.PaperAccount, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("PaperAccount.Get: %w", err)
+		}
+	}()
+	data, err := p.sql.GetByChat(ctx, tx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	acc = &models.PaperAccount{}
+	if err = sonic.Unmarshal(data, acc); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
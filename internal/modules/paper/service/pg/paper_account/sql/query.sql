@@ -0,0 +1,12 @@
+-- name: Upsert :exec
+INSERT INTO paper_accounts (
+    chatid, state, updated_at
+) VALUES (
+             @chatid, @state, now()
+         )
+ON CONFLICT (chatid)
+DO UPDATE SET state = EXCLUDED.state, updated_at = now();
+
+
+-- name: GetByChat :one
+SELECT state FROM paper_accounts WHERE chatid = @chatid;
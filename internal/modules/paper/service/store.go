@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// AccountStore — постоянное хранилище paper-счетов (Postgres).
+// LoadAccount: nil без ошибки — счёта ещё не было.
+type AccountStore interface {
+	SaveAccount(ctx context.Context, userID int64, acc *models.PaperAccount) error
+	LoadAccount(ctx context.Context, userID int64) (*models.PaperAccount, error)
+}
+
+const storeTimeout = 5 * time.Second
+
+// persist пишет снимок счёта после изменения. Снимок берётся под saveMu,
+// поэтому более поздняя запись всегда не старее предыдущей.
+// Ошибку только логируем: торговлю из-за БД не останавливаем.
+func (a *Account) persist() {
+	if a.store == nil {
+		return
+	}
+	a.saveMu.Lock()
+	defer a.saveMu.Unlock()
+
+	a.mu.Lock()
+	snap := a.snapshotLocked()
+	a.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := a.store.SaveAccount(ctx, a.userID, snap); err != nil {
+		log.Printf("[PAPER] user=%d save: %v", a.userID, err)
+	}
+}
+
+func (a *Account) snapshotLocked() *models.PaperAccount {
+	snap := &models.PaperAccount{
+		Seq:     a.seq,
+		Balance: a.balance,
+		Lever:   make(map[string]int, len(a.lever)),
+		Fills:   append([]models.Fill(nil), a.fills...),
+	}
+	for k, v := range a.lever {
+		snap.Lever[k] = v
+	}
+	for _, p := range a.positions {
+		snap.Positions = append(snap.Positions, models.PaperPosition{
+			InstID: p.instID, PosSide: p.posSide, Size: p.size, AvgPx: p.avgPx, CtVal: p.ctVal,
+			Lever: p.lever, Last: p.last, Realized: p.realized, OpenedAt: p.openedAt,
+		})
+	}
+	for _, al := range a.algos {
+		snap.Algos = append(snap.Algos, models.PaperAlgo{
+			ID: al.id, ClOrdID: al.clOrdID, Seq: al.seq, InstID: al.instID, PosSide: al.posSide,
+			Size: al.size, TriggerPx: al.triggerPx, IsTP: al.isTP,
+		})
+	}
+	for _, o := range a.orders {
+		if o.state != models.OrderLive {
+			continue // завершённые нужны только GetOrder сразу после входа
+		}
+		snap.Orders = append(snap.Orders, models.PaperOrder{
+			ID: o.id, ClOrdID: o.clOrdID, InstID: o.instID, PosSide: o.posSide, OrdType: o.ordType,
+			Px: o.px, Lever: o.lever, Sz: o.sz, CtVal: o.ctVal, CreatedAt: o.createdAt,
+		})
+	}
+	return snap
+}
+
+// restore — счёт из снимка (до первого обращения, без локов).
+func (a *Account) restore(snap *models.PaperAccount) {
+	a.seq = snap.Seq
+	a.balance = snap.Balance
+	for k, v := range snap.Lever {
+		a.lever[k] = v
+	}
+	for _, p := range snap.Positions {
+		a.positions[posKey(p.InstID, p.PosSide)] = &position{
+			instID: p.InstID, posSide: p.PosSide, size: p.Size, avgPx: p.AvgPx, ctVal: p.CtVal,
+			lever: p.Lever, last: p.Last, realized: p.Realized, openedAt: p.OpenedAt,
+		}
+	}
+	for _, al := range snap.Algos {
+		a.algos[al.ID] = &algo{
+			id: al.ID, clOrdID: al.ClOrdID, seq: al.Seq, instID: al.InstID, posSide: al.PosSide,
+			size: al.Size, triggerPx: al.TriggerPx, isTP: al.IsTP,
+		}
+	}
+	for _, o := range snap.Orders {
+		a.orders[o.ID] = &order{
+			id: o.ID, clOrdID: o.ClOrdID, instID: o.InstID, posSide: o.PosSide, ordType: o.OrdType,
+			px: o.Px, lever: o.Lever, sz: o.Sz, ctVal: o.CtVal, state: models.OrderLive, createdAt: o.CreatedAt,
+		}
+	}
+	a.fills = append(a.fills[:0], snap.Fills...)
+}
+
+// Fresh — счёт начат с нуля (в БД его не было или он не прочитался):
+// позиций, которые помнит трейлинг, на нём нет.
+func (a *Account) Fresh() bool { return a.fresh }
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+)
+
+type memStore struct {
+	saved   map[int64]*models.PaperAccount
+	loadErr error
+}
+
+func (m *memStore) SaveAccount(_ context.Context, userID int64, acc *models.PaperAccount) error {
+	m.saved[userID] = acc
+	return nil
+}
+
+func (m *memStore) LoadAccount(_ context.Context, userID int64) (*models.PaperAccount, error) {
+	return m.saved[userID], m.loadErr
+}
+
+func newTestEngine(store AccountStore) *Engine {
+	return &Engine{
+		cfg:      config.PaperConfig{StartBalance: 1000},
+		store:    store,
+		last:     make(map[string]float64),
+		meta:     make(map[string]models.Instrument),
+		accounts: make(map[int64]*Account),
+	}
+}
+
+func TestAccountRestoreAfterRestart(t *testing.T) {
+	store := &memStore{saved: make(map[int64]*models.PaperAccount)}
+	opened := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+
+	acc := newTestEngine(store).Account(1)
+	if !acc.Fresh() {
+		t.Fatal("new account must be fresh")
+	}
+	acc.mu.Lock()
+	acc.seq = 7
+	acc.balance = 1234.5
+	acc.lever["BTC-USDT-SWAP:long"] = 5
+	acc.positions["BTC-USDT-SWAP:long"] = &position{
+		instID: "BTC-USDT-SWAP", posSide: "long", size: 3, avgPx: 100, ctVal: 0.01, lever: 5, last: 101, openedAt: opened,
+	}
+	acc.algos["p3"] = &algo{id: "p3", seq: 3, instID: "BTC-USDT-SWAP", posSide: "long", size: 3, triggerPx: 95}
+	acc.orders["p5"] = &order{id: "p5", instID: "BTC-USDT-SWAP", posSide: "long", ordType: "limit",
+		px: 99, lever: 5, sz: 1, ctVal: 0.01, state: models.OrderLive, createdAt: opened}
+	acc.orders["p6"] = &order{id: "p6", state: models.OrderFilled, createdAt: opened}
+	acc.mu.Unlock()
+	acc.persist()
+
+	// рестарт: новый движок, тот же store
+	got := newTestEngine(store).Account(1)
+	if got.Fresh() {
+		t.Fatal("restored account must not be fresh")
+	}
+	if got.seq != 7 || got.balance != 1234.5 || got.lever["BTC-USDT-SWAP:long"] != 5 {
+		t.Fatalf("seq/balance/lever = %d/%v/%v", got.seq, got.balance, got.lever)
+	}
+	if !reflect.DeepEqual(got.positions, acc.positions) {
+		t.Fatalf("positions = %+v", got.positions["BTC-USDT-SWAP:long"])
+	}
+	if !reflect.DeepEqual(got.algos, acc.algos) {
+		t.Fatalf("algos = %+v", got.algos)
+	}
+	if len(got.orders) != 1 || !reflect.DeepEqual(got.orders["p5"], acc.orders["p5"]) {
+		t.Fatalf("orders = %+v", got.orders)
+	}
+}
+
+func TestAccountLoadErrorKeepsSaved(t *testing.T) {
+	saved := &models.PaperAccount{Balance: 500}
+	store := &memStore{
+		saved:   map[int64]*models.PaperAccount{1: saved},
+		loadErr: errors.New("db down"),
+	}
+
+	acc := newTestEngine(store).Account(1)
+	if !acc.Fresh() || acc.balance != 1000 {
+		t.Fatalf("fresh=%v balance=%v, want fresh start balance", acc.Fresh(), acc.balance)
+	}
+	acc.persist()
+	if store.saved[1] != saved {
+		t.Fatal("fresh account overwrote the saved one")
+	}
+}
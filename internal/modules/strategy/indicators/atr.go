@@ -0,0 +1,85 @@
+package indicators
+
+import "math"
+
+// trueRange — TR бара; prevClose <= 0 — первый бар, TR = high - low.
+func trueRange(b Bar, prevClose float64) float64 {
+	tr := b.High - b.Low
+	if prevClose > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(b.High-prevClose), math.Abs(b.Low-prevClose)))
+	}
+	return tr
+}
+
+// ATR — средний истинный диапазон по Уайлдеру.
+type ATR struct {
+	rma       *RMA
+	prevClose float64
+}
+
+func NewATR(period int) *ATR { return &ATR{rma: NewRMA(period)} }
+
+func (a *ATR) UpdateBar(b Bar) {
+	a.rma.Update(trueRange(b, a.prevClose))
+	a.prevClose = b.Close
+}
+
+func (a *ATR) Ready() bool    { return a.rma.Ready() }
+func (a *ATR) Value() float64 { return a.rma.Value() }
+
+// ADX — сила тренда (0..100) по Уайлдеру; +DI/-DI — направление.
+// Ready, когда сглажен и сам DX: около 2*period баров.
+type ADX struct {
+	tr, plus, minus *RMA
+	adx             *RMA
+	prev            Bar
+	n               int
+}
+
+func NewADX(period int) *ADX {
+	return &ADX{tr: NewRMA(period), plus: NewRMA(period), minus: NewRMA(period), adx: NewRMA(period)}
+}
+
+func (a *ADX) UpdateBar(b Bar) {
+	a.n++
+	if a.n == 1 {
+		a.prev = b
+		return
+	}
+	up := b.High - a.prev.High
+	dn := a.prev.Low - b.Low
+	pdm, mdm := 0.0, 0.0
+	if up > dn && up > 0 {
+		pdm = up
+	}
+	if dn > up && dn > 0 {
+		mdm = dn
+	}
+	a.tr.Update(trueRange(b, a.prev.Close))
+	a.plus.Update(pdm)
+	a.minus.Update(mdm)
+	a.prev = b
+
+	if !a.tr.Ready() {
+		return
+	}
+	p, m := a.PlusDI(), a.MinusDI()
+	dx := 0.0
+	if p+m > 0 {
+		dx = 100 * math.Abs(p-m) / (p + m)
+	}
+	a.adx.Update(dx)
+}
+
+func (a *ADX) PlusDI() float64  { return di(a.plus, a.tr) }
+func (a *ADX) MinusDI() float64 { return di(a.minus, a.tr) }
+
+func di(dm, tr *RMA) float64 {
+	if tr.Value() == 0 {
+		return 0
+	}
+	return 100 * dm.Value() / tr.Value()
+}
+
+func (a *ADX) Ready() bool    { return a.adx.Ready() }
+func (a *ADX) Value() float64 { return a.adx.Value() }
@@ -0,0 +1,85 @@
+package indicators
+
+import "math"
+
+// Bollinger — SMA(period) ± k стандартных отклонений (по генеральной
+// совокупности, как в TradingView). Value — средняя линия.
+type Bollinger struct {
+	k          float64
+	w          ring
+	sum, sumSq float64
+}
+
+func NewBollinger(period int, k float64) *Bollinger {
+	return &Bollinger{k: k, w: newRing(period)}
+}
+
+func (b *Bollinger) Update(x float64) {
+	if old, ok := b.w.Push(x); ok {
+		b.sum -= old
+		b.sumSq -= old * old
+	}
+	b.sum += x
+	b.sumSq += x * x
+}
+
+func (b *Bollinger) Ready() bool { return b.w.Full() }
+
+func (b *Bollinger) Value() float64 {
+	if b.w.Len() == 0 {
+		return 0
+	}
+	return b.sum / float64(b.w.Len())
+}
+
+// StdDev — отклонение в окне (сумма квадратов может слегка уйти в минус от округления).
+func (b *Bollinger) StdDev() float64 {
+	n := float64(b.w.Len())
+	if n == 0 {
+		return 0
+	}
+	m := b.sum / n
+	return math.Sqrt(math.Max(b.sumSq/n-m*m, 0))
+}
+
+func (b *Bollinger) Upper() float64 { return b.Value() + b.k*b.StdDev() }
+func (b *Bollinger) Lower() float64 { return b.Value() - b.k*b.StdDev() }
+
+// Width — (upper-lower)/mid; 0, если средней ещё нет.
+func (b *Bollinger) Width() float64 {
+	m := b.Value()
+	if m == 0 {
+		return 0
+	}
+	return (b.Upper() - b.Lower()) / m
+}
+
+// Donchian — max high / min low за последние period баров
+// (монотонные очереди: O(1) амортизированно вместо прохода по окну).
+type Donchian struct {
+	period int
+	n      int
+	hi, lo monoDeque
+}
+
+func NewDonchian(period int) *Donchian {
+	period = normPeriod(period)
+	return &Donchian{
+		period: period,
+		hi:     newMonoDeque(period, true),
+		lo:     newMonoDeque(period, false),
+	}
+}
+
+func (d *Donchian) UpdateBar(b Bar) {
+	d.hi.Push(d.n, b.High, d.period)
+	d.lo.Push(d.n, b.Low, d.period)
+	d.n++
+}
+
+func (d *Donchian) Ready() bool    { return d.n >= d.period }
+func (d *Donchian) Upper() float64 { return d.hi.Front() }
+func (d *Donchian) Lower() float64 { return d.lo.Front() }
+func (d *Donchian) Value() float64 { return (d.Upper() + d.Lower()) / 2 }
+func (d *Donchian) Count() int     { return min(d.n, d.period) }
+func (d *Donchian) Period() int    { return d.period }
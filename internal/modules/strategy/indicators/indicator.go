@@ -0,0 +1,58 @@
+// Package indicators — потоковые индикаторы для стратегий и фильтров.
+//
+// Контракт у всех один: Update/UpdateBar на каждой закрытой свече (O(1),
+// без пересчёта окна), Ready() — хватает ли истории, Value() — текущее
+// значение (до прогрева — промежуточное, полагаться на него нельзя).
+// Индикаторы не потокобезопасны: синхронизация — на стороне движка.
+package indicators
+
+import "trade_bot/internal/models"
+
+// Indicator — общая часть контракта.
+type Indicator interface {
+	Ready() bool
+	Value() float64
+}
+
+// Series — индикатор по одному ряду (обычно close).
+type Series interface {
+	Indicator
+	Update(x float64)
+}
+
+// BarSeries — индикатор по бару целиком (high/low/close/volume).
+type BarSeries interface {
+	Indicator
+	UpdateBar(b Bar)
+}
+
+// Bar — закрытая свеча без времени и инструмента.
+type Bar struct {
+	Open, High, Low, Close, Volume float64
+}
+
+// BarOf — Bar из свечи стрима.
+func BarOf(ct models.CandleTick) Bar {
+	return Bar{Open: ct.Open, High: ct.High, Low: ct.Low, Close: ct.Close, Volume: ct.Volume}
+}
+
+var (
+	_ Series    = (*SMA)(nil)
+	_ Series    = (*EMA)(nil)
+	_ Series    = (*RMA)(nil)
+	_ Series    = (*RSI)(nil)
+	_ Series    = (*Bollinger)(nil)
+	_ BarSeries = (*ATR)(nil)
+	_ BarSeries = (*ADX)(nil)
+	_ BarSeries = (*Donchian)(nil)
+	_ BarSeries = (*VWAP)(nil)
+	_ BarSeries = (*OBV)(nil)
+	_ BarSeries = (*Supertrend)(nil)
+)
+
+func normPeriod(period int) int {
+	if period <= 1 {
+		return 1
+	}
+	return period
+}
@@ -0,0 +1,170 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+const eps = 1e-6
+
+// bars — общий ряд для индикаторов по бару; эталоны ниже посчитаны вручную
+// по формулам Уайлдера / TradingView.
+var bars = []Bar{
+	{High: 10, Low: 8, Close: 9},
+	{High: 11, Low: 9, Close: 10},
+	{High: 12, Low: 9, Close: 11},
+	{High: 11, Low: 10, Close: 10.5},
+	{High: 10.5, Low: 8, Close: 8.5},
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < eps }
+
+func TestSeries(t *testing.T) {
+	tests := []struct {
+		name  string
+		ind   Series
+		in    []float64
+		ready bool
+		want  float64
+	}{
+		{"SMA warmup", NewSMA(3), []float64{1, 2}, false, 1.5},
+		{"SMA ready", NewSMA(3), []float64{1, 2, 3}, true, 2},
+		{"SMA window", NewSMA(3), []float64{1, 2, 3, 4, 5}, true, 4},
+
+		// alpha = 2/(3+1) = 0.5: 1 -> 1.5 -> 2.25 -> 3.125
+		{"EMA warmup", NewEMA(3), []float64{1, 2}, false, 1.5},
+		{"EMA ready", NewEMA(3), []float64{1, 2, 3}, true, 2.25},
+		{"EMA", NewEMA(3), []float64{1, 2, 3, 4}, true, 3.125},
+
+		// изменения +1 +1: потерь нет -> 100; затем -1: gain 0.5, loss 0.5 -> 50
+		{"RSI warmup", NewRSI(2), []float64{1, 2}, false, 100},
+		{"RSI only gains", NewRSI(2), []float64{1, 2, 3}, true, 100},
+		{"RSI", NewRSI(2), []float64{1, 2, 3, 2}, true, 50},
+		// затем +2: gain 1.25, loss 0.25 -> 100 - 100/6
+		{"RSI smoothed", NewRSI(2), []float64{1, 2, 3, 2, 4}, true, 100 - 100.0/6},
+		{"RSI flat", NewRSI(2), []float64{5, 5, 5}, true, 50},
+
+		// окно 1,2,3: mean 2; окно 2,3,6: mean 11/3
+		{"Bollinger mid", NewBollinger(3, 2), []float64{1, 2, 3}, true, 2},
+		{"Bollinger window", NewBollinger(3, 2), []float64{1, 2, 3, 6}, true, 11.0 / 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, x := range tt.in {
+				tt.ind.Update(x)
+			}
+			if tt.ind.Ready() != tt.ready {
+				t.Fatalf("Ready = %t, want %t", tt.ind.Ready(), tt.ready)
+			}
+			if !near(tt.ind.Value(), tt.want) {
+				t.Fatalf("Value = %.9f, want %.9f", tt.ind.Value(), tt.want)
+			}
+		})
+	}
+}
+
+func TestBollingerBands(t *testing.T) {
+	tests := []struct {
+		in           []float64
+		sd, up, down float64
+	}{
+		// sd по генеральной совокупности: sqrt(2/3)
+		{[]float64{1, 2, 3}, math.Sqrt(2.0 / 3), 2 + 2*math.Sqrt(2.0/3), 2 - 2*math.Sqrt(2.0/3)},
+		// 2,3,6: дисперсия 26/9
+		{[]float64{1, 2, 3, 6}, math.Sqrt(26.0 / 9), 11.0/3 + 2*math.Sqrt(26.0/9), 11.0/3 - 2*math.Sqrt(26.0/9)},
+	}
+	for _, tt := range tests {
+		b := NewBollinger(3, 2)
+		for _, x := range tt.in {
+			b.Update(x)
+		}
+		if !near(b.StdDev(), tt.sd) || !near(b.Upper(), tt.up) || !near(b.Lower(), tt.down) {
+			t.Fatalf("%v: sd/upper/lower = %.6f/%.6f/%.6f, want %.6f/%.6f/%.6f",
+				tt.in, b.StdDev(), b.Upper(), b.Lower(), tt.sd, tt.up, tt.down)
+		}
+		if w := (tt.up - tt.down) / b.Value(); !near(b.Width(), w) {
+			t.Fatalf("%v: Width = %.6f, want %.6f", tt.in, b.Width(), w)
+		}
+	}
+}
+
+func TestATR(t *testing.T) {
+	// TR: 2, 2, 3, 1, 2.5
+	want := []struct {
+		ready bool
+		v     float64
+	}{{false, 2}, {true, 2}, {true, 2.5}, {true, 1.75}, {true, 2.125}}
+
+	a := NewATR(2)
+	for i, b := range bars {
+		a.UpdateBar(b)
+		if a.Ready() != want[i].ready || !near(a.Value(), want[i].v) {
+			t.Fatalf("bar %d: ATR = %.6f ready=%t, want %.6f ready=%t", i, a.Value(), a.Ready(), want[i].v, want[i].ready)
+		}
+	}
+}
+
+func TestADX(t *testing.T) {
+	// +DM: 1, 1, 0, 0; -DM: 0, 0, 0, 2; TR: 2, 3, 1, 2.5 (RMA 2)
+	want := []struct {
+		ready            bool
+		adx, plus, minus float64
+	}{
+		{false, 0, 0, 0},
+		{false, 0, 50, 0},                           // TR ещё не сглажен
+		{false, 100, 40, 0},                         // DX 100
+		{true, 100, 100 * 0.5 / 1.75, 0},            // DX 100
+		{true, 80, 100 * 0.25 / 2.125, 100 / 2.125}, // DX 60
+	}
+
+	a := NewADX(2)
+	for i, b := range bars {
+		a.UpdateBar(b)
+		w := want[i]
+		if a.Ready() != w.ready || !near(a.Value(), w.adx) || !near(a.PlusDI(), w.plus) || !near(a.MinusDI(), w.minus) {
+			t.Fatalf("bar %d: ADX %.4f +DI %.4f -DI %.4f ready=%t, want %.4f %.4f %.4f ready=%t",
+				i, a.Value(), a.PlusDI(), a.MinusDI(), a.Ready(), w.adx, w.plus, w.minus, w.ready)
+		}
+	}
+}
+
+func TestDonchian(t *testing.T) {
+	want := []struct {
+		ready        bool
+		upper, lower float64
+	}{{false, 10, 8}, {true, 11, 8}, {true, 12, 9}, {true, 12, 9}, {true, 11, 8}}
+
+	d := NewDonchian(2)
+	for i, b := range bars {
+		d.UpdateBar(b)
+		w := want[i]
+		if d.Ready() != w.ready || d.Upper() != w.upper || d.Lower() != w.lower {
+			t.Fatalf("bar %d: %.2f/%.2f ready=%t, want %.2f/%.2f ready=%t", i, d.Upper(), d.Lower(), d.Ready(), w.upper, w.lower, w.ready)
+		}
+		if d.Value() != (w.upper+w.lower)/2 {
+			t.Fatalf("bar %d: mid = %.2f", i, d.Value())
+		}
+	}
+}
+
+func TestSupertrend(t *testing.T) {
+	// ATR(2): -, 2, 2.5, 1.75, 2.125; hl2: 9, 10, 10.5, 10.5, 9.25
+	want := []struct {
+		dir int
+		v   float64
+	}{
+		{0, 0},
+		{1, 8},       // старт: close 10 >= hl2 -> вверх, линия hl2 - ATR
+		{1, 8},       // нижняя 8 не опускается
+		{1, 8.75},    // нижняя подтягивается к 10.5 - 1.75
+		{-1, 11.375}, // close 8.5 под 8.75 -> вниз, верхняя 9.25 + 2.125
+	}
+
+	s := NewSupertrend(2, 1)
+	for i, b := range bars {
+		s.UpdateBar(b)
+		if s.Dir() != want[i].dir || !near(s.Value(), want[i].v) {
+			t.Fatalf("bar %d: dir %d value %.4f, want %d %.4f", i, s.Dir(), s.Value(), want[i].dir, want[i].v)
+		}
+	}
+}
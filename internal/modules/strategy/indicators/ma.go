@@ -0,0 +1,77 @@
+package indicators
+
+// SMA — простое скользящее среднее за period значений.
+type SMA struct {
+	w   ring
+	sum float64
+}
+
+func NewSMA(period int) *SMA { return &SMA{w: newRing(period)} }
+
+func (s *SMA) Update(x float64) {
+	if old, ok := s.w.Push(x); ok {
+		s.sum -= old
+	}
+	s.sum += x
+}
+
+func (s *SMA) Ready() bool { return s.w.Full() }
+
+func (s *SMA) Value() float64 {
+	if s.w.Len() == 0 {
+		return 0
+	}
+	return s.sum / float64(s.w.Len())
+}
+
+// EMA — экспоненциальное среднее, alpha = 2/(period+1); стартует с первого
+// значения, Ready после period обновлений.
+type EMA struct {
+	period int
+	alpha  float64
+	value  float64
+	warmup int
+}
+
+func NewEMA(period int) *EMA {
+	period = normPeriod(period)
+	return &EMA{period: period, alpha: 2.0 / (float64(period) + 1)}
+}
+
+func (e *EMA) Update(x float64) {
+	if e.warmup == 0 {
+		e.value = x
+		e.warmup = 1
+		return
+	}
+	e.value = e.alpha*x + (1-e.alpha)*e.value
+	if e.warmup < e.period {
+		e.warmup++
+	}
+}
+
+func (e *EMA) Ready() bool    { return e.warmup >= e.period }
+func (e *EMA) Value() float64 { return e.value }
+
+// RMA — сглаживание Уайлдера (alpha = 1/period): первые period значений
+// усредняем, дальше (prev*(period-1) + x) / period. База для RSI/ATR/ADX.
+type RMA struct {
+	period int
+	value  float64
+	n      int
+}
+
+func NewRMA(period int) *RMA { return &RMA{period: normPeriod(period)} }
+
+func (r *RMA) Update(x float64) {
+	r.n++
+	if r.n <= r.period {
+		r.value += (x - r.value) / float64(r.n)
+		return
+	}
+	p := float64(r.period)
+	r.value = (r.value*(p-1) + x) / p
+}
+
+func (r *RMA) Ready() bool    { return r.n >= r.period }
+func (r *RMA) Value() float64 { return r.value }
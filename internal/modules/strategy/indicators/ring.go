@@ -0,0 +1,70 @@
+package indicators
+
+// ring — окно последних n значений фиксированной ёмкости.
+type ring struct {
+	buf  []float64
+	head int // индекс самого старого
+	n    int
+}
+
+func newRing(size int) ring {
+	return ring{buf: make([]float64, normPeriod(size))}
+}
+
+// Push добавляет x; если окно было полным — возвращает вытесненное значение.
+func (r *ring) Push(x float64) (old float64, evicted bool) {
+	if r.n < len(r.buf) {
+		r.buf[(r.head+r.n)%len(r.buf)] = x
+		r.n++
+		return 0, false
+	}
+	old = r.buf[r.head]
+	r.buf[r.head] = x
+	r.head = (r.head + 1) % len(r.buf)
+	return old, true
+}
+
+func (r *ring) Len() int   { return r.n }
+func (r *ring) Full() bool { return r.n == len(r.buf) }
+
+// monoDeque — монотонная очередь для скользящего max/min за окно:
+// значения в ней убывают (max) или возрастают (min), голова — экстремум окна.
+type monoDeque struct {
+	seq  []int
+	val  []float64
+	head int
+	n    int
+	max  bool
+}
+
+func newMonoDeque(window int, max bool) monoDeque {
+	c := normPeriod(window) + 1
+	return monoDeque{seq: make([]int, c), val: make([]float64, c), max: max}
+}
+
+// Push — значение v с порядковым номером i; всё старше i-window+1 уходит из окна.
+func (d *monoDeque) Push(i int, v float64, window int) {
+	c := len(d.val)
+	for d.n > 0 {
+		t := (d.head + d.n - 1) % c
+		if (d.max && d.val[t] > v) || (!d.max && d.val[t] < v) {
+			break
+		}
+		d.n--
+	}
+	t := (d.head + d.n) % c
+	d.seq[t], d.val[t] = i, v
+	d.n++
+	for d.seq[d.head] <= i-window {
+		d.head = (d.head + 1) % c
+		d.n--
+	}
+}
+
+// Front — экстремум текущего окна (0, если пусто).
+func (d *monoDeque) Front() float64 {
+	if d.n == 0 {
+		return 0
+	}
+	return d.val[d.head]
+}
@@ -0,0 +1,42 @@
+package indicators
+
+// RSI — индекс относительной силы по Уайлдеру (0..100).
+type RSI struct {
+	gain, loss *RMA
+	prev       float64
+	n          int
+}
+
+func NewRSI(period int) *RSI {
+	if period <= 1 {
+		period = 2
+	}
+	return &RSI{gain: NewRMA(period), loss: NewRMA(period)}
+}
+
+func (r *RSI) Update(x float64) {
+	r.n++
+	if r.n == 1 {
+		r.prev = x
+		return
+	}
+	ch := x - r.prev
+	r.prev = x
+	r.gain.Update(max(ch, 0))
+	r.loss.Update(max(-ch, 0))
+}
+
+// Ready — набралось period изменений цены.
+func (r *RSI) Ready() bool { return r.gain.Ready() }
+
+// Value — 0..100; без движения цены — 50.
+func (r *RSI) Value() float64 {
+	g, l := r.gain.Value(), r.loss.Value()
+	if l == 0 {
+		if g == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+g/l)
+}
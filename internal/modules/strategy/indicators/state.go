@@ -0,0 +1,98 @@
+package indicators
+
+// Состояния индикаторов для снапшота движков: State() снимает, Restore()
+// возвращает в индикатор, созданный с теми же параметрами (период из конфига
+// в состояние не входит — за совпадением конфига следит владелец снапшота).
+
+type EMAState struct {
+	Value  float64 `json:"v"`
+	Warmup int     `json:"w"`
+}
+
+func (e *EMA) State() EMAState { return EMAState{Value: e.value, Warmup: e.warmup} }
+
+func (e *EMA) Restore(s EMAState) {
+	e.value = s.Value
+	e.warmup = min(s.Warmup, e.period)
+}
+
+type RMAState struct {
+	Value float64 `json:"v"`
+	N     int     `json:"n"`
+}
+
+func (r *RMA) State() RMAState { return RMAState{Value: r.value, N: r.n} }
+
+func (r *RMA) Restore(s RMAState) {
+	r.value = s.Value
+	r.n = s.N
+}
+
+type RSIState struct {
+	Gain RMAState `json:"gain"`
+	Loss RMAState `json:"loss"`
+	Prev float64  `json:"prev"`
+	N    int      `json:"n"`
+}
+
+func (r *RSI) State() RSIState {
+	return RSIState{Gain: r.gain.State(), Loss: r.loss.State(), Prev: r.prev, N: r.n}
+}
+
+func (r *RSI) Restore(s RSIState) {
+	r.gain.Restore(s.Gain)
+	r.loss.Restore(s.Loss)
+	r.prev = s.Prev
+	r.n = s.N
+}
+
+type ATRState struct {
+	RMA       RMAState `json:"rma"`
+	PrevClose float64  `json:"prev_close"`
+}
+
+func (a *ATR) State() ATRState { return ATRState{RMA: a.rma.State(), PrevClose: a.prevClose} }
+
+func (a *ATR) Restore(s ATRState) {
+	a.rma.Restore(s.RMA)
+	a.prevClose = s.PrevClose
+}
+
+// DonchianState — содержимое монотонных очередей: этого хватает, чтобы
+// продолжить окно без исходных баров.
+type DonchianState struct {
+	N  int          `json:"n"`
+	Hi []DequePoint `json:"hi"`
+	Lo []DequePoint `json:"lo"`
+}
+
+type DequePoint struct {
+	Seq int     `json:"i"`
+	V   float64 `json:"v"`
+}
+
+func (d *Donchian) State() DonchianState {
+	return DonchianState{N: d.n, Hi: d.hi.points(), Lo: d.lo.points()}
+}
+
+func (d *Donchian) Restore(s DonchianState) {
+	d.n = s.N
+	d.hi = newMonoDeque(d.period, true)
+	d.lo = newMonoDeque(d.period, false)
+	// очереди уже монотонны: Push ничего не выкинет, кроме вышедшего из окна
+	for _, p := range s.Hi {
+		d.hi.Push(p.Seq, p.V, d.period)
+	}
+	for _, p := range s.Lo {
+		d.lo.Push(p.Seq, p.V, d.period)
+	}
+}
+
+func (d *monoDeque) points() []DequePoint {
+	out := make([]DequePoint, d.n)
+	for k := range out {
+		i := (d.head + k) % len(d.val)
+		out[k] = DequePoint{Seq: d.seq[i], V: d.val[i]}
+	}
+	return out
+}
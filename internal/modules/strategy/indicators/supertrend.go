@@ -0,0 +1,68 @@
+package indicators
+
+// Supertrend — линия hl2 ± mult*ATR, которая двигается только в сторону
+// тренда и переворачивается, когда close её пробивает. Value — текущая
+// линия (под ценой в аптренде, над ценой в даунтренде).
+type Supertrend struct {
+	mult         float64
+	atr          *ATR
+	upper, lower float64
+	prevClose    float64
+	dir          int // +1 up, -1 down, 0 — ещё не определён
+}
+
+func NewSupertrend(period int, mult float64) *Supertrend {
+	return &Supertrend{mult: mult, atr: NewATR(period)}
+}
+
+func (s *Supertrend) UpdateBar(b Bar) {
+	s.atr.UpdateBar(b)
+	if !s.atr.Ready() {
+		s.prevClose = b.Close
+		return
+	}
+
+	hl2 := (b.High + b.Low) / 2
+	up := hl2 + s.mult*s.atr.Value()
+	lo := hl2 - s.mult*s.atr.Value()
+
+	if s.dir == 0 {
+		s.upper, s.lower, s.dir = up, lo, 1
+		if b.Close < hl2 {
+			s.dir = -1
+		}
+		s.prevClose = b.Close
+		return
+	}
+
+	// полосы сужаются, но не расширяются, пока цена их не пересекла
+	if up < s.upper || s.prevClose > s.upper {
+		s.upper = up
+	}
+	if lo > s.lower || s.prevClose < s.lower {
+		s.lower = lo
+	}
+
+	switch {
+	case s.dir < 0 && b.Close > s.upper:
+		s.dir = 1
+	case s.dir > 0 && b.Close < s.lower:
+		s.dir = -1
+	}
+	s.prevClose = b.Close
+}
+
+func (s *Supertrend) Ready() bool { return s.dir != 0 }
+
+// Dir — +1 аптренд, -1 даунтренд, 0 — не прогрет.
+func (s *Supertrend) Dir() int { return s.dir }
+
+func (s *Supertrend) Value() float64 {
+	switch {
+	case s.dir > 0:
+		return s.lower
+	case s.dir < 0:
+		return s.upper
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package indicators
+
+// VWAP — средняя цена, взвешенная по объёму, по типичной цене (H+L+C)/3.
+// period > 0 — скользящее окно из period баров, 0 — накопительно до Reset
+// (якорь сессии выбирает вызывающий).
+type VWAP struct {
+	period  int
+	pv, vol float64
+	wpv     ring
+	wvol    ring
+	n       int
+}
+
+func NewVWAP(period int) *VWAP {
+	v := &VWAP{period: period}
+	if period > 0 {
+		v.wpv, v.wvol = newRing(period), newRing(period)
+	}
+	return v
+}
+
+func (v *VWAP) UpdateBar(b Bar) {
+	pv := (b.High + b.Low + b.Close) / 3 * b.Volume
+	if v.period > 0 {
+		if old, ok := v.wpv.Push(pv); ok {
+			v.pv -= old
+		}
+		if old, ok := v.wvol.Push(b.Volume); ok {
+			v.vol -= old
+		}
+	}
+	v.pv += pv
+	v.vol += b.Volume
+	v.n++
+}
+
+// Reset — новый якорь накопительного VWAP.
+func (v *VWAP) Reset() { *v = *NewVWAP(v.period) }
+
+func (v *VWAP) Ready() bool {
+	if v.period > 0 {
+		return v.wvol.Full() && v.vol > 0
+	}
+	return v.vol > 0
+}
+
+func (v *VWAP) Value() float64 {
+	if v.vol <= 0 {
+		return 0
+	}
+	return v.pv / v.vol
+}
+
+// OBV — on-balance volume: объём бара с плюсом на росте close, с минусом на падении.
+type OBV struct {
+	value, prev float64
+	n           int
+}
+
+func NewOBV() *OBV { return &OBV{} }
+
+func (o *OBV) UpdateBar(b Bar) {
+	o.n++
+	if o.n > 1 {
+		switch {
+		case b.Close > o.prev:
+			o.value += b.Volume
+		case b.Close < o.prev:
+			o.value -= b.Volume
+		}
+	}
+	o.prev = b.Close
+}
+
+func (o *OBV) Ready() bool    { return o.n > 1 }
+func (o *OBV) Value() float64 { return o.value }
@@ -3,6 +3,8 @@ package strategy
 import (
 	"context"
 	"log"
+	"time"
+	"trade_bot/internal/modules/config"
 	"trade_bot/internal/modules/strategy/service"
 
 	"go.uber.org/fx"
@@ -27,13 +29,41 @@ func Module() fx.Option {
 			asSendOnlySignals, // chan<- models.Signal
 			newSignalsStopChan,
 			asSendOnlyStopSignals,
-			service.NewEngine, // service.Engine
-			service.NewHub,    // *service.Hub (получит V2Config, Notifier, chan<-Signal, Engine)
+			service.NewEngines,     // service.Engines (cfg.Strategy.Engines из реестра)
+			service.NewCorrelation, // *service.Correlation
+			service.NewATR,         // *service.ATR
+			service.NewHub,         // *service.Hub (получит V2Config, Notifier, chan<-Signal, chan<-CandleTick, Engines, Correlation, ATR)
 		),
 
-		fx.Invoke(func(lc fx.Lifecycle, hub *service.Hub, ticks <-chan okxws.OutTick) {
+		fx.Invoke(func(lc fx.Lifecycle, cfg *config.Config, hub *service.Hub, ticks <-chan okxws.OutTick) {
+			stop := make(chan struct{})
 			lc.Append(fx.Hook{
 				OnStart: func(ctx context.Context) error {
+					// снапшот движков — до прогрева (bootstrap докачает только разрыв)
+					if n, err := hub.LoadState(ctx); err != nil {
+						log.Printf("[STRAT] restore state: %v", err)
+					} else if n > 0 {
+						log.Printf("[STRAT] restored state for %d symbols", n)
+					}
+
+					// периодический снапшот
+					if every := cfg.Strategy.State.Every; cfg.Strategy.State.Path != "" && every > 0 {
+						go func() {
+							t := time.NewTicker(every)
+							defer t.Stop()
+							for {
+								select {
+								case <-stop:
+									return
+								case <-t.C:
+									if err := hub.SaveState(); err != nil {
+										log.Printf("[STRAT] save state: %v", err)
+									}
+								}
+							}
+						}()
+					}
+
 					go func() {
 						log.Printf("[STRAT] hub loop started")
 						for {
@@ -52,6 +82,13 @@ func Module() fx.Option {
 					}()
 					return nil
 				},
+				OnStop: func(ctx context.Context) error {
+					close(stop)
+					if err := hub.SaveState(); err != nil {
+						log.Printf("[STRAT] save state: %v", err)
+					}
+					return nil
+				},
 			})
 		}),
 	)
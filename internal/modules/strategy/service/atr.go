@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	ind "trade_bot/internal/modules/strategy/indicators"
+)
+
+// atrMaxBars — сколько последних баров храним для LowHigh.
+const atrMaxBars = 50
+
+// atrState — ATR по Уайлдеру и последние бары символа/ТФ.
+type atrState struct {
+	atr     *ind.ATR
+	lastEnd time.Time
+
+	// последние бары (до atrMaxBars) — для трейлинга за экстремумом
+	highs []float64
+	lows  []float64
+}
+
+type atrKey struct {
+	instID string
+	tf     string
+}
+
+// ATR — трекер ATR (и экстремумов последних баров) по символу и таймфрейму;
+// кормится теми же закрытыми свечами, что и Engine.
+type ATR struct {
+	period int
+
+	mu sync.RWMutex
+	st map[atrKey]*atrState
+}
+
+func NewATR(cfg *config.Config) *ATR {
+	p := cfg.Strategy.ATRPeriod
+	if p <= 0 {
+		p = 14
+	}
+	return &ATR{
+		period: p,
+		st:     make(map[atrKey]*atrState),
+	}
+}
+
+// OnCandle — закрытая свеча любого ТФ.
+func (a *ATR) OnCandle(ct models.CandleTick) {
+	if ct.High <= 0 || ct.Low <= 0 || ct.Close <= 0 {
+		return
+	}
+	k := atrKey{instID: ct.InstID, tf: helper.NormTF(ct.TimeframeRaw)}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.st[k]
+	if !ok {
+		s = &atrState{atr: ind.NewATR(a.period)}
+		a.st[k] = s
+	}
+	if !s.lastEnd.IsZero() && !ct.End.After(s.lastEnd) {
+		return // повтор/старая свеча
+	}
+	s.lastEnd = ct.End
+	s.atr.UpdateBar(ind.BarOf(ct))
+
+	s.highs = append(s.highs, ct.High)
+	s.lows = append(s.lows, ct.Low)
+	if len(s.highs) > atrMaxBars {
+		s.highs = s.highs[1:]
+		s.lows = s.lows[1:]
+	}
+}
+
+// Value — текущий ATR; 0 — ещё не прогрет.
+func (a *ATR) Value(instID, tf string) float64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	s, ok := a.st[atrKey{instID: instID, tf: helper.NormTF(tf)}]
+	if !ok || !s.atr.Ready() {
+		return 0
+	}
+	return s.atr.Value()
+}
+
+// LowHigh — min low и max high последних n закрытых баров; ok == false — баров меньше n.
+func (a *ATR) LowHigh(instID, tf string, n int) (lo, hi float64, ok bool) {
+	if n <= 0 || n > atrMaxBars {
+		return 0, 0, false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	s, found := a.st[atrKey{instID: instID, tf: helper.NormTF(tf)}]
+	if !found || len(s.lows) < n {
+		return 0, 0, false
+	}
+	k := len(s.lows) - n
+	return minSlice(s.lows[k:]), maxSlice(s.highs[k:]), true
+}
+
+// atrSnap — состояние ATR символа/ТФ в снапшоте Hub.
+type atrSnap struct {
+	ATR     ind.ATRState `json:"atr"`
+	LastEnd time.Time    `json:"last_end"`
+	Highs   []float64    `json:"highs"`
+	Lows    []float64    `json:"lows"`
+}
+
+// Snapshot — ключ "instId|tf".
+func (a *ATR) Snapshot() (json.RawMessage, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	out := make(map[string]atrSnap, len(a.st))
+	for k, s := range a.st {
+		out[k.instID+"|"+k.tf] = atrSnap{
+			ATR:     s.atr.State(),
+			LastEnd: s.lastEnd,
+			Highs:   s.highs,
+			Lows:    s.lows,
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (a *ATR) Restore(raw json.RawMessage) error {
+	var in map[string]atrSnap
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return fmt.Errorf("atr restore: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, s := range in {
+		instID, tf, ok := strings.Cut(key, "|")
+		if !ok || len(s.Highs) != len(s.Lows) {
+			continue
+		}
+		st := &atrState{atr: ind.NewATR(a.period), lastEnd: s.LastEnd, highs: s.Highs, lows: s.Lows}
+		st.atr.Restore(s.ATR)
+		a.st[atrKey{instID: instID, tf: tf}] = st
+	}
+	return nil
+}
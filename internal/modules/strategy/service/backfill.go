@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+
+	okxws "trade_bot/internal/modules/okx_websocket/service"
+)
+
+// BeginBackfill — по instId начинается докачка истории по REST: живые LTF/HTF
+// свечи с этого момента откладываются до EndBackfill, иначе свежая свеча
+// обгонит историю и та будет отброшена как устаревшая.
+func (h *Hub) BeginBackfill(instID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.held[instID]; !ok {
+		h.held[instID] = nil
+	}
+}
+
+// Backfill — свеча докачки: идёт в движки сразу, мимо отложенных.
+func (h *Hub) Backfill(ctx context.Context, t okxws.OutTick) {
+	h.feed(ctx, t)
+}
+
+// EndBackfill — докачка instId закончена (или не удалась): отложенные живые
+// свечи уходят в движки по порядку. Пока очередь разбирается, новые свечи
+// встают в её конец — символ отпускается, только когда она пуста.
+func (h *Hub) EndBackfill(ctx context.Context, instID string) {
+	for {
+		h.mu.Lock()
+		batch, ok := h.held[instID]
+		if !ok || len(batch) == 0 {
+			delete(h.held, instID)
+			h.mu.Unlock()
+			return
+		}
+		h.held[instID] = []okxws.OutTick{}
+		h.mu.Unlock()
+
+		for _, t := range batch {
+			h.feed(ctx, t)
+		}
+	}
+}
+
+// hold — откладывает живую свечу символа в докачке; true — отложена.
+func (h *Hub) hold(t okxws.OutTick) bool {
+	if !h.strategyTF(t.Timeframe) {
+		return false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	q, ok := h.held[t.InstID]
+	if !ok {
+		return false
+	}
+	h.held[t.InstID] = append(q, t)
+	return true
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	okxws "trade_bot/internal/modules/okx_websocket/service"
+)
+
+// recEngine — движок, который запоминает End полученных свечей.
+type recEngine struct {
+	mu   sync.Mutex
+	ends []time.Time
+}
+
+func (e *recEngine) OnCandle(t models.CandleTick) (models.Signal, bool, bool) {
+	e.mu.Lock()
+	e.ends = append(e.ends, t.End)
+	e.mu.Unlock()
+	return models.Signal{}, false, false
+}
+func (e *recEngine) IsReady(string) bool       { return false }
+func (e *recEngine) Dump(string) string        { return "" }
+func (e *recEngine) Name() string              { return "rec" }
+func (e *recEngine) Type() models.StrategyType { return models.DefaultStrategy }
+func (e *recEngine) WarmupBars(string) int     { return 5 }
+
+func newTestHub(eng Engine) *Hub {
+	cfg := &config.Config{}
+	cfg.Strategy.LTF, cfg.Strategy.HTF = "15m", "1h"
+	return NewHub(cfg, nil, make(chan models.Signal, 16), make(chan models.CandleTick, 16),
+		Engines{eng}, NewCorrelation(cfg), NewATR(cfg))
+}
+
+func tick15m(end time.Time) okxws.OutTick {
+	return okxws.OutTick{InstID: "BTC-USDT-SWAP", Timeframe: "15m", Candle: models.CandleTick{
+		Open: 100, High: 101, Low: 99, Close: 100, Start: end.Add(-15 * time.Minute), End: end,
+	}}
+}
+
+// Живая свеча, пришедшая до докачки, ждёт её и идёт после истории.
+func TestHubHoldsLiveTicksDuringBackfill(t *testing.T) {
+	eng := &recEngine{}
+	h := newTestHub(eng)
+	ctx := context.Background()
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return t0.Add(time.Duration(i) * 15 * time.Minute) }
+
+	h.BeginBackfill("BTC-USDT-SWAP")
+	h.OnTick(ctx, tick15m(at(5)))
+	for i := 1; i <= 5; i++ { // докачка перекрывается с живой свечой
+		h.Backfill(ctx, tick15m(at(i)))
+	}
+	h.EndBackfill(ctx, "BTC-USDT-SWAP")
+	h.OnTick(ctx, tick15m(at(6)))
+	h.OnTick(ctx, tick15m(at(6))) // повтор
+
+	if len(eng.ends) != 6 {
+		t.Fatalf("engine got %d candles, want 6: %v", len(eng.ends), eng.ends)
+	}
+	for i, end := range eng.ends {
+		if !end.Equal(at(i + 1)) {
+			t.Fatalf("candle %d End = %s, want %s", i, end, at(i+1))
+		}
+	}
+}
+
+// Без докачки опоздавшая свеча отбрасывается, а не ломает порядок.
+func TestHubAdmitRejectsStaleCandles(t *testing.T) {
+	eng := &recEngine{}
+	h := newTestHub(eng)
+	ctx := context.Background()
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	h.OnTick(ctx, tick15m(t0.Add(30*time.Minute)))
+	h.OnTick(ctx, tick15m(t0.Add(15*time.Minute)))
+
+	if len(eng.ends) != 1 {
+		t.Fatalf("engine got %d candles, want only the first", len(eng.ends))
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+)
+
+// corrMinOverlap — меньше общих баров: корреляцию не считаем.
+const corrMinOverlap = 20
+
+// Correlation — скользящая корреляция доходностей LTF-свечей между инструментами.
+// Копит закрытия из тех же тиков, что получает Hub; матрица считается лениво —
+// по паре на запрос, на окне из последних Window баров.
+type Correlation struct {
+	tf     string
+	window int
+
+	mu     sync.RWMutex
+	series map[string][]corrPoint // instId -> последние window+1 закрытий
+}
+
+type corrPoint struct {
+	end   time.Time
+	close float64
+}
+
+func NewCorrelation(cfg *config.Config) *Correlation {
+	w := cfg.Strategy.CorrWindow
+	if w <= 0 {
+		w = 96
+	}
+	return &Correlation{
+		tf:     helper.NormTF(cfg.Strategy.LTF),
+		window: w,
+		series: make(map[string][]corrPoint),
+	}
+}
+
+// OnCandle — закрытая свеча; не-LTF игнорируем.
+func (c *Correlation) OnCandle(ct models.CandleTick) {
+	if helper.NormTF(ct.TimeframeRaw) != c.tf || ct.Close <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.series[ct.InstID]
+	if n := len(s); n > 0 && !ct.End.After(s[n-1].end) {
+		return // повтор/старая свеча
+	}
+	s = append(s, corrPoint{end: ct.End, close: ct.Close})
+	if len(s) > c.window+1 {
+		s = s[len(s)-c.window-1:]
+	}
+	c.series[ct.InstID] = s
+}
+
+// Corr — корреляция Пирсона лог-доходностей a и b по общим барам.
+// ok == false — данных мало (инструмент новый или не в вотчлисте).
+func (c *Correlation) Corr(a, b string) (float64, bool) {
+	if a == b {
+		return 1, true
+	}
+
+	c.mu.RLock()
+	ra := returns(c.series[a])
+	rb := returns(c.series[b])
+	c.mu.RUnlock()
+
+	var xs, ys []float64
+	for end, x := range ra {
+		if y, ok := rb[end]; ok {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	if len(xs) < corrMinOverlap {
+		return 0, false
+	}
+	return pearson(xs, ys)
+}
+
+// returns — лог-доходность бара по времени его закрытия.
+func returns(s []corrPoint) map[time.Time]float64 {
+	out := make(map[time.Time]float64, len(s))
+	for i := 1; i < len(s); i++ {
+		out[s[i].end] = math.Log(s[i].close / s[i-1].close)
+	}
+	return out
+}
+
+func pearson(xs, ys []float64) (float64, bool) {
+	n := float64(len(xs))
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= n
+	my /= n
+
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(vx*vy), true
+}
+
+// corrSnap — точка ряда в снапшоте Hub.
+type corrSnap struct {
+	End   time.Time `json:"end"`
+	Close float64   `json:"close"`
+}
+
+func (c *Correlation) Snapshot() (json.RawMessage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make(map[string][]corrSnap, len(c.series))
+	for inst, s := range c.series {
+		pts := make([]corrSnap, len(s))
+		for i, p := range s {
+			pts[i] = corrSnap{End: p.end, Close: p.close}
+		}
+		out[inst] = pts
+	}
+	return json.Marshal(out)
+}
+
+func (c *Correlation) Restore(raw json.RawMessage) error {
+	var in map[string][]corrSnap
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return fmt.Errorf("correlation restore: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for inst, pts := range in {
+		if len(pts) > c.window+1 {
+			pts = pts[len(pts)-c.window-1:]
+		}
+		s := make([]corrPoint, len(pts))
+		for i, p := range pts {
+			s[i] = corrPoint{end: p.End, close: p.Close}
+		}
+		c.series[inst] = s
+	}
+	return nil
+}
@@ -1,12 +1,14 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"sync"
 	"time"
 	"trade_bot/internal/helper"
 	"trade_bot/internal/modules/config"
+	ind "trade_bot/internal/modules/strategy/indicators"
 
 	"trade_bot/internal/models"
 )
@@ -27,14 +29,13 @@ type DonchianV2HTF struct {
 
 type v2State struct {
 	// LTF
-	highs    []float64
-	lows     []float64
+	don      *ind.Donchian
 	wLTF     int
 	readyLTF bool
 
 	// HTF
-	emaFast  emaState
-	emaSlow  emaState
+	emaFast  *ind.EMA
+	emaSlow  *ind.EMA
 	wHTF     int
 	readyHTF bool
 	trend    Trend
@@ -56,10 +57,9 @@ func (e *DonchianV2HTF) get(sym string) *v2State {
 		return s
 	}
 	s := &v2State{
-		highs:   make([]float64, 0, e.cfg.Strategy.DonchianPeriod),
-		lows:    make([]float64, 0, e.cfg.Strategy.DonchianPeriod),
-		emaFast: newEMA(e.cfg.Strategy.HTFEmaFast),
-		emaSlow: newEMA(e.cfg.Strategy.HTFEmaSlow),
+		don:     ind.NewDonchian(e.cfg.Strategy.DonchianPeriod),
+		emaFast: ind.NewEMA(e.cfg.Strategy.HTFEmaFast),
+		emaSlow: ind.NewEMA(e.cfg.Strategy.HTFEmaSlow),
 		trend:   TrendNone,
 	}
 	e.st[sym] = s
@@ -122,7 +122,7 @@ func (e *DonchianV2HTF) OnCandle(t models.CandleTick) (models.Signal, bool, bool
 
 	// ---------------- LTF: Donchian breakout ----------------
 	case helper.NormTF(e.cfg.Strategy.LTF):
-		// 0) если буфер уже прогрет — считаем канал ДО добавления текущей свечи
+		// 0) если канал уже прогрет — считаем канал ДО добавления текущей свечи
 		var (
 			dh, dl  float64
 			haveCh  bool
@@ -130,9 +130,9 @@ func (e *DonchianV2HTF) OnCandle(t models.CandleTick) (models.Signal, bool, bool
 			bodyPct float64
 		)
 
-		if len(st.highs) >= e.cfg.Strategy.DonchianPeriod {
-			dh = maxSlice(st.highs)
-			dl = minSlice(st.lows)
+		if st.don.Ready() {
+			dh = st.don.Upper()
+			dl = st.don.Lower()
 			if dh > 0 && dl > 0 && dh > dl {
 				haveCh = true
 			}
@@ -140,7 +140,7 @@ func (e *DonchianV2HTF) OnCandle(t models.CandleTick) (models.Signal, bool, bool
 
 		// 1) инкремент прогрева LTF (по закрытым свечам)
 		st.wLTF++
-		if st.wLTF >= e.cfg.Strategy.MinWarmupLTF && len(st.highs) >= e.cfg.Strategy.DonchianPeriod && !st.readyLTF {
+		if st.wLTF >= e.cfg.Strategy.MinWarmupLTF && st.don.Ready() && !st.readyLTF {
 			st.readyLTF = true
 			becameReady = true
 		}
@@ -201,21 +201,18 @@ func (e *DonchianV2HTF) OnCandle(t models.CandleTick) (models.Signal, bool, bool
 						TF:       helper.NormTF(e.cfg.Strategy.LTF),
 						Side:     side,
 						Price:    t.Close,
-						Strategy: "donchian_v2_htf",
+						Strategy: models.StrategyDonchianV2,
 						Reason: fmt.Sprintf(
 							"trend=%v Don[%d] chPct=%.4f bodyPct=%.4f bo=%.4f upBo=%.4f dnBo=%.4f dh=%.6f dl=%.6f",
 							st.trend, e.cfg.Strategy.DonchianPeriod, chPct, bodyPct, bo, upBoPct, dnBoPct, dh, dl,
 						),
 						CreatedAt: time.Now(),
+						ChHigh:    dh,
+						ChLow:     dl,
 					}
 
-					// 3) теперь добавляем текущую свечу в буфер и выходим с сигналом
-					st.highs = append(st.highs, t.High)
-					st.lows = append(st.lows, t.Low)
-					if len(st.highs) > e.cfg.Strategy.DonchianPeriod {
-						st.highs = st.highs[1:]
-						st.lows = st.lows[1:]
-					}
+					// 3) теперь добавляем текущую свечу в канал и выходим с сигналом
+					st.don.UpdateBar(ind.BarOf(t))
 
 					fmt.Printf("[SIG] %s %s close=%.6f dh=%.6f dl=%.6f trend=%v upBo=%.4f dnBo=%.4f\n",
 						t.InstID, side, t.Close, dh, dl, st.trend, upBoPct, dnBoPct)
@@ -226,13 +223,8 @@ func (e *DonchianV2HTF) OnCandle(t models.CandleTick) (models.Signal, bool, bool
 		}
 
 	UPDATE_BUFFER:
-		// 4) если сигнала нет — просто обновляем буфер текущей свечой
-		st.highs = append(st.highs, t.High)
-		st.lows = append(st.lows, t.Low)
-		if len(st.highs) > e.cfg.Strategy.DonchianPeriod {
-			st.highs = st.highs[1:]
-			st.lows = st.lows[1:]
-		}
+		// 4) если сигнала нет — просто обновляем канал текущей свечой
+		st.don.UpdateBar(ind.BarOf(t))
 
 		return models.Signal{}, false, becameReady
 
@@ -253,6 +245,19 @@ func (e *DonchianV2HTF) IsReady(symbol string) bool {
 
 func (e *DonchianV2HTF) Name() string { return "donchian_v2_htf1h" }
 
+func (e *DonchianV2HTF) Type() models.StrategyType { return models.StrategyDonchianV2 }
+
+func (e *DonchianV2HTF) WarmupBars(tf string) int {
+	sc := e.cfg.Strategy
+	switch helper.NormTF(tf) {
+	case helper.NormTF(sc.HTF):
+		return max(sc.HTFEmaFast, sc.HTFEmaSlow, sc.MinWarmupHTF)
+	case helper.NormTF(sc.LTF):
+		return max(sc.DonchianPeriod, sc.MinWarmupLTF)
+	}
+	return 0
+}
+
 func (e *DonchianV2HTF) Dump(symbol string) string {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -262,8 +267,8 @@ func (e *DonchianV2HTF) Dump(symbol string) string {
 		return "v2: no state"
 	}
 
-	dh := maxSlice(st.highs)
-	dl := minSlice(st.lows)
+	dh := st.don.Upper()
+	dl := st.don.Lower()
 
 	return fmt.Sprintf(
 		"v2[15m] w15=%d/%d ready15=%v dh=%.6f dl=%.6f | [1h] w1h=%d fast=%.6f slow=%.6f trend=%v ready1h=%v",
@@ -281,3 +286,64 @@ func (t Trend) String() string {
 		return "none"
 	}
 }
+
+// v2Snap — состояние символа в снапшоте Hub.
+type v2Snap struct {
+	Don      ind.DonchianState `json:"don"`
+	WLTF     int               `json:"w_ltf"`
+	ReadyLTF bool              `json:"ready_ltf"`
+
+	EmaFast  ind.EMAState `json:"ema_fast"`
+	EmaSlow  ind.EMAState `json:"ema_slow"`
+	WHTF     int          `json:"w_htf"`
+	ReadyHTF bool         `json:"ready_htf"`
+	Trend    Trend        `json:"trend"`
+
+	LastSignalEnd time.Time `json:"last_signal_end"`
+}
+
+func (e *DonchianV2HTF) Snapshot() (json.RawMessage, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	out := make(map[string]v2Snap, len(e.st))
+	for sym, st := range e.st {
+		out[sym] = v2Snap{
+			Don:           st.don.State(),
+			WLTF:          st.wLTF,
+			ReadyLTF:      st.readyLTF,
+			EmaFast:       st.emaFast.State(),
+			EmaSlow:       st.emaSlow.State(),
+			WHTF:          st.wHTF,
+			ReadyHTF:      st.readyHTF,
+			Trend:         st.trend,
+			LastSignalEnd: st.lastSignalEnd,
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (e *DonchianV2HTF) Restore(raw json.RawMessage) error {
+	var in map[string]v2Snap
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return fmt.Errorf("donchianV2 restore: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for sym, s := range in {
+		delete(e.st, sym)
+		st := e.get(sym)
+		st.don.Restore(s.Don)
+		st.wLTF = s.WLTF
+		st.readyLTF = s.ReadyLTF
+		st.emaFast.Restore(s.EmaFast)
+		st.emaSlow.Restore(s.EmaSlow)
+		st.wHTF = s.WHTF
+		st.readyHTF = s.ReadyHTF
+		st.trend = s.Trend
+		st.lastSignalEnd = s.LastSignalEnd
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/modules/config"
+	ind "trade_bot/internal/modules/strategy/indicators"
+
+	"trade_bot/internal/models"
+)
+
+// EMARSI — тренд по EMA fast/slow на HTF, вход на LTF по откату RSI:
+// в аптренде RSI уходит ниже PullbackLong («взвод»), а сигнал BUY — когда
+// не позже MaxPullback баров после этого он возвращается выше уровня бычьей свечой.
+// SELL — зеркально через PullbackShort.
+type EMARSI struct {
+	cfg *config.Config
+	mu  sync.Mutex
+	st  map[string]*emarsiState
+}
+
+type emarsiState struct {
+	// LTF
+	rsi      *ind.RSI
+	prevRSI  float64
+	wLTF     int
+	readyLTF bool
+
+	// откат: сторона, сколько баров ждём, экстремум отката (для стопа от канала)
+	armed     models.Side
+	armedBars int
+	swingHigh float64
+	swingLow  float64
+
+	// HTF
+	emaFast  *ind.EMA
+	emaSlow  *ind.EMA
+	wHTF     int
+	readyHTF bool
+	trend    Trend
+
+	// anti-spam: одна LTF свеча -> максимум 1 сигнал
+	lastSignalEnd time.Time
+}
+
+func NewEMARSI(cfg *config.Config) *EMARSI {
+	return &EMARSI{
+		cfg: cfg,
+		st:  make(map[string]*emarsiState),
+	}
+}
+
+func (e *EMARSI) get(sym string) *emarsiState {
+	if s, ok := e.st[sym]; ok {
+		return s
+	}
+	c := e.cfg.Strategy.EMARSI
+	s := &emarsiState{
+		rsi:     ind.NewRSI(c.RSIPeriod),
+		emaFast: ind.NewEMA(c.HTFEmaFast),
+		emaSlow: ind.NewEMA(c.HTFEmaSlow),
+		trend:   TrendNone,
+	}
+	e.st[sym] = s
+	return s
+}
+
+// OnCandle — контракт как у DonchianV2HTF: HTF обновляет тренд,
+// LTF — RSI и состояние отката.
+func (e *EMARSI) OnCandle(t models.CandleTick) (models.Signal, bool, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tf := helper.NormTF(t.TimeframeRaw)
+	c := e.cfg.Strategy.EMARSI
+	st := e.get(t.InstID)
+
+	becameReady := false
+
+	// защита от мусора
+	if t.Close <= 0 || t.High <= 0 || t.Low <= 0 {
+		return models.Signal{}, false, false
+	}
+
+	switch tf {
+
+	// ---------------- HTF: тренд ----------------
+	case helper.NormTF(e.cfg.Strategy.HTF):
+		st.emaFast.Update(t.Close)
+		st.emaSlow.Update(t.Close)
+		st.wHTF++
+
+		if st.wHTF >= c.MinWarmupHTF && st.emaFast.Ready() && st.emaSlow.Ready() {
+			if !st.readyHTF {
+				st.readyHTF = true
+				becameReady = true
+			}
+
+			f := st.emaFast.Value()
+			s := st.emaSlow.Value()
+			trend := TrendNone
+			switch {
+			case f > s:
+				trend = TrendUp
+			case f < s:
+				trend = TrendDown
+			}
+			// смена тренда сбрасывает взведённый откат
+			if trend != st.trend {
+				st.armed = models.SideNone
+			}
+			st.trend = trend
+		}
+
+		return models.Signal{}, false, becameReady
+
+	// ---------------- LTF: RSI pullback / re-cross ----------------
+	case helper.NormTF(e.cfg.Strategy.LTF):
+		st.prevRSI = st.rsi.Value()
+		wasReady := st.rsi.Ready()
+		st.rsi.Update(t.Close)
+		st.wLTF++
+
+		if st.wLTF >= c.MinWarmupLTF && st.rsi.Ready() && !st.readyLTF {
+			st.readyLTF = true
+			becameReady = true
+		}
+		if !wasReady || !st.readyLTF || !st.readyHTF || st.trend == TrendNone {
+			return models.Signal{}, false, becameReady
+		}
+
+		rsi := st.rsi.Value()
+
+		// 1) откат: взводим или продлеваем, копим экстремум
+		if st.armed != models.SideNone {
+			st.armedBars++
+			st.swingLow = math.Min(st.swingLow, t.Low)
+			st.swingHigh = math.Max(st.swingHigh, t.High)
+		}
+		// пока RSI за уровнем — откат продолжается, окно ожидания считаем заново
+		switch {
+		case st.trend == TrendUp && rsi < c.PullbackLong:
+			if st.armed != models.SideBuy {
+				st.armed = models.SideBuy
+				st.swingLow, st.swingHigh = t.Low, t.High
+			}
+			st.armedBars = 0
+		case st.trend == TrendDown && rsi > c.PullbackShort:
+			if st.armed != models.SideSell {
+				st.armed = models.SideSell
+				st.swingLow, st.swingHigh = t.Low, t.High
+			}
+			st.armedBars = 0
+		}
+		if c.MaxPullback > 0 && st.armedBars > c.MaxPullback {
+			st.armed = models.SideNone
+			return models.Signal{}, false, becameReady
+		}
+
+		// 2) возврат RSI через уровень свечой в сторону тренда
+		bodyPct := math.Abs(t.Close-t.Open) / t.Close
+		if bodyPct < c.MinBodyPct || !st.lastSignalEnd.Before(t.End) {
+			return models.Signal{}, false, becameReady
+		}
+
+		var side models.Side
+		var level float64
+		switch {
+		case st.armed == models.SideBuy && st.prevRSI < c.PullbackLong && rsi >= c.PullbackLong && t.Close > t.Open:
+			side, level = models.SideBuy, c.PullbackLong
+		case st.armed == models.SideSell && st.prevRSI > c.PullbackShort && rsi <= c.PullbackShort && t.Close < t.Open:
+			side, level = models.SideSell, c.PullbackShort
+		default:
+			return models.Signal{}, false, becameReady
+		}
+
+		st.armed = models.SideNone
+		st.lastSignalEnd = t.End
+
+		sig := models.Signal{
+			InstID:   t.InstID,
+			TF:       helper.NormTF(e.cfg.Strategy.LTF),
+			Side:     side,
+			Price:    t.Close,
+			Strategy: models.StrategyEMARSI,
+			Reason: fmt.Sprintf(
+				"trend=%v RSI[%d] %.2f->%.2f level=%.0f bars=%d bodyPct=%.4f swing=%.6f..%.6f",
+				st.trend, c.RSIPeriod, st.prevRSI, rsi, level, st.armedBars, bodyPct, st.swingLow, st.swingHigh,
+			),
+			CreatedAt: time.Now(),
+			// экстремумы отката — для StopMode=channel
+			ChHigh: st.swingHigh,
+			ChLow:  st.swingLow,
+		}
+
+		log.Printf("[SIG] %s %s close=%.6f rsi=%.2f->%.2f trend=%v swing=%.6f..%.6f",
+			t.InstID, side, t.Close, st.prevRSI, rsi, st.trend, st.swingLow, st.swingHigh)
+
+		return sig, true, becameReady
+
+	default:
+		return models.Signal{}, false, false
+	}
+}
+
+func (e *EMARSI) IsReady(symbol string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	st, ok := e.st[symbol]
+	if !ok {
+		return false
+	}
+	return st.readyLTF && st.readyHTF && st.trend != TrendNone
+}
+
+func (e *EMARSI) Name() string { return "ema_rsi_pullback" }
+
+func (e *EMARSI) Type() models.StrategyType { return models.StrategyEMARSI }
+
+func (e *EMARSI) WarmupBars(tf string) int {
+	c := e.cfg.Strategy.EMARSI
+	switch helper.NormTF(tf) {
+	case helper.NormTF(e.cfg.Strategy.HTF):
+		return max(c.HTFEmaFast, c.HTFEmaSlow, c.MinWarmupHTF)
+	case helper.NormTF(e.cfg.Strategy.LTF):
+		return max(c.RSIPeriod+1, c.MinWarmupLTF) // RSI готов после period изменений цены
+	}
+	return 0
+}
+
+func (e *EMARSI) Dump(symbol string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	st, ok := e.st[symbol]
+	if !ok {
+		return "emarsi: no state"
+	}
+
+	return fmt.Sprintf(
+		"emarsi[ltf] w=%d/%d ready=%v rsi=%.2f armed=%q bars=%d | [htf] w=%d fast=%.6f slow=%.6f trend=%v ready=%v",
+		st.wLTF, e.cfg.Strategy.EMARSI.MinWarmupLTF, st.readyLTF, st.rsi.Value(), st.armed, st.armedBars,
+		st.wHTF, st.emaFast.Value(), st.emaSlow.Value(), st.trend, st.readyHTF,
+	)
+}
+
+// emarsiSnap — состояние символа в снапшоте Hub.
+type emarsiSnap struct {
+	RSI      ind.RSIState `json:"rsi"`
+	PrevRSI  float64      `json:"prev_rsi"`
+	WLTF     int          `json:"w_ltf"`
+	ReadyLTF bool         `json:"ready_ltf"`
+
+	Armed     models.Side `json:"armed"`
+	ArmedBars int         `json:"armed_bars"`
+	SwingHigh float64     `json:"swing_high"`
+	SwingLow  float64     `json:"swing_low"`
+
+	EmaFast  ind.EMAState `json:"ema_fast"`
+	EmaSlow  ind.EMAState `json:"ema_slow"`
+	WHTF     int          `json:"w_htf"`
+	ReadyHTF bool         `json:"ready_htf"`
+	Trend    Trend        `json:"trend"`
+
+	LastSignalEnd time.Time `json:"last_signal_end"`
+}
+
+func (e *EMARSI) Snapshot() (json.RawMessage, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	out := make(map[string]emarsiSnap, len(e.st))
+	for sym, st := range e.st {
+		out[sym] = emarsiSnap{
+			RSI:           st.rsi.State(),
+			PrevRSI:       st.prevRSI,
+			WLTF:          st.wLTF,
+			ReadyLTF:      st.readyLTF,
+			Armed:         st.armed,
+			ArmedBars:     st.armedBars,
+			SwingHigh:     st.swingHigh,
+			SwingLow:      st.swingLow,
+			EmaFast:       st.emaFast.State(),
+			EmaSlow:       st.emaSlow.State(),
+			WHTF:          st.wHTF,
+			ReadyHTF:      st.readyHTF,
+			Trend:         st.trend,
+			LastSignalEnd: st.lastSignalEnd,
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (e *EMARSI) Restore(raw json.RawMessage) error {
+	var in map[string]emarsiSnap
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return fmt.Errorf("emarsi restore: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for sym, s := range in {
+		delete(e.st, sym)
+		st := e.get(sym)
+		st.rsi.Restore(s.RSI)
+		st.prevRSI = s.PrevRSI
+		st.wLTF = s.WLTF
+		st.readyLTF = s.ReadyLTF
+		st.armed = s.Armed
+		st.armedBars = s.ArmedBars
+		st.swingHigh = s.SwingHigh
+		st.swingLow = s.SwingLow
+		st.emaFast.Restore(s.EmaFast)
+		st.emaSlow.Restore(s.EmaSlow)
+		st.wHTF = s.WHTF
+		st.readyHTF = s.ReadyHTF
+		st.trend = s.Trend
+		st.lastSignalEnd = s.LastSignalEnd
+	}
+	return nil
+}
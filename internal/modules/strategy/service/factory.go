@@ -1,9 +1,80 @@
 package service
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"trade_bot/internal/models"
 	"trade_bot/internal/modules/config"
 )
 
-func NewEngine(cfg *config.Config) Engine {
-	return NewDonchianV2HTF(cfg)
+// Engines — движки, которые Hub гоняет на одном потоке свечей.
+type Engines []Engine
+
+// Names — "a,b" для логов и сервисных сообщений.
+func (es Engines) Names() string {
+	names := make([]string, len(es))
+	for i, e := range es {
+		names[i] = e.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// WarmupBars — сколько свечей tf нужно, чтобы прогрелись все движки.
+func (es Engines) WarmupBars(tf string) int {
+	n := 0
+	for _, e := range es {
+		n = max(n, e.WarmupBars(tf))
+	}
+	return n
+}
+
+// registry — все реализованные стратегии по StrategyType.
+var registry = map[models.StrategyType]func(cfg *config.Config) Engine{
+	models.StrategyDonchianV2: func(cfg *config.Config) Engine { return NewDonchianV2HTF(cfg) },
+	models.StrategyEMARSI:     func(cfg *config.Config) Engine { return NewEMARSI(cfg) },
+}
+
+// Registered — имена всех реализованных стратегий, по алфавиту.
+func Registered() []models.StrategyType {
+	out := make([]models.StrategyType, 0, len(registry))
+	for t := range registry {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// New — движок стратегии по имени из реестра.
+func New(t models.StrategyType, cfg *config.Config) (Engine, error) {
+	mk, ok := registry[t]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q (есть: %v)", t, Registered())
+	}
+	return mk(cfg), nil
+}
+
+// NewEngines — движки из cfg.Strategy.Engines; пусто — только models.DefaultStrategy.
+func NewEngines(cfg *config.Config) (Engines, error) {
+	names := cfg.Strategy.Engines
+	if len(names) == 0 {
+		names = []string{string(models.DefaultStrategy)}
+	}
+
+	seen := make(map[models.StrategyType]bool, len(names))
+	out := make(Engines, 0, len(names))
+	for _, n := range names {
+		t := models.StrategyType(strings.TrimSpace(n))
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+
+		e, err := New(t, cfg)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, nil
 }
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"testing"
+	"trade_bot/internal/modules/config"
+)
+
+// Прогрев берётся по самому «долгому» движку, а не только по Donchian.
+func TestEnginesWarmupBarsTakesMax(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Strategy.LTF, cfg.Strategy.HTF = "15m", "1h"
+	cfg.Strategy.DonchianPeriod, cfg.Strategy.MinWarmupLTF = 20, 20
+	cfg.Strategy.HTFEmaSlow, cfg.Strategy.MinWarmupHTF = 50, 50
+	cfg.Strategy.EMARSI.RSIPeriod, cfg.Strategy.EMARSI.MinWarmupLTF = 40, 30
+	cfg.Strategy.EMARSI.HTFEmaSlow, cfg.Strategy.EMARSI.MinWarmupHTF = 200, 200
+
+	es := Engines{NewDonchianV2HTF(cfg), NewEMARSI(cfg)}
+	if got := es.WarmupBars("15m"); got != 41 {
+		t.Fatalf("LTF = %d, want 41 (RSI 40 + 1)", got)
+	}
+	if got := es.WarmupBars("1H"); got != 200 {
+		t.Fatalf("HTF = %d, want 200 (EMARSI slow EMA)", got)
+	}
+	if got := es.WarmupBars("4h"); got != 0 {
+		t.Fatalf("unused TF = %d, want 0", got)
+	}
+}
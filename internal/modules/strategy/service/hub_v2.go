@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"sync"
 	"time"
 	"trade_bot/internal/helper"
@@ -22,9 +21,20 @@ type Hub struct {
 	out       chan<- models.Signal
 	candleOut chan<- models.CandleTick
 
-	engine Engine
+	engines Engines
+	corr    *Correlation
+	atr     *ATR
+
+	// snapMu: OnTick держит RLock, пока кормит движки, снапшот — Lock
+	snapMu sync.RWMutex
+
+	mu sync.Mutex
+	// End последней LTF/HTF свечи по "instId|tf": живой поток и из снапшота
+	lastEnd     map[string]time.Time
+	restoredEnd map[string]time.Time
+	// символы в докачке REST: живые LTF/HTF тики ждут её конца (см. BeginBackfill)
+	held map[string][]okxws.OutTick
 
-	mu            sync.Mutex
 	readyCnt      int
 	ready         map[string]bool
 	warmupDone    bool
@@ -38,18 +48,38 @@ type Hub struct {
 	warmupStalled bool
 }
 
-func NewHub(cfg *config.Config, n ServiceNotifier, out chan<- models.Signal, engine Engine) *Hub {
+func NewHub(cfg *config.Config, n ServiceNotifier, out chan<- models.Signal, candleOut chan<- models.CandleTick, engines Engines, corr *Correlation, atr *ATR) *Hub {
 	return &Hub{
 		cfg:       cfg,
 		n:         n,
 		out:       out,
-		engine:    engine,
+		candleOut: candleOut,
+		engines:   engines,
+		corr:      corr,
+		atr:       atr,
 		ready:     make(map[string]bool),
 		startedAt: time.Now(),
+
+		lastEnd:     make(map[string]time.Time),
+		restoredEnd: make(map[string]time.Time),
+		held:        make(map[string][]okxws.OutTick),
 	}
 }
 
+// WarmupBars — история tf, нужная для прогрева всех движков хаба.
+func (h *Hub) WarmupBars(tf string) int { return h.engines.WarmupBars(tf) }
+
+// OnTick — закрытая свеча живого потока. Пока по символу идёт докачка,
+// LTF/HTF свечи откладываются, чтобы движки получили их после истории.
 func (h *Hub) OnTick(ctx context.Context, t okxws.OutTick) {
+	if h.hold(t) {
+		return
+	}
+	h.feed(ctx, t)
+}
+
+// feed — свеча в движки, корреляцию и ATR; сигналы наружу.
+func (h *Hub) feed(ctx context.Context, t okxws.OutTick) {
 	// приводим WS tick к models.CandleTick
 	ct := models.CandleTick{
 		InstID:       t.InstID,
@@ -64,7 +94,30 @@ func (h *Hub) OnTick(ctx context.Context, t okxws.OutTick) {
 		TimeframeRaw: t.Timeframe,
 	}
 
-	sig, ok, becameReady := h.engine.OnCandle(ct)
+	// все движки на одной свече; символ считается прогретым по первому из них
+	var (
+		sigs        []models.Signal
+		becameReady bool
+	)
+	h.snapMu.RLock()
+	if !h.admit(ct) {
+		h.snapMu.RUnlock()
+		return // уже в восстановленном состоянии
+	}
+	for _, e := range h.engines {
+		sig, ok, ready := e.OnCandle(ct)
+		if ok {
+			sig.Strategy = e.Type()
+			sigs = append(sigs, sig)
+		}
+		becameReady = becameReady || ready
+	}
+	h.corr.OnCandle(ct)
+	h.atr.OnCandle(ct)
+	h.snapMu.RUnlock()
+	for i := range sigs {
+		sigs[i].ATR = h.atr.Value(sigs[i].InstID, sigs[i].TF)
+	}
 
 	if becameReady {
 		h.onBecameReady(ctx, ct.InstID)
@@ -78,34 +131,27 @@ func (h *Hub) OnTick(ctx context.Context, t okxws.OutTick) {
 		h.maybeWarmupProgress(ctx)
 	}
 
-	// прогресс прогрева
-	if becameReady {
-		h.onBecameReady(ctx, ct.InstID)
-	} else {
-		h.maybeWarmupProgress(ctx)
-	}
-
 	if helper.NormTF(ct.TimeframeRaw) == "1m" {
 		select {
 		case h.candleOut <- ct:
-			fmt.Printf("[CANDLE OUT] %s 1m close=%.6f end=%s\n", ct.InstID, ct.Close, ct.End.Format(time.RFC3339))
-
 		default:
 		}
 	}
 
 	// блокируем сигналы пока прогрев не окончен
-	if !ok || !h.isWarmupDone() {
+	if len(sigs) == 0 || !h.isWarmupDone() {
 		return
 	}
 
-	// отдаём сигнал наружу (лучше не блокировать Hub)
-	select {
-	case h.out <- sig:
-	default:
-		if h.n != nil {
-			h.n.SendService(ctx, "⚠️ signal channel full, drop %s %s @ %.6f (%s)",
-				sig.InstID, sig.Side, sig.Price, sig.TF)
+	// отдаём сигналы наружу (лучше не блокировать Hub)
+	for _, sig := range sigs {
+		select {
+		case h.out <- sig:
+		default:
+			if h.n != nil {
+				h.n.SendService(ctx, "⚠️ signal channel full, drop %s %s %s @ %.6f (%s)",
+					sig.Strategy, sig.InstID, sig.Side, sig.Price, sig.TF)
+			}
 		}
 	}
 }
@@ -135,7 +181,7 @@ func (h *Hub) onBecameReady(ctx context.Context, sym string) {
 		if h.n != nil {
 			h.n.SendService(ctx,
 				"🔥 Warmup started | engine=%s | LTF=%s HTF=%s | ожидаем=%d",
-				h.engine.Name(), h.cfg.Strategy.LTF, h.cfg.Strategy.HTF, expected,
+				h.engines.Names(), h.cfg.Strategy.LTF, h.cfg.Strategy.HTF, expected,
 			)
 		}
 		// не return — пусть может сразу завершиться, если expected маленький
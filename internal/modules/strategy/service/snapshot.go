@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+)
+
+// snapshotVersion — меняем при несовместимом изменении формата.
+const snapshotVersion = 1
+
+// Snapshotter — состояние по символам, которое переживает рестарт.
+// Движок без него после рестарта прогревается заново.
+type Snapshotter interface {
+	Snapshot() (json.RawMessage, error)
+	Restore(raw json.RawMessage) error
+}
+
+type hubSnapshot struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"saved_at"`
+	// отпечаток параметров стратегии: другой конфиг — другие окна и периоды
+	ConfigHash string `json:"config_hash"`
+
+	// последняя закрытая свеча, вошедшая в состояние: "instId|tf" -> End
+	LastEnd map[string]time.Time `json:"last_end"`
+
+	Engines map[string]json.RawMessage `json:"engines"` // StrategyType -> состояние
+	ATR     json.RawMessage            `json:"atr,omitempty"`
+	Corr    json.RawMessage            `json:"corr,omitempty"`
+}
+
+func endKey(instID, tf string) string { return instID + "|" + helper.NormTF(tf) }
+
+// configHash — параметры, от которых зависит состояние (без списка движков —
+// его покрывает проверка состояний в LoadState, — вотчлиста и настроек самого снапшота).
+func (h *Hub) configHash() string {
+	sc := h.cfg.Strategy
+	sc.Engines = nil
+	sc.ExpectedSymbols = 0
+	sc.ProgressEvery = 0
+	sc.WatchTopN = 0
+	sc.State = config.StrategyStateConfig{}
+
+	b, _ := json.Marshal(sc)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SaveState пишет снапшот в cfg.Strategy.State.Path (атомарно, через .tmp).
+// Тики на время снятия состояния ждут — срез согласован с LastEnd.
+func (h *Hub) SaveState() error {
+	path := h.cfg.Strategy.State.Path
+	if path == "" {
+		return nil
+	}
+
+	snap := hubSnapshot{
+		Version:    snapshotVersion,
+		SavedAt:    time.Now(),
+		ConfigHash: h.configHash(),
+		Engines:    make(map[string]json.RawMessage, len(h.engines)),
+	}
+
+	h.snapMu.Lock()
+	err := func() error {
+		for _, e := range h.engines {
+			s, ok := e.(Snapshotter)
+			if !ok {
+				continue
+			}
+			raw, err := s.Snapshot()
+			if err != nil {
+				return fmt.Errorf("%s: %w", e.Type(), err)
+			}
+			snap.Engines[string(e.Type())] = raw
+		}
+		var err error
+		if snap.ATR, err = h.atr.Snapshot(); err != nil {
+			return err
+		}
+		if snap.Corr, err = h.corr.Snapshot(); err != nil {
+			return err
+		}
+
+		h.mu.Lock()
+		snap.LastEnd = make(map[string]time.Time, len(h.lastEnd))
+		for k, v := range h.lastEnd {
+			snap.LastEnd[k] = v
+		}
+		h.mu.Unlock()
+		return nil
+	}()
+	h.snapMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("strategy snapshot: %w", err)
+	}
+
+	b, err := json.Marshal(&snap)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path) // атомарно
+}
+
+// LoadState восстанавливает снапшот, если он есть, свежий и снят с тем же
+// конфигом стратегии. Возвращает число восстановленных символов; символы,
+// которые уже готовы, сразу засчитываются в прогрев.
+func (h *Hub) LoadState(ctx context.Context) (int, error) {
+	st := h.cfg.Strategy.State
+	if st.Path == "" {
+		return 0, nil
+	}
+
+	b, err := os.ReadFile(st.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read %s: %w", st.Path, err)
+	}
+
+	var snap hubSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return 0, fmt.Errorf("decode %s: %w", st.Path, err)
+	}
+	switch {
+	case snap.Version != snapshotVersion:
+		log.Printf("[STRAT] snapshot skipped: version %d != %d", snap.Version, snapshotVersion)
+		return 0, nil
+	case snap.ConfigHash != h.configHash():
+		log.Printf("[STRAT] snapshot skipped: strategy config changed")
+		return 0, nil
+	case st.MaxAge > 0 && time.Since(snap.SavedAt) > st.MaxAge:
+		log.Printf("[STRAT] snapshot skipped: saved %s ago (max %s)", time.Since(snap.SavedAt).Round(time.Second), st.MaxAge)
+		return 0, nil
+	}
+
+	// RestoredEnd сокращает прогрев до разрыва для всех движков сразу, поэтому
+	// снапшот берём, только если в нём есть состояние каждого движка
+	// (новый движок в списке или движок без Snapshotter — полный прогрев)
+	for _, e := range h.engines {
+		_, ok := e.(Snapshotter)
+		if _, have := snap.Engines[string(e.Type())]; !ok || !have {
+			log.Printf("[STRAT] snapshot skipped: no state for engine %s", e.Type())
+			return 0, nil
+		}
+	}
+
+	h.snapMu.Lock()
+	err = func() error {
+		for _, e := range h.engines {
+			if err := e.(Snapshotter).Restore(snap.Engines[string(e.Type())]); err != nil {
+				return err
+			}
+		}
+		if len(snap.ATR) > 0 {
+			if err := h.atr.Restore(snap.ATR); err != nil {
+				return err
+			}
+		}
+		if len(snap.Corr) > 0 {
+			if err := h.corr.Restore(snap.Corr); err != nil {
+				return err
+			}
+		}
+
+		h.mu.Lock()
+		for k, v := range snap.LastEnd {
+			h.lastEnd[k] = v
+			h.restoredEnd[k] = v
+		}
+		h.mu.Unlock()
+		return nil
+	}()
+	h.snapMu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("strategy restore: %w", err)
+	}
+
+	syms := make(map[string]bool)
+	for k := range snap.LastEnd {
+		if sym, _, ok := strings.Cut(k, "|"); ok {
+			syms[sym] = true
+		}
+	}
+	for sym := range syms {
+		for _, e := range h.engines {
+			if e.IsReady(sym) {
+				h.onBecameReady(ctx, sym)
+				break
+			}
+		}
+	}
+
+	log.Printf("[STRAT] snapshot restored: %d symbols, saved %s ago",
+		len(syms), time.Since(snap.SavedAt).Round(time.Second))
+	return len(syms), nil
+}
+
+// RestoredEnd — End последней свечи instId/tf из восстановленного снапшота
+// (zero — снапшота по символу нет, нужен полный прогрев).
+func (h *Hub) RestoredEnd(instID, tf string) time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.restoredEnd[endKey(instID, tf)]
+}
+
+// admit — свеча новее всего, что уже вошло в состояние (снапшот, докачка,
+// живой поток). Повторы и опоздавшие свечи движки не получают: индикаторы
+// считаются только по возрастанию времени.
+func (h *Hub) admit(ct models.CandleTick) bool {
+	if !h.strategyTF(ct.TimeframeRaw) {
+		return true
+	}
+	k := endKey(ct.InstID, helper.NormTF(ct.TimeframeRaw))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if last, ok := h.lastEnd[k]; ok && !ct.End.After(last) {
+		return false
+	}
+	h.lastEnd[k] = ct.End
+	return true
+}
+
+// strategyTF — ТФ, по которому считают движки (LTF или HTF).
+func (h *Hub) strategyTF(raw string) bool {
+	tf := helper.NormTF(raw)
+	return tf == helper.NormTF(h.cfg.Strategy.LTF) || tf == helper.NormTF(h.cfg.Strategy.HTF)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+)
+
+// snapEngine — recEngine с состоянием, которое переживает рестарт.
+type snapEngine struct {
+	recEngine
+	typ models.StrategyType
+}
+
+func (e *snapEngine) Type() models.StrategyType          { return e.typ }
+func (e *snapEngine) Snapshot() (json.RawMessage, error) { return json.RawMessage(`{}`), nil }
+func (e *snapEngine) Restore(json.RawMessage) error      { return nil }
+
+func newSnapHub(path string, engines ...Engine) *Hub {
+	cfg := &config.Config{}
+	cfg.Strategy.LTF, cfg.Strategy.HTF = "15m", "1h"
+	cfg.Strategy.State.Path = path
+	return NewHub(cfg, nil, make(chan models.Signal, 16), make(chan models.CandleTick, 16),
+		engines, NewCorrelation(cfg), NewATR(cfg))
+}
+
+// Движок без сохранённого состояния не должен получить прогрев только
+// за разрыв: снапшот целиком пропускается, RestoredEnd пуст.
+func TestLoadStateSkipsSnapshotWithoutEngineState(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+	end := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	saved := newSnapHub(path, &snapEngine{typ: models.DefaultStrategy})
+	saved.OnTick(ctx, tick15m(end))
+	if err := saved.SaveState(); err != nil {
+		t.Fatal(err)
+	}
+
+	same := newSnapHub(path, &snapEngine{typ: models.DefaultStrategy})
+	if _, err := same.LoadState(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := same.RestoredEnd("BTC-USDT-SWAP", "15m"); !got.Equal(end) {
+		t.Fatalf("same engines: RestoredEnd = %s, want %s", got, end)
+	}
+
+	added := newSnapHub(path, &snapEngine{typ: models.DefaultStrategy}, &snapEngine{typ: "other"})
+	if _, err := added.LoadState(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := added.RestoredEnd("BTC-USDT-SWAP", "15m"); !got.IsZero() {
+		t.Fatalf("engine without state: RestoredEnd = %s, want zero", got)
+	}
+
+	plain := newSnapHub(path, &snapEngine{typ: models.DefaultStrategy}, &recEngine{})
+	if n, _ := plain.LoadState(ctx); n != 0 || !plain.RestoredEnd("BTC-USDT-SWAP", "15m").IsZero() {
+		t.Fatal("engine without Snapshotter: snapshot restored")
+	}
+}
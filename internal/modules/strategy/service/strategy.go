@@ -10,4 +10,9 @@ type Engine interface {
 	IsReady(symbol string) bool
 	Dump(symbol string) string
 	Name() string
+	// Type — тег стратегии в сигнале; по нему юзеры подписываются на сигналы
+	Type() models.StrategyType
+	// WarmupBars — сколько закрытых свечей tf нужно символу до готовности
+	// (0 — этот ТФ движку не нужен)
+	WarmupBars(tf string) int
 }
@@ -2,6 +2,9 @@ package service
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 	"trade_bot/internal/models"
 )
 
@@ -27,14 +30,113 @@ func formatRiskSettings(ts *models.TradingSettings) string {
 	return fmt.Sprintf(
 		"*📉 Риск / SL / TP*\n\n"+
 			"Risk: `%s%%` на сделку\n"+
-			"Stop: `%s%%`\n"+
+			"Stop: `%s`\n"+
 			"TP: `%sR`\n",
 		f2(ts.RiskPct),
-		f2(ts.StopPct),
+		stopStr(ts),
 		f2(ts.TakeProfitRR),
 	)
 }
 
+// stopStr — стоп по режиму: процент, k·ATR или граница канала.
+func stopStr(ts *models.TradingSettings) string {
+	switch ts.StopMode {
+	case models.StopModeATR:
+		k := ts.StopATRMult
+		if k <= 0 {
+			k = 2
+		}
+		return f2(k) + " × ATR"
+	case models.StopModeChannel:
+		return "граница канала"
+	default:
+		return f2(ts.StopPct) + "%"
+	}
+}
+
+// entryStr — режим входа: market или лимит со сдвигом и ожиданием.
+func entryStr(ts *models.TradingSettings) string {
+	timeout := ts.EntryTimeout
+	if timeout <= 0 {
+		timeout = 20 * time.Second
+	}
+	lim := fmt.Sprintf("%s%%, %s", f2(ts.EntryOffsetPct), timeout)
+	switch ts.EntryMode {
+	case models.EntryLimit:
+		return "limit " + lim
+	case models.EntryPostOnly:
+		return "post-only " + lim
+	case models.EntryLimitMarket:
+		return "limit → market " + lim
+	default:
+		return "market"
+	}
+}
+
+// ladderStr — "1R:30% 2R:30%", пусто — "выкл".
+func ladderStr(ladder []models.TPLevel) string {
+	if len(ladder) == 0 {
+		return "выкл"
+	}
+	parts := make([]string, 0, len(ladder))
+	for _, l := range ladder {
+		parts = append(parts, fmt.Sprintf("%sR:%.0f%%", f2(l.R), l.Frac*100))
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseLadder — "1:30, 2:30" (R:процент объёма); "0" — выключить.
+func parseLadder(text string) ([]models.TPLevel, error) {
+	if text == "0" || text == "-" {
+		return nil, nil
+	}
+	var (
+		out []models.TPLevel
+		sum float64
+	)
+	for _, item := range strings.FieldsFunc(text, func(r rune) bool { return r == ' ' || r == ';' || r == '\n' }) {
+		item = strings.TrimRight(item, ",.") // запятые к этому моменту уже точки
+		if item == "" {
+			continue
+		}
+		rs, ps, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("ступень %q: нужно R:процент", item)
+		}
+		r, err := strconv.ParseFloat(strings.TrimSuffix(rs, "R"), 64)
+		if err != nil || r <= 0 || r > 20 {
+			return nil, fmt.Errorf("ступень %q: R должно быть 0..20", item)
+		}
+		p, err := strconv.ParseFloat(strings.TrimSuffix(ps, "%"), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("ступень %q: процент 1..100", item)
+		}
+		sum += p
+		out = append(out, models.TPLevel{R: r, Frac: p / 100})
+	}
+	if len(out) == 0 || len(out) > models.MaxTPLevels {
+		return nil, fmt.Errorf("нужно 1..%d ступеней", models.MaxTPLevels)
+	}
+	if sum > 100 {
+		return nil, fmt.Errorf("сумма долей %.0f%% > 100%%", sum)
+	}
+	return out, nil
+}
+
+// trailStr — стадия Trail после Lock по режиму.
+func trailStr(tr *models.TrailingConfig) string {
+	switch tr.TrailMode {
+	case models.TrailModeATR:
+		return "MFE − " + f2(tr.TrailATRMult) + " × ATR"
+	case models.TrailModeR:
+		return "MFE − " + f2(tr.TrailDistR) + "R"
+	case models.TrailModeBars:
+		return fmt.Sprintf("экстремум %d баров", tr.TrailBars)
+	default:
+		return "выкл"
+	}
+}
+
 func formatTrailing(cfg *models.TrailingConfig) string {
 	return fmt.Sprintf(
 		"*🧲 Trailing / Partial*\n\n"+
@@ -29,6 +29,27 @@ func (t *Telegram) handleCallback(ctx context.Context, chatID int64, cb *tgbotap
 	case "toggle:partial":
 		t.togglePartial(ctx, chatID)
 		return
+	case "toggle:demo":
+		t.toggleDemo(ctx, chatID)
+		return
+	case "toggle:paper":
+		t.togglePaper(ctx, chatID)
+		return
+	case "toggle:stop_mode":
+		t.toggleStopMode(ctx, chatID)
+		return
+	case "toggle:entry_mode":
+		t.toggleEntryMode(ctx, chatID)
+		return
+	case "toggle:trail_mode":
+		t.toggleTrailMode(ctx, chatID)
+		return
+	case "toggle:flatten":
+		t.toggleFlatten(ctx, chatID)
+		return
+	case "risk:reset":
+		t.handleRiskReset(ctx, chatID)
+		return
 	case "toggle:feat:near_tp":
 		t.toggleFeature(ctx, chatID, "near_tp")
 		return
@@ -89,6 +110,10 @@ func (t *Telegram) handleCallback(ctx context.Context, chatID int64, cb *tgbotap
 		return
 	}
 
+	if strings.HasPrefix(data, "strat:") {
+		t.toggleStrategy(ctx, chatID, strings.TrimPrefix(data, "strat:"))
+		return
+	}
 	if strings.HasPrefix(data, "set:") {
 		key := strings.TrimPrefix(data, "set:")
 		t.askValue(ctx, chatID, key)
@@ -104,6 +129,12 @@ func (t *Telegram) handleCallback(ctx context.Context, chatID int64, cb *tgbotap
 	case "menu:features":
 		t.handleFeaturesMenu(ctx, chatID)
 		return
+	case "menu:risk":
+		t.handleRiskMenu(ctx, chatID)
+		return
+	case "menu:strategies":
+		t.handleStrategiesMenu(ctx, chatID)
+		return
 	}
 
 }
@@ -121,23 +152,33 @@ func (t *Telegram) handleSettingsMenu(ctx context.Context, chatID int64) {
 	b.WriteString("⚙️ *Настройки торговли*\n\n")
 
 	fmt.Fprintf(&b,
-		"💰 *Размер позиции*: `%.2f%%`\n— Сколько депозита используется в сделке\n\n"+
+		"🧠 *Стратегии*: `%s`\n\n"+
+			"💰 *Размер позиции*: `%.2f%%`\n— Сколько депозита используется в сделке\n\n"+
 			"⚠️ *Риск*: `%.2f%%`\n— Потеря при срабатывании стопа\n\n"+
-			"📉 *Стоп*: `%.2f%%`\n— Допустимое движение против тебя\n\n"+
-			"🎯 *Тейк*: `%.2fR`\n— Прибыль относительно риска\n\n"+
+			"📉 *Стоп*: `%s`\n— Допустимое движение против тебя\n\n"+
+			"🎯 *Тейк*: `%.2fR`\n— Прибыль относительно риска\n"+
+			"🪜 *Лестница TP*: `%s`\n\n"+
+			"🚪 *Вход*: `%s`\n\n"+
 			"📊 *Плечо*: `x%d`\n"+
 			"🔢 *Макс. позиций*: `%d`\n\n"+
 			"🔔 *Подтверждение входа*: *%s*\n"+
-			"↘️ *Частичная фиксация*: *%s* (%.0f%%)\n",
+			"↘️ *Частичная фиксация*: *%s* (%.0f%%)\n"+
+			"🎮 *Demo OKX*: *%s*\n"+
+			"🧪 *Paper trading*: *%s*\n",
+		strategiesStr(&ts),
 		ts.PositionPct,
 		ts.RiskPct,
-		ts.StopPct,
+		stopStr(&ts),
 		ts.TakeProfitRR,
+		ladderStr(ts.TPLadder),
+		entryStr(&ts),
 		ts.Leverage,
 		ts.MaxOpenPositions,
 		onOff(ts.ConfirmRequired),
 		onOff(tr.PartialEnabled),
 		tr.PartialCloseFrac*100,
+		onOff(ts.OKXDemo),
+		onOff(ts.Paper),
 	)
 
 	kb := tgbotapi.NewInlineKeyboardMarkup(
@@ -154,6 +195,18 @@ func (t *Telegram) handleSettingsMenu(ctx context.Context, chatID int64) {
 			btn("📉 Стоп %", "set:stop"),
 			btn("🎯 Тейк R", "set:tp_rr"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("🧭 Режим стопа", "toggle:stop_mode"),
+			btn("📏 k × ATR", "set:stop_atr_k"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("🪜 Лестница TP", "set:tp_ladder"),
+			btn("🚪 Режим входа", "toggle:entry_mode"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("↔️ Сдвиг лимита %", "set:entry_offset"),
+			btn("⏳ Ожидание лимита", "set:entry_timeout"),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			btn("📊 Плечо", "set:lev"),
 			btn("🔢 Макс позиций", "set:maxpos"),
@@ -164,6 +217,14 @@ func (t *Telegram) handleSettingsMenu(ctx context.Context, chatID int64) {
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			btn("✨ Фичи", "menu:features"),
+			btn("🎮 Demo OKX", "toggle:demo"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("🧪 Paper trading", "toggle:paper"),
+			btn("🧯 Риск-лимиты", "menu:risk"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("🧠 Стратегии", "menu:strategies"),
 		),
 	)
 
@@ -206,6 +267,9 @@ func (t *Telegram) handleTrailingMenu(ctx context.Context, chatID int64) {
 			"• Закрыть: `%.0f%%` позиции\n"+
 			"— Часть позиции фиксируется,\n"+
 			"  остальное остаётся на дальнейший рост\n\n"+
+			"🪜 *Трейлинг после Lock*: `%s`\n"+
+			"— Каждые 15m стоп подтягивается за ценой,\n"+
+			"  пока позиция не закроется\n\n"+
 			"💡 R — это отношение прибыли к риску (1R = риск по стоп-лоссу)",
 		tr.BETriggerR, tr.BEOffsetR,
 		tr.LockTriggerR, tr.LockOffsetR,
@@ -213,6 +277,7 @@ func (t *Telegram) handleTrailingMenu(ctx context.Context, chatID int64) {
 		onOff(tr.PartialEnabled),
 		tr.PartialTriggerR,
 		tr.PartialCloseFrac*100,
+		trailStr(&tr),
 	)
 
 	kb := tgbotapi.NewInlineKeyboardMarkup(
@@ -237,6 +302,14 @@ func (t *Telegram) handleTrailingMenu(ctx context.Context, chatID int64) {
 			btn("↘️ Partial ON/OFF", "toggle:partial"),
 			btn("↘️ Trigger", "set:partial_trigger_r"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("🪜 Trail режим", "toggle:trail_mode"),
+			btn("🪜 k × ATR", "set:trail_atr_mult"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("🪜 d × R", "set:trail_dist_r"),
+			btn("🪜 N баров", "set:trail_bars"),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			btn("↘️ Close %", "set:partial_close_frac"),
 			btn("⬅️ Назад", "menu:settings"),
@@ -26,6 +26,10 @@ func (t *Telegram) handleUpdate(ctx context.Context, update tgbotapi.Update) {
 				}
 			case "positions":
 				go t.handlePositions(ctx) // если нужно, можешь прокинуть chatID
+			case "pnl":
+				go t.handlePnL(ctx, chatID)
+			case "risk_reset":
+				t.handleRiskReset(ctx, chatID)
 			default:
 				// /help, /status и т.п. — по желанию
 			}
@@ -67,13 +71,17 @@ func (t *Telegram) handleStart(ctx context.Context, chatID int64) error {
 			tgbotapi.NewKeyboardButton("⚙️ Настройки"),
 			tgbotapi.NewKeyboardButton("📊 Статус"),
 		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("💰 PnL"),
+		),
 	)
 
 	msgText := "Привет! Я торговый бот для OKX.\n\n" +
 		"1️⃣ Сначала укажи свои API-ключи OKX.\n" +
 		"2️⃣ Затем можешь запустить бота кнопкой «▶️ Запустить бота».\n\n" +
 		"Отправь свои API-ключи в формате:\n" +
-		"`OKX: apiKey; apiSecret; passphrase`"
+		"`OKX: apiKey; apiSecret; passphrase`\n\n" +
+		"🧪 Без ключей можно торговать виртуальным счётом: ⚙️ Настройки → Paper trading."
 
 	msg := tgbotapi.NewMessage(chatID, msgText)
 	msg.ParseMode = "Markdown"
@@ -117,13 +125,24 @@ func (t *Telegram) handleTextMessage(ctx context.Context, msg *tgbotapi.Message)
 	case "▶️ Запустить бота":
 		go func() {
 			runCtx := context.Background()
-			t.router.EnableUser(user, t)
+			started := t.router.EnableUser(user, t)
+			// запоминаем, чтобы после рестарта сервиса бот поднялся сам
+			if err := t.repo.SetEnabled(runCtx, chatID, true); err != nil {
+				log.Printf("[TG] set enabled chat=%d: %v", chatID, err)
+			}
+			if !started {
+				_, _ = t.Send(runCtx, chatID, "ℹ️ Бот уже запущен для этого аккаунта.")
+				return
+			}
 			_, _ = t.Send(runCtx, chatID, "✅ Бот запущен для этого аккаунта.")
 		}()
 		return
 
 	case "⏹ Остановить бота":
 		t.router.DisableUser(chatID)
+		if err := t.repo.SetEnabled(ctx, chatID, false); err != nil {
+			log.Printf("[TG] set disabled chat=%d: %v", chatID, err)
+		}
 		_, _ = t.Send(ctx, chatID, "🛑 Бот остановлен для этого аккаунта.")
 		return
 
@@ -134,6 +153,9 @@ func (t *Telegram) handleTextMessage(ctx context.Context, msg *tgbotapi.Message)
 	case "📊 Статус":
 		go t.handleStatus(ctx, user)
 		return
+	case "💰 PnL":
+		go t.handlePnL(ctx, chatID)
+		return
 	case "🧪 Тестовая сделка (BTC x1)":
 		t.handleTestTradeMenu(ctx, chatID, user) // или без user, как удобнее
 		return
@@ -268,13 +290,17 @@ func (t *Telegram) handleStatus(ctx context.Context, user *models.UserSettings)
 				"  Размер: `%.4f`\n"+
 				"  Вход:   `%.4f`\n"+
 				"  Сейчас: `%.4f`\n"+
-				"  PnL:    `%.2f USDT (%.2f%%)`\n\n",
+				"  PnL:    `%.2f USDT (%.2f%%)`\n",
 			symbol, side,
 			qty,
 			entry,
 			last,
 			upnl, upnlPct,
 		)
+		if p.Realised != 0 {
+			fmt.Fprintf(&b, "  Реализовано: `%.2f USDT`\n", p.Realised)
+		}
+		b.WriteString("\n")
 	}
 
 	fmt.Fprintf(&b, "*Суммарный PnL:* `%.2f USDT`\n", totalPnl)
@@ -284,6 +310,43 @@ func (t *Telegram) handleStatus(ctx context.Context, user *models.UserSettings)
 	_, _ = t.SendMessage(ctx, msg)
 }
 
+// handlePnL — итог закрытых сделок из журнала: день / неделя / месяц (UTC).
+func (t *Telegram) handlePnL(ctx context.Context, chatID int64) {
+	periods, err := t.router.PnLForUser(ctx, chatID)
+	if err != nil {
+		log.Printf("PnLForUser error: %v", err)
+		_, _ = t.Send(ctx, chatID, "⚠️ Не удалось посчитать PnL: "+err.Error())
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("💰 *Реализованный PnL* (UTC)\n\n")
+
+	var unsettled int
+	for _, p := range periods {
+		if p.Trades == 0 {
+			fmt.Fprintf(&b, "*%s:* сделок нет\n\n", p.Name)
+			continue
+		}
+		fmt.Fprintf(&b,
+			"*%s:* `%+.2f USDT` (`%+.2fR`)\n"+
+				"  Сделок: `%d`, в плюс: `%d` (%.0f%%)\n"+
+				"  Комиссии: `%.2f` | Funding: `%.2f`\n\n",
+			p.Name, p.PnlUSDT, p.PnlR,
+			p.Trades, p.Wins, float64(p.Wins)/float64(p.Trades)*100,
+			p.FeeUSDT, p.FundingUSDT,
+		)
+		unsettled = max(unsettled, p.Unsettled)
+	}
+	if unsettled > 0 {
+		fmt.Fprintf(&b, "⏳ Ещё не сверено с биржей: `%d` — PnL по ним оценочный\n", unsettled)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "Markdown"
+	_, _ = t.SendMessage(ctx, msg)
+}
+
 func (t *Telegram) handleToggleConfirm(ctx context.Context, chatID int64, msg *tgbotapi.Message) {
 	user, err := t.getUser(ctx, chatID)
 	if err != nil {
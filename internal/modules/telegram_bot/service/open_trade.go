@@ -14,11 +14,11 @@ func (t *Telegram) openTestTradeBTC1x(ctx context.Context, chatID int64) {
 		return
 	}
 
-	// торговые креды именно пользователя
+	// торговые креды именно пользователя (paper-счёту не нужны)
 	ts := user.Settings.TradingSettings
-	if strings.TrimSpace(ts.OKXAPIKey) == "" ||
+	if !ts.Paper && (strings.TrimSpace(ts.OKXAPIKey) == "" ||
 		strings.TrimSpace(ts.OKXAPISecret) == "" ||
-		strings.TrimSpace(ts.OKXPassphrase) == "" {
+		strings.TrimSpace(ts.OKXPassphrase) == "") {
 		_, _ = t.Send(ctx, chatID, "🔑 Для тестовой сделки нужны OKX ключ/секрет/пасфраза. Добавь их и повтори.")
 		return
 	}
@@ -89,7 +89,7 @@ func (t *Telegram) openTestTradeBTC1x(ctx context.Context, chatID int64) {
 		Reason:   "manual_test_btc_1x",
 	}
 
-	_, err = sess.OpenPositionWithTpSl(ctx, sig, params)
+	_, err = sess.OpenPositionWithTpSl(ctx, sig, params, 0)
 	if err != nil {
 		_, _ = t.Send(ctx, chatID, "❗️Тестовая сделка не открылась: "+err.Error())
 		return
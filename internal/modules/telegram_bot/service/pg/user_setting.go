@@ -85,6 +85,43 @@ func (u *User) Get(
 	return user, err
 }
 
+// GetAll in db
+func (u *User) GetAll(
+	ctx context.Context,
+) (users []*models.UserSettings, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.GetAll: %w", err)
+		}
+	}()
+
+	err = u.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			users, err = u.user.GetAll(ctx, tx)
+			return err
+		})
+
+	return users, err
+}
+
+// SetEnabled — флаг «бот запущен» для автозапуска после рестарта
+func (u *User) SetEnabled(
+	ctx context.Context,
+	userID int64,
+	enabled bool,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.SetEnabled: %w", err)
+		}
+	}()
+	err = u.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return u.user.SetEnabled(ctx, tx, userID, enabled)
+		})
+	return err
+}
+
 // Delete in db
 func (u *User) Delete(
 	ctx context.Context,
@@ -19,8 +19,14 @@ WHERE chatid = @chatid and  id = @id;
 
 
 -- name: GetById :one
-SELECT id, name, settings, step::text FROM user_settings WHERE chatid = @chatid;
+SELECT id, name, settings, step::text, enabled FROM user_settings WHERE chatid = @chatid;
 
 
 -- name: GetAll :many
-SELECT id, chatid, name, settings, step::text FROM user_settings;
\ No newline at end of file
+SELECT id, chatid, name, settings, step::text, enabled FROM user_settings;
+
+
+-- name: SetEnabled :exec
+UPDATE user_settings
+SET enabled = @enabled
+WHERE chatid = @chatid;
\ No newline at end of file
@@ -25,7 +25,7 @@ func (q *Queries) Delete(ctx context.Context, db DBTX, arg *DeleteParams) error
 }
 
 const getAll = `-- name: GetAll :many
-SELECT id, chatid, name, settings, step::text FROM user_settings
+SELECT id, chatid, name, settings, step::text, enabled FROM user_settings
 `
 
 type GetAllRow struct {
@@ -34,6 +34,7 @@ type GetAllRow struct {
 	Name     string `db:"name"`
 	Settings []byte `db:"settings"`
 	Step     string `db:"step"`
+	Enabled  bool   `db:"enabled"`
 }
 
 func (q *Queries) GetAll(ctx context.Context, db DBTX) ([]*GetAllRow, error) {
@@ -51,6 +52,7 @@ func (q *Queries) GetAll(ctx context.Context, db DBTX) ([]*GetAllRow, error) {
 			&i.Name,
 			&i.Settings,
 			&i.Step,
+			&i.Enabled,
 		); err != nil {
 			return nil, err
 		}
@@ -63,7 +65,7 @@ func (q *Queries) GetAll(ctx context.Context, db DBTX) ([]*GetAllRow, error) {
 }
 
 const getById = `-- name: GetById :one
-SELECT id, name, settings, step::text FROM user_settings WHERE chatid = $1
+SELECT id, name, settings, step::text, enabled FROM user_settings WHERE chatid = $1
 `
 
 type GetByIdRow struct {
@@ -71,6 +73,7 @@ type GetByIdRow struct {
 	Name     string `db:"name"`
 	Settings []byte `db:"settings"`
 	Step     string `db:"step"`
+	Enabled  bool   `db:"enabled"`
 }
 
 func (q *Queries) GetById(ctx context.Context, db DBTX, chatid int64) (*GetByIdRow, error) {
@@ -81,6 +84,7 @@ func (q *Queries) GetById(ctx context.Context, db DBTX, chatid int64) (*GetByIdR
 		&i.Name,
 		&i.Settings,
 		&i.Step,
+		&i.Enabled,
 	)
 	return &i, err
 }
@@ -112,6 +116,22 @@ func (q *Queries) Insert(ctx context.Context, db DBTX, arg *InsertParams) (int64
 	return id, err
 }
 
+const setEnabled = `-- name: SetEnabled :exec
+UPDATE user_settings
+SET enabled = $1
+WHERE chatid = $2
+`
+
+type SetEnabledParams struct {
+	Enabled bool  `db:"enabled"`
+	Chatid  int64 `db:"chatid"`
+}
+
+func (q *Queries) SetEnabled(ctx context.Context, db DBTX, arg *SetEnabledParams) error {
+	_, err := db.Exec(ctx, setEnabled, arg.Enabled, arg.Chatid)
+	return err
+}
+
 const update = `-- name: Update :exec
 UPDATE user_settings
 SET  name = $1, settings = $2, step = $3::text
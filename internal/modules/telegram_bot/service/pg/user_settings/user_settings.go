@@ -99,29 +99,47 @@ func (u *UserSettings) GetById(ctx context.Context, tx pgx.Tx, chatID int64) (us
 		Name:     resp.Name,
 		Settings: t,
 		Step:     resp.Step,
+		Enabled:  resp.Enabled,
 	}, nil
 }
 
-//func (u *UserSettings) GetAll(ctx context.Context, tx pgx.Tx) (users []*models.UserSettings, err error) {
-//	defer func() {
-//		if err != nil {
-//			err = fmt.Errorf("UserSettings.GetAll: %w", err)
-//		}
-//	}()
-//	resp, err := u.sql.GetAll(ctx, tx)
-//	if err != nil {
-//		return nil, err
-//	}
-//	users = make([]*dto.UserSettings, len(resp))
-//
-//	for i := range resp {
-//		users = append(users, &dto.UserSettings{
-//			ID:       resp[i].ID,
-//			ChatID:   resp[i].Chatid,
-//			Name:     resp[i].Name,
-//			AuthCode: resp[i].AuthCode,
-//			Step:     resp[i].Step,
-//		})
-//	}
-//	return users, nil
-//}
+func (u *UserSettings) GetAll(ctx context.Context, tx pgx.Tx) (users []*models.UserSettings, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("UserSettings.GetAll: %w", err)
+		}
+	}()
+	resp, err := u.sql.GetAll(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	users = make([]*models.UserSettings, 0, len(resp))
+
+	for i := range resp {
+		var t models.Settings
+		if err = sonic.Unmarshal(resp[i].Settings, &t); err != nil {
+			return nil, err
+		}
+		users = append(users, &models.UserSettings{
+			ID:       resp[i].ID,
+			UserID:   resp[i].Chatid,
+			Name:     resp[i].Name,
+			Settings: t,
+			Step:     resp[i].Step,
+			Enabled:  resp[i].Enabled,
+		})
+	}
+	return users, nil
+}
+
+func (u *UserSettings) SetEnabled(ctx context.Context, tx pgx.Tx, chatID int64, enabled bool) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("UserSettings.SetEnabled: %w", err)
+		}
+	}()
+	return u.sql.SetEnabled(ctx, tx, &sql.SetEnabledParams{
+		Chatid:  chatID,
+		Enabled: enabled,
+	})
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (t *Telegram) handleRiskMenu(ctx context.Context, chatID int64) {
+	user, err := t.getUser(ctx, chatID)
+	if err != nil {
+		_, _ = t.Send(ctx, chatID, "Настройки не найдены, попробуй /start")
+		return
+	}
+
+	ts := user.Settings.TradingSettings
+
+	var b strings.Builder
+	b.WriteString("🧯 *Риск-лимиты*\n\n")
+
+	fmt.Fprintf(&b,
+		"📉 *Дневной убыток*: `%s` / `%s`\n"+
+			"— От equity на начало дня (UTC), с учётом открытых позиций\n\n"+
+			"🔁 *Убыточных подряд*: `%s`\n\n"+
+			"🕳 *Просадка от пика*: `%s`\n\n"+
+			"🧹 *Закрыть всё при срабатывании*: *%s*\n\n"+
+			"💼 *Портфель* (проверка перед входом)\n"+
+			"• Notional всего: `%s`\n"+
+			"• Чистая экспозиция: `%s`\n"+
+			"• В одну сторону: `%s`\n"+
+			"• Корреляция с позициями: `%s` → %s\n\n"+
+			"— При нарушении бот не берёт сигналы до 00:00 UTC\n"+
+			"  или до ручного сброса. 0 — лимит выключен.\n"+
+			"— Изменения применяются после перезапуска бота\n",
+		limitStr(ts.MaxDailyLossPct, "%"),
+		limitStr(ts.MaxDailyLossUSDT, " USDT"),
+		limitInt(ts.MaxConsecLosses),
+		limitStr(ts.MaxDrawdownPct, "%"),
+		onOff(ts.FlattenOnHalt),
+		limitStr(ts.MaxTotalNotionalX, "x equity"),
+		limitStr(ts.MaxNetNotionalX, "x equity"),
+		limitInt(ts.MaxSameDirection),
+		limitStr(ts.MaxCorrelation, ""),
+		corrAction(ts.CorrSizeMult),
+	)
+
+	if st, running := t.router.RiskForUser(chatID); running {
+		if st.Halted {
+			fmt.Fprintf(&b, "\n⛔️ *Сейчас стоп*: %s\n— до %s UTC\n",
+				st.Reason, st.Until.UTC().Format("02.01 15:04"))
+		} else {
+			fmt.Fprintf(&b, "\n✅ Торговля разрешена (серия убытков: `%d`)\n", st.LossStreak)
+		}
+	}
+
+	kb := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			btn("📉 Убыток %", "set:daily_loss_pct"),
+			btn("📉 Убыток USDT", "set:daily_loss_usdt"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("🔁 Подряд", "set:max_losses"),
+			btn("🕳 Просадка %", "set:max_dd_pct"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("💼 Notional x", "set:notional_x"),
+			btn("⚖️ Net x", "set:net_x"),
+			btn("↔️ В одну сторону", "set:same_dir"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("🔗 Корреляция", "set:max_corr"),
+			btn("↘️ Доля риска", "set:corr_mult"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn(toggleLabel("🧹 Закрыть всё", ts.FlattenOnHalt), "toggle:flatten"),
+			btn("🔓 Снять стоп", "risk:reset"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			btn("⬅️ Назад", "menu:settings"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = kb
+	_, _ = t.SendMessage(ctx, msg)
+}
+
+// handleRiskReset — /risk_reset и кнопка «Снять стоп».
+func (t *Telegram) handleRiskReset(ctx context.Context, chatID int64) {
+	st, running := t.router.RiskForUser(chatID)
+	if !running {
+		_, _ = t.Send(ctx, chatID, "ℹ️ Бот не запущен — сбрасывать нечего.")
+		return
+	}
+	if err := t.router.ResetRiskForUser(ctx, chatID); err != nil {
+		_, _ = t.Send(ctx, chatID, "⚠️ Не удалось сбросить: "+err.Error())
+		return
+	}
+	if st.Halted {
+		_, _ = t.Send(ctx, chatID, "🔓 Риск-стоп снят, лимиты считаются заново от текущей equity.")
+		return
+	}
+	_, _ = t.Send(ctx, chatID, "🔓 Счётчики риск-лимитов сброшены.")
+}
+
+func (t *Telegram) toggleFlatten(ctx context.Context, chatID int64) {
+	user, err := t.getUser(ctx, chatID)
+	if err != nil {
+		_, _ = t.Send(ctx, chatID, "Настройки не найдены, попробуй /start")
+		return
+	}
+
+	ts := &user.Settings.TradingSettings
+	ts.FlattenOnHalt = !ts.FlattenOnHalt
+
+	if err := t.repo.Update(ctx, user); err != nil {
+		_, _ = t.Send(ctx, chatID, "⚠️ Не удалось сохранить: "+err.Error())
+		return
+	}
+	t.handleRiskMenu(ctx, chatID)
+}
+
+func limitStr(v float64, unit string) string {
+	if v <= 0 {
+		return "выкл"
+	}
+	return f2(v) + unit
+}
+
+// corrAction — что делаем с сигналом выше порога корреляции.
+func corrAction(mult float64) string {
+	if mult <= 0 || mult >= 1 {
+		return "пропуск"
+	}
+	return "риск x" + f2(mult)
+}
+
+func limitInt(v int) string {
+	if v <= 0 {
+		return "выкл"
+	}
+	return strconv.Itoa(v)
+}
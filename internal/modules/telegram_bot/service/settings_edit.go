@@ -21,6 +21,14 @@ func (t *Telegram) askValue(ctx context.Context, chatID int64, key string) {
 		hint = "Введи *риск* в %, например: `1.0` (1% риска по стопу)"
 	case "stop":
 		hint = "Введи *стоп* в %, например: `1.2`"
+	case "stop_atr_k":
+		hint = "Введи *k* для стопа от ATR, например: `2.0` (SL = вход ± k × ATR)"
+	case "entry_offset":
+		hint = "Введи *сдвиг лимита* от цены сигнала в %, например: `0.05` (>0 — BUY выше/SELL ниже, быстрее исполнится; <0 — ждать отката)"
+	case "entry_timeout":
+		hint = "Введи *ожидание лимита* в секундах, например: `20` (потом остаток отменяется или добирается по рынку)"
+	case "tp_ladder":
+		hint = "Введи *лестницу TP* как `R:%` через пробел, например: `1:30 2:30` — 30% на 1R, 30% на 2R, остаток ведёт трейлинг (`0` — выкл)"
 	case "tp_rr":
 		hint = "Введи *тейк* в R, например: `2.0` (TP=2R)"
 	case "lev":
@@ -28,6 +36,26 @@ func (t *Telegram) askValue(ctx context.Context, chatID int64, key string) {
 	case "maxpos":
 		hint = "Введи *макс. открытых позиций* (целое), например: `6`"
 
+	// --- риск-лимиты ---
+	case "daily_loss_pct":
+		hint = "Введи *макс. дневной убыток* в % от equity, например: `3` (0 — выкл)"
+	case "daily_loss_usdt":
+		hint = "Введи *макс. дневной убыток* в USDT, например: `50` (0 — выкл)"
+	case "max_losses":
+		hint = "Введи *макс. убыточных сделок подряд* (целое), например: `4` (0 — выкл)"
+	case "max_dd_pct":
+		hint = "Введи *макс. просадку от пика* в %, например: `10` (0 — выкл)"
+	case "notional_x":
+		hint = "Введи *макс. суммарный notional* в долях equity, например: `3` (0 — выкл)"
+	case "net_x":
+		hint = "Введи *макс. чистую экспозицию* (long − short) в долях equity, например: `2` (0 — выкл)"
+	case "same_dir":
+		hint = "Введи *макс. позиций в одну сторону* (целое), например: `3` (0 — выкл)"
+	case "max_corr":
+		hint = "Введи *порог корреляции* с открытыми позициями той же стороны, например: `0.8` (0 — выкл)"
+	case "corr_mult":
+		hint = "Введи *долю риска* для коррелированного входа, например: `0.5` (0 — пропускать сигнал)"
+
 	// --- TrailingConfig (ВСЕ поля) ---
 	case "be_trigger_r":
 		hint = "Введи *BE Trigger* в R, например: `0.6`"
@@ -45,6 +73,12 @@ func (t *Telegram) askValue(ctx context.Context, chatID int64, key string) {
 		hint = "Введи *Partial Trigger* в R, например: `0.9`"
 	case "partial_close_frac":
 		hint = "Введи *Partial Close* в % (1..100), например: `50`"
+	case "trail_atr_mult":
+		hint = "Введи *k* для трейлинга от ATR, например: `3.0` (SL = MFE ∓ k × ATR 15m)"
+	case "trail_dist_r":
+		hint = "Введи *дистанцию трейлинга* в R, например: `1.0` (SL = MFE ∓ d × R)"
+	case "trail_bars":
+		hint = "Введи *число 15m баров* для трейлинга за экстремумом, например: `3`"
 
 	default:
 		hint = "Введи значение"
@@ -97,6 +131,38 @@ func (t *Telegram) handleAwaitValue(ctx context.Context, chatID int64, text, key
 		}
 		ts.StopPct = v
 
+	case "stop_atr_k":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v <= 0 || v > 10 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число 0..10, например `2.0`")
+			return
+		}
+		ts.StopATRMult = v
+
+	case "entry_offset":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v < -2 || v > 2 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число -2..2, например `0.05`")
+			return
+		}
+		ts.EntryOffsetPct = v
+
+	case "entry_timeout":
+		v, err := strconv.Atoi(text)
+		if err != nil || v < 1 || v > 600 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно целое 1..600, например `20`")
+			return
+		}
+		ts.EntryTimeout = time.Duration(v) * time.Second
+
+	case "tp_ladder":
+		v, err := parseLadder(text)
+		if err != nil {
+			_, _ = t.Send(ctx, chatID, "❗️"+err.Error()+", например `1:30 2:30`")
+			return
+		}
+		ts.TPLadder = v
+
 	case "tp_rr":
 		v, err := strconv.ParseFloat(text, 64)
 		if err != nil || v <= 0 || v > 20 {
@@ -121,6 +187,79 @@ func (t *Telegram) handleAwaitValue(ctx context.Context, chatID int64, text, key
 		}
 		ts.MaxOpenPositions = v
 
+	// -------- риск-лимиты (0 — выкл) --------
+	case "daily_loss_pct":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v < 0 || v > 100 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число 0..100, например `3`")
+			return
+		}
+		ts.MaxDailyLossPct = v
+
+	case "daily_loss_usdt":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v < 0 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число ≥ 0, например `50`")
+			return
+		}
+		ts.MaxDailyLossUSDT = v
+
+	case "max_losses":
+		v, err := strconv.Atoi(text)
+		if err != nil || v < 0 || v > 100 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно целое 0..100, например `4`")
+			return
+		}
+		ts.MaxConsecLosses = v
+
+	case "max_dd_pct":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v < 0 || v > 100 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число 0..100, например `10`")
+			return
+		}
+		ts.MaxDrawdownPct = v
+
+	case "notional_x":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v < 0 || v > 100 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число 0..100, например `3`")
+			return
+		}
+		ts.MaxTotalNotionalX = v
+
+	case "net_x":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v < 0 || v > 100 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число 0..100, например `2`")
+			return
+		}
+		ts.MaxNetNotionalX = v
+
+	case "same_dir":
+		v, err := strconv.Atoi(text)
+		if err != nil || v < 0 || v > 50 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно целое 0..50, например `3`")
+			return
+		}
+		ts.MaxSameDirection = v
+
+	case "max_corr":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v < 0 || v > 1 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число 0..1, например `0.8`")
+			return
+		}
+		ts.MaxCorrelation = v
+
+	case "corr_mult":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v < 0 || v >= 1 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число 0..0.99, например `0.5`")
+			return
+		}
+		ts.CorrSizeMult = v
+
 	// -------- TrailingConfig (ВСЕ поля) --------
 	case "be_trigger_r":
 		v, err := strconv.ParseFloat(text, 64)
@@ -187,6 +326,30 @@ func (t *Telegram) handleAwaitValue(ctx context.Context, chatID int64, text, key
 		}
 		tr.PartialCloseFrac = p / 100.0
 
+	case "trail_atr_mult":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v <= 0 || v > 10 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число 0..10, например `3.0`")
+			return
+		}
+		tr.TrailATRMult = v
+
+	case "trail_dist_r":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v <= 0 || v > 10 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно число 0..10, например `1.0`")
+			return
+		}
+		tr.TrailDistR = v
+
+	case "trail_bars":
+		v, err := strconv.Atoi(text)
+		if err != nil || v < 1 || v > 50 {
+			_, _ = t.Send(ctx, chatID, "❗️Нужно целое 1..50, например `3`")
+			return
+		}
+		tr.TrailBars = v
+
 	default:
 		_, _ = t.Send(ctx, chatID, "❗️Неизвестная настройка")
 		return
@@ -212,13 +375,28 @@ func (t *Telegram) handleAwaitValue(ctx context.Context, chatID int64, text, key
 		t.handleTrailingMenu(ctx, chatID)
 		return
 	}
+	if isRiskKey(key) {
+		t.handleRiskMenu(ctx, chatID)
+		return
+	}
 	t.handleSettingsMenu(ctx, chatID)
 }
 
 func isTrailingKey(key string) bool {
 	switch key {
 	case "be_trigger_r", "be_offset_r", "lock_trigger_r", "lock_offset_r",
-		"timestop_bars", "timestop_min_mfe_r", "partial_trigger_r", "partial_close_frac":
+		"timestop_bars", "timestop_min_mfe_r", "partial_trigger_r", "partial_close_frac",
+		"trail_atr_mult", "trail_dist_r", "trail_bars":
+		return true
+	default:
+		return false
+	}
+}
+
+func isRiskKey(key string) bool {
+	switch key {
+	case "daily_loss_pct", "daily_loss_usdt", "max_losses", "max_dd_pct",
+		"notional_x", "net_x", "same_dir", "max_corr", "corr_mult":
 		return true
 	default:
 		return false
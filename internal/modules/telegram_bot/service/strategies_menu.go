@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"trade_bot/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleStrategiesMenu — на сигналы каких стратегий подписан юзер.
+// Список — движки, которые крутятся в Hub (strategy.engines в конфиге).
+func (t *Telegram) handleStrategiesMenu(ctx context.Context, chatID int64) {
+	user, err := t.getUser(ctx, chatID)
+	if err != nil {
+		_, _ = t.Send(ctx, chatID, "Настройки не найдены, попробуй /start")
+		return
+	}
+
+	ts := user.Settings.TradingSettings
+
+	var b strings.Builder
+	b.WriteString("🧠 *Стратегии*\n\n")
+	fmt.Fprintf(&b, "Подписка: `%s`\n\n", strategiesStr(&ts))
+	b.WriteString("— Бот берёт сигналы только отмеченных стратегий\n" +
+		"— Изменения применяются после перезапуска бота\n")
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(t.cfg.Strategy.Engines)+1)
+	for _, name := range t.cfg.Strategy.Engines {
+		st := models.StrategyType(name)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			btn(toggleLabel(name, ts.Subscribed(st)), "strat:"+name),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		btn("⬅️ Назад", "menu:settings"),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, _ = t.SendMessage(ctx, msg)
+}
+
+// toggleStrategy — подписка на стратегию вкл/выкл; хотя бы одна должна остаться.
+func (t *Telegram) toggleStrategy(ctx context.Context, chatID int64, name string) {
+	user, err := t.getUser(ctx, chatID)
+	if err != nil {
+		_, _ = t.Send(ctx, chatID, "Настройки не найдены, попробуй /start")
+		return
+	}
+
+	st := models.StrategyType(name)
+	known := false
+	for _, n := range t.cfg.Strategy.Engines {
+		known = known || n == name
+	}
+	if !known {
+		_, _ = t.Send(ctx, chatID, "Неизвестная стратегия")
+		return
+	}
+
+	ts := &user.Settings.TradingSettings
+	if len(ts.Strategies) == 0 {
+		ts.Strategies = []models.StrategyType{models.DefaultStrategy}
+	}
+
+	next := make([]models.StrategyType, 0, len(ts.Strategies)+1)
+	for _, s := range ts.Strategies {
+		if s != st {
+			next = append(next, s)
+		}
+	}
+	if len(next) == len(ts.Strategies) {
+		next = append(next, st)
+	}
+	if len(next) == 0 {
+		_, _ = t.Send(ctx, chatID, "❗️Нужна хотя бы одна стратегия")
+		return
+	}
+	ts.Strategies = next
+
+	if err := t.repo.Update(ctx, user); err != nil {
+		_, _ = t.Send(ctx, chatID, "⚠️ Не удалось сохранить: "+err.Error())
+		return
+	}
+	t.handleStrategiesMenu(ctx, chatID)
+}
+
+// strategiesStr — "donchianV2, emarsi".
+func strategiesStr(ts *models.TradingSettings) string {
+	if len(ts.Strategies) == 0 {
+		return string(models.DefaultStrategy)
+	}
+	names := make([]string, len(ts.Strategies))
+	for i, s := range ts.Strategies {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ", ")
+}
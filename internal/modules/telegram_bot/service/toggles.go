@@ -1,6 +1,9 @@
 package service
 
-import "context"
+import (
+	"context"
+	"trade_bot/internal/models"
+)
 
 func (t *Telegram) toggleConfirm(ctx context.Context, chatID int64) {
 	user, err := t.getUser(ctx, chatID)
@@ -14,6 +17,130 @@ func (t *Telegram) toggleConfirm(ctx context.Context, chatID int64) {
 	t.handleSettingsMenu(ctx, chatID)
 }
 
+// toggleDemo — торговля на demo-аккаунте OKX (нужны demo-ключи).
+// Клиент биржи создаётся при запуске сессии, поэтому нужен перезапуск бота.
+func (t *Telegram) toggleDemo(ctx context.Context, chatID int64) {
+	user, err := t.getUser(ctx, chatID)
+	if err != nil {
+		_, _ = t.Send(ctx, chatID, "Настройки не найдены, попробуй /start")
+		return
+	}
+
+	ts := &user.Settings.TradingSettings
+	ts.OKXDemo = !ts.OKXDemo
+
+	if err := t.repo.Update(ctx, user); err != nil {
+		_, _ = t.Send(ctx, chatID, "⚠️ Не удалось сохранить: "+err.Error())
+		return
+	}
+
+	if _, running := t.router.GetSession(chatID); running {
+		_, _ = t.Send(ctx, chatID, "ℹ️ Режим применится после перезапуска: ⏹ Остановить → ▶️ Запустить.")
+	}
+	t.handleSettingsMenu(ctx, chatID)
+}
+
+// togglePaper — виртуальный счёт бота вместо OKX: живые цены, без денег и ключей.
+func (t *Telegram) togglePaper(ctx context.Context, chatID int64) {
+	user, err := t.getUser(ctx, chatID)
+	if err != nil {
+		_, _ = t.Send(ctx, chatID, "Настройки не найдены, попробуй /start")
+		return
+	}
+
+	ts := &user.Settings.TradingSettings
+	ts.Paper = !ts.Paper
+
+	if err := t.repo.Update(ctx, user); err != nil {
+		_, _ = t.Send(ctx, chatID, "⚠️ Не удалось сохранить: "+err.Error())
+		return
+	}
+
+	if _, running := t.router.GetSession(chatID); running {
+		_, _ = t.Send(ctx, chatID, "ℹ️ Режим применится после перезапуска: ⏹ Остановить → ▶️ Запустить.")
+	}
+	t.handleSettingsMenu(ctx, chatID)
+}
+
+// toggleStopMode — по кругу: percent → atr → channel.
+func (t *Telegram) toggleStopMode(ctx context.Context, chatID int64) {
+	user, err := t.getUser(ctx, chatID)
+	if err != nil {
+		_, _ = t.Send(ctx, chatID, "Настройки не найдены, попробуй /start")
+		return
+	}
+
+	ts := &user.Settings.TradingSettings
+	switch ts.StopMode {
+	case models.StopModeATR:
+		ts.StopMode = models.StopModeChannel
+	case models.StopModeChannel:
+		ts.StopMode = models.StopModePercent
+	default:
+		ts.StopMode = models.StopModeATR
+	}
+
+	if err := t.repo.Update(ctx, user); err != nil {
+		_, _ = t.Send(ctx, chatID, "⚠️ Не удалось сохранить: "+err.Error())
+		return
+	}
+	t.handleSettingsMenu(ctx, chatID)
+}
+
+// toggleEntryMode — по кругу: market → limit → post-only → limit → market.
+func (t *Telegram) toggleEntryMode(ctx context.Context, chatID int64) {
+	user, err := t.getUser(ctx, chatID)
+	if err != nil {
+		_, _ = t.Send(ctx, chatID, "Настройки не найдены, попробуй /start")
+		return
+	}
+
+	ts := &user.Settings.TradingSettings
+	switch ts.EntryMode {
+	case models.EntryLimit:
+		ts.EntryMode = models.EntryPostOnly
+	case models.EntryPostOnly:
+		ts.EntryMode = models.EntryLimitMarket
+	case models.EntryLimitMarket:
+		ts.EntryMode = models.EntryMarket
+	default:
+		ts.EntryMode = models.EntryLimit
+	}
+
+	if err := t.repo.Update(ctx, user); err != nil {
+		_, _ = t.Send(ctx, chatID, "⚠️ Не удалось сохранить: "+err.Error())
+		return
+	}
+	t.handleSettingsMenu(ctx, chatID)
+}
+
+// toggleTrailMode — по кругу: выкл → atr → r → bars.
+func (t *Telegram) toggleTrailMode(ctx context.Context, chatID int64) {
+	user, err := t.getUser(ctx, chatID)
+	if err != nil {
+		_, _ = t.Send(ctx, chatID, "Настройки не найдены, попробуй /start")
+		return
+	}
+
+	tr := &user.Settings.TrailingConfig
+	switch tr.TrailMode {
+	case models.TrailModeOff:
+		tr.TrailMode = models.TrailModeATR
+	case models.TrailModeATR:
+		tr.TrailMode = models.TrailModeR
+	case models.TrailModeR:
+		tr.TrailMode = models.TrailModeBars
+	default:
+		tr.TrailMode = models.TrailModeOff
+	}
+
+	if err := t.repo.Update(ctx, user); err != nil {
+		_, _ = t.Send(ctx, chatID, "⚠️ Не удалось сохранить: "+err.Error())
+		return
+	}
+	t.handleTrailingMenu(ctx, chatID)
+}
+
 func (t *Telegram) togglePartial(ctx context.Context, chatID int64) {
 	user, err := t.getUser(ctx, chatID)
 	if err != nil {
@@ -0,0 +1,207 @@
+package okxfake
+
+import (
+	"sort"
+	"strconv"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// fillLocked исполняет market-ордер по px и двигает позицию/баланс.
+// long: buy открывает, sell закрывает; short — наоборот.
+func (s *Server) fillLocked(instID, side, posSide string, sz, px float64, reduceOnly bool, clOrdID string) *Order {
+	in := s.instruments[instID]
+	k := posKey{instID: instID, posSide: posSide}
+	p := s.positions[k]
+	if p == nil {
+		p = &position{}
+		s.positions[k] = p
+	}
+
+	opening := (posSide == "long" && side == "buy") || (posSide == "short" && side == "sell")
+	if opening && reduceOnly {
+		return nil
+	}
+
+	fee := px * sz * in.CtVal * s.TakerFee
+	var pnl float64
+	if opening {
+		if p.size <= 0 {
+			p.openedAt = time.Now()
+		}
+		p.avgPx = (p.avgPx*p.size + px*sz) / (p.size + sz)
+		p.size += sz
+	} else {
+		if sz > p.size {
+			sz = p.size
+		}
+		if sz <= 0 {
+			return nil
+		}
+		fee = px * sz * in.CtVal * s.TakerFee
+		pnl = (px - p.avgPx) * sz * in.CtVal
+		if posSide == "short" {
+			pnl = -pnl
+		}
+		p.size -= sz
+		p.realized += pnl
+		s.balance += pnl
+		if p.size <= 1e-12 {
+			p.size = 0
+			s.cancelPositionAlgosLocked(k)
+		}
+	}
+	s.balance -= fee
+
+	o := &Order{
+		OrdID:      s.nextIDLocked(),
+		ClOrdID:    clOrdID,
+		InstID:     instID,
+		Side:       side,
+		PosSide:    posSide,
+		Size:       sz,
+		AvgPx:      px,
+		Fee:        fee,
+		Pnl:        pnl,
+		ReduceOnly: !opening,
+		CreatedAt:  time.Now(),
+	}
+	s.orders[o.OrdID] = o
+	return o
+}
+
+// fillLimitLocked исполняет лимит по px целиком; ордер сохраняет свой ordId.
+func (s *Server) fillLimitLocked(lim *Order, px float64) {
+	o := s.fillLocked(lim.InstID, lim.Side, lim.PosSide, lim.Size, px, false, lim.ClOrdID)
+	if o == nil {
+		lim.State = "canceled"
+		return
+	}
+	delete(s.orders, o.OrdID)
+	delete(s.limits, lim.OrdID)
+	o.OrdID, o.OrdType, o.Px = lim.OrdID, lim.OrdType, lim.Px
+	s.orders[o.OrdID] = o
+	s.pushFillLocked(o)
+}
+
+// fillLimitsLocked — живые лимиты, до которых дошла закрытая 1m свеча, исполняются по своей цене.
+func (s *Server) fillLimitsLocked(c models.CandleTick) {
+	live := make([]*Order, 0)
+	for _, o := range s.limits {
+		if o.InstID == c.InstID && o.State == "live" && limitHit(o, c.Low, c.High) {
+			live = append(live, o)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool {
+		ni, _ := strconv.Atoi(live[i].OrdID)
+		nj, _ := strconv.Atoi(live[j].OrdID)
+		return ni < nj
+	})
+	for _, o := range live {
+		s.fillLimitLocked(o, o.Px)
+	}
+}
+
+// limitHit — цена дошла до лимита: buy — low не выше px, sell — high не ниже.
+func limitHit(o *Order, low, high float64) bool {
+	if o.Side == "buy" {
+		return low <= o.Px
+	}
+	return high >= o.Px
+}
+
+func (o *Order) ordType() string {
+	if o.OrdType == "" {
+		return "market"
+	}
+	return o.OrdType
+}
+
+// triggerAlgosLocked — проверка SL/TP по high/low закрытой 1m свечи.
+// Если в одной свече задеты и SL, и TP — считаем, что первым был SL.
+func (s *Server) triggerAlgosLocked(c models.CandleTick) {
+	live := make([]*Algo, 0)
+	for _, a := range s.algos {
+		if a.InstID == c.InstID && a.State == "live" {
+			live = append(live, a)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool {
+		si, sj := live[i].SLTriggerPx > 0, live[j].SLTriggerPx > 0
+		if si != sj {
+			return si
+		}
+		ni, _ := strconv.Atoi(live[i].AlgoID)
+		nj, _ := strconv.Atoi(live[j].AlgoID)
+		return ni < nj
+	})
+
+	for _, a := range live {
+		if a.State != "live" {
+			continue // могли отменить при закрытии позиции
+		}
+		px, hit := algoHit(a, c)
+		if !hit {
+			continue
+		}
+		a.State = "effective"
+		s.pushAlgoLocked(a, px)
+		if o := s.fillLocked(a.InstID, a.Side, a.PosSide, a.Size, px, true, ""); o != nil {
+			o.AlgoID, o.AlgoClOrdID = a.AlgoID, a.AlgoClOrdID
+			s.pushFillLocked(o)
+		}
+	}
+}
+
+func algoHit(a *Algo, c models.CandleTick) (float64, bool) {
+	long := a.PosSide == "long"
+	switch {
+	case a.SLTriggerPx > 0 && long && c.Low <= a.SLTriggerPx:
+		return a.SLTriggerPx, true
+	case a.SLTriggerPx > 0 && !long && c.High >= a.SLTriggerPx:
+		return a.SLTriggerPx, true
+	case a.TPTriggerPx > 0 && long && c.High >= a.TPTriggerPx:
+		return a.TPTriggerPx, true
+	case a.TPTriggerPx > 0 && !long && c.Low <= a.TPTriggerPx:
+		return a.TPTriggerPx, true
+	}
+	return 0, false
+}
+
+func (s *Server) cancelPositionAlgosLocked(k posKey) {
+	for _, a := range s.algos {
+		if a.InstID == k.instID && a.PosSide == k.posSide && a.State == "live" {
+			a.State = "canceled"
+			s.pushAlgoLocked(a, 0)
+		}
+	}
+}
+
+// Funding — списать/начислить funding по открытым позициям instID:
+// rate > 0 — long платит short, как на OKX. Пишется bill type=8.
+func (s *Server) Funding(instID string, rate float64) {
+	defer s.flushPrivate()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	in := s.instruments[instID]
+	for _, side := range []string{"long", "short"} {
+		p := s.positions[posKey{instID: instID, posSide: side}]
+		if p == nil || p.size <= 0 {
+			continue
+		}
+		chg := -rate * s.last[instID] * p.size * in.CtVal
+		if side == "short" {
+			chg = -chg
+		}
+		s.balance += chg
+		s.bills = append(s.bills, Bill{
+			BillID:    s.nextIDLocked(),
+			InstID:    instID,
+			Type:      "8",
+			BalChg:    chg,
+			CreatedAt: time.Now(),
+		})
+		s.pushAccountLocked()
+	}
+}
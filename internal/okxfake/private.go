@@ -0,0 +1,111 @@
+package okxfake
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// privFrame — пуш приватного канала; arg — на что должен быть подписан клиент.
+type privFrame struct {
+	arg   wsArg
+	frame []byte
+}
+
+var (
+	argOrders    = wsArg{Channel: "orders", InstType: "SWAP"}
+	argAlgos     = wsArg{Channel: "orders-algo", InstType: "SWAP"}
+	argPositions = wsArg{Channel: "positions", InstType: "SWAP"}
+	argAccount   = wsArg{Channel: "account", Ccy: "USDT"}
+)
+
+func isPrivateChannel(ch string) bool {
+	switch ch {
+	case "orders", "orders-algo", "positions", "account":
+		return true
+	}
+	return false
+}
+
+func (s *Server) queuePrivateLocked(arg wsArg, data any) {
+	frame, _ := json.Marshal(map[string]any{"arg": arg, "data": data})
+	s.privOut = append(s.privOut, privFrame{arg: arg, frame: frame})
+}
+
+// pushFillLocked — исполнение ордера: orders, затем позиция и баланс, как на OKX.
+func (s *Server) pushFillLocked(o *Order) {
+	s.queuePrivateLocked(argOrders, []map[string]string{{
+		"instType":    "SWAP",
+		"instId":      o.InstID,
+		"ordId":       o.OrdID,
+		"clOrdId":     o.ClOrdID,
+		"algoId":      o.AlgoID,
+		"algoClOrdId": o.AlgoClOrdID,
+		"side":        o.Side,
+		"posSide":     o.PosSide,
+		"ordType":     o.ordType(),
+		"state":       "filled",
+		"sz":          fmtF(o.Size),
+		"fillPx":      fmtF(o.AvgPx),
+		"fillSz":      fmtF(o.Size),
+		"accFillSz":   fmtF(o.Size),
+		"avgPx":       fmtF(o.AvgPx),
+		"fillFee":     fmtF(-o.Fee),
+		"fillPnl":     fmtF(o.Pnl),
+		"reduceOnly":  strconv.FormatBool(o.ReduceOnly),
+		"uTime":       strconv.FormatInt(o.CreatedAt.UnixMilli(), 10),
+	}})
+	s.queuePrivateLocked(argPositions, []map[string]string{
+		s.positionRowLocked(posKey{instID: o.InstID, posSide: o.PosSide}),
+	})
+	s.pushAccountLocked()
+}
+
+// pushAlgoLocked — смена состояния SL/TP; px — цена исполнения (0 — не исполнялся).
+func (s *Server) pushAlgoLocked(a *Algo, px float64) {
+	row := map[string]string{
+		"instType":    "SWAP",
+		"instId":      a.InstID,
+		"algoId":      a.AlgoID,
+		"algoClOrdId": a.AlgoClOrdID,
+		"ordType":     "conditional",
+		"side":        a.Side,
+		"posSide":     a.PosSide,
+		"sz":          fmtF(a.Size),
+		"slTriggerPx": fmtF(a.SLTriggerPx),
+		"tpTriggerPx": fmtF(a.TPTriggerPx),
+		"state":       a.State,
+		"actualPx":    "",
+		"actualSz":    "",
+		"cTime":       strconv.FormatInt(a.CreatedAt.UnixMilli(), 10),
+	}
+	if px > 0 {
+		row["actualPx"] = fmtF(px)
+		row["actualSz"] = fmtF(a.Size)
+	}
+	s.queuePrivateLocked(argAlgos, []map[string]string{row})
+}
+
+func (s *Server) pushAccountLocked() {
+	s.queuePrivateLocked(argAccount, []map[string]any{s.balanceRowLocked()})
+}
+
+// flushPrivate рассылает накопленные пуши залогиненным подписчикам.
+// Зовётся после s.mu.Unlock: запись в сокет не держит стенд.
+func (s *Server) flushPrivate() {
+	s.mu.Lock()
+	out := s.privOut
+	s.privOut = nil
+	subs := make([]*wsConn, 0, len(s.subs))
+	for conn := range s.subs {
+		subs = append(subs, conn)
+	}
+	s.mu.Unlock()
+
+	for _, f := range out {
+		for _, conn := range subs {
+			if conn.loggedIn() && conn.subscribed(f.arg) {
+				conn.write(f.frame)
+			}
+		}
+	}
+}
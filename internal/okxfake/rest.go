@@ -0,0 +1,557 @@
+package okxfake
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type okxResp struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+	Data any    `json:"data"`
+}
+
+func writeOK(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(okxResp{Code: "0", Data: data})
+}
+
+func writeErr(w http.ResponseWriter, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(okxResp{Code: code, Msg: msg, Data: []any{}})
+}
+
+// private — как у OKX: без ключа приватные эндпоинты не отвечают.
+func (s *Server) private(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("OK-ACCESS-KEY") == "" {
+			writeErr(w, "50103", "Request header OK-ACCESS-KEY can not be empty.")
+			return
+		}
+		h(w, r)
+	}
+}
+
+func fmtF(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+func parseMs(s string) time.Time {
+	ms, _ := strconv.ParseInt(s, 10, 64)
+	return time.UnixMilli(ms)
+}
+
+func parseF(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// ===== /trade/order =====
+
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("OK-ACCESS-KEY") == "" {
+		writeErr(w, "50103", "Request header OK-ACCESS-KEY can not be empty.")
+		return
+	}
+	if r.Method == http.MethodGet {
+		s.handleGetOrder(w, r)
+		return
+	}
+
+	var req struct {
+		InstID     string `json:"instId"`
+		Side       string `json:"side"`
+		PosSide    string `json:"posSide"`
+		OrdType    string `json:"ordType"`
+		Px         string `json:"px"`
+		Sz         string `json:"sz"`
+		ReduceOnly bool   `json:"reduceOnly"`
+		ClOrdID    string `json:"clOrdId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, "50000", "bad body: "+err.Error())
+		return
+	}
+	switch req.OrdType {
+	case "market":
+	case "limit", "post_only":
+		if req.ReduceOnly || parseF(req.Px) <= 0 {
+			writeErr(w, "51000", "fake: limit orders only open positions and need px")
+			return
+		}
+	default:
+		writeErr(w, "51000", "fake: only market, limit and post_only orders supported")
+		return
+	}
+
+	defer s.flushPrivate() // после Unlock
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	px := s.last[req.InstID]
+	if _, ok := s.instruments[req.InstID]; !ok || px <= 0 {
+		writeErr(w, "51001", "Instrument ID does not exist")
+		return
+	}
+	sz := parseF(req.Sz)
+	if sz <= 0 {
+		writeErr(w, "51000", "Parameter sz error")
+		return
+	}
+
+	if req.OrdType != "market" {
+		lim := &Order{
+			OrdID:     s.nextIDLocked(),
+			ClOrdID:   req.ClOrdID,
+			OrdType:   req.OrdType,
+			Px:        parseF(req.Px),
+			State:     "live",
+			InstID:    req.InstID,
+			Side:      req.Side,
+			PosSide:   req.PosSide,
+			Size:      sz,
+			CreatedAt: time.Now(),
+		}
+		s.limits[lim.OrdID] = lim
+		if limitHit(lim, px, px) {
+			// пересекает рынок: post-only снимается, limit берёт по last
+			if lim.OrdType == "post_only" {
+				lim.State = "canceled"
+			} else {
+				s.fillLimitLocked(lim, px)
+			}
+		}
+		writeOK(w, []map[string]string{{"ordId": lim.OrdID, "clOrdId": lim.ClOrdID, "sCode": "0", "sMsg": ""}})
+		return
+	}
+
+	o := s.fillLocked(req.InstID, req.Side, req.PosSide, sz, px, req.ReduceOnly, req.ClOrdID)
+	if o == nil {
+		writeOK(w, []map[string]string{{"ordId": "", "sCode": "51169", "sMsg": "no position to reduce"}})
+		return
+	}
+	s.pushFillLocked(o)
+	writeOK(w, []map[string]string{{"ordId": o.OrdID, "clOrdId": o.ClOrdID, "sCode": "0", "sMsg": ""}})
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.URL.Query().Get("ordId")
+	o, ok := s.orders[id]
+	if !ok {
+		o, ok = s.limits[id]
+	}
+	if !ok {
+		writeErr(w, "51603", "Order does not exist")
+		return
+	}
+
+	state, fillSz := "filled", o.Size
+	if o.State != "" {
+		state, fillSz = o.State, 0
+	}
+	writeOK(w, []map[string]string{{
+		"instId":    o.InstID,
+		"ordId":     o.OrdID,
+		"clOrdId":   o.ClOrdID,
+		"side":      o.Side,
+		"posSide":   o.PosSide,
+		"ordType":   o.ordType(),
+		"px":        fmtF(o.Px),
+		"sz":        fmtF(o.Size),
+		"accFillSz": fmtF(fillSz),
+		"avgPx":     fmtF(o.AvgPx),
+		"fillPx":    fmtF(o.AvgPx),
+		"fee":       fmtF(-o.Fee),
+		"feeCcy":    "USDT",
+		"state":     state,
+	}})
+}
+
+func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		InstID string `json:"instId"`
+		OrdID  string `json:"ordId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, "50000", "bad body: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lim, ok := s.limits[req.OrdID]
+	if !ok || lim.State != "live" {
+		writeOK(w, []map[string]string{{"ordId": req.OrdID, "sCode": "51400", "sMsg": "Order cancellation failed as the order has been filled, canceled or does not exist"}})
+		return
+	}
+	lim.State = "canceled"
+	writeOK(w, []map[string]string{{"ordId": req.OrdID, "sCode": "0", "sMsg": ""}})
+}
+
+// ===== /trade/order-algo, /trade/cancel-algos =====
+
+func (s *Server) handlePlaceAlgo(w http.ResponseWriter, r *http.Request) {
+	var req map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, "50000", "bad body: "+err.Error())
+		return
+	}
+	if req["ordType"] != "conditional" {
+		writeErr(w, "51000", "fake: only conditional algos supported")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.instruments[req["instId"]]; !ok {
+		writeErr(w, "51001", "Instrument ID does not exist")
+		return
+	}
+	a := &Algo{
+		AlgoID:      s.nextIDLocked(),
+		AlgoClOrdID: req["algoClOrdId"],
+		InstID:      req["instId"],
+		Side:        req["side"],
+		PosSide:     req["posSide"],
+		Size:        parseF(req["sz"]),
+		SLTriggerPx: parseF(req["slTriggerPx"]),
+		TPTriggerPx: parseF(req["tpTriggerPx"]),
+		State:       "live",
+		CreatedAt:   time.Now(),
+	}
+	if a.Size <= 0 || (a.SLTriggerPx <= 0 && a.TPTriggerPx <= 0) {
+		writeOK(w, []map[string]string{{"algoId": "", "sCode": "51000", "sMsg": "Parameter error"}})
+		return
+	}
+	s.algos[a.AlgoID] = a
+	writeOK(w, []map[string]string{{"algoId": a.AlgoID, "algoClOrdId": a.AlgoClOrdID, "sCode": "0", "sMsg": ""}})
+}
+
+func (s *Server) handleCancelAlgos(w http.ResponseWriter, r *http.Request) {
+	var req []struct {
+		InstID string `json:"instId"`
+		AlgoID string `json:"algoId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, "50000", "bad body: "+err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]map[string]string, 0, len(req))
+	for _, c := range req {
+		a, ok := s.algos[c.AlgoID]
+		if !ok || a.State != "live" {
+			out = append(out, map[string]string{"algoId": c.AlgoID, "sCode": "51400", "sMsg": "Cancellation failed as the order does not exist"})
+			continue
+		}
+		a.State = "canceled"
+		out = append(out, map[string]string{"algoId": c.AlgoID, "sCode": "0", "sMsg": ""})
+	}
+	writeOK(w, out)
+}
+
+func (s *Server) handlePendingAlgos(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live := make([]*Algo, 0, len(s.algos))
+	for _, a := range s.algos {
+		if a.State == "live" {
+			live = append(live, a)
+		}
+	}
+	// как OKX: новые первыми
+	sort.Slice(live, func(i, j int) bool {
+		ai, _ := strconv.Atoi(live[i].AlgoID)
+		aj, _ := strconv.Atoi(live[j].AlgoID)
+		return ai > aj
+	})
+
+	out := make([]map[string]string, 0, len(live))
+	for _, a := range live {
+		out = append(out, map[string]string{
+			"algoId":      a.AlgoID,
+			"algoClOrdId": a.AlgoClOrdID,
+			"instId":      a.InstID,
+			"instType":    "SWAP",
+			"ordType":     "conditional",
+			"side":        a.Side,
+			"posSide":     a.PosSide,
+			"sz":          fmtF(a.Size),
+			"slTriggerPx": fmtF(a.SLTriggerPx),
+			"tpTriggerPx": fmtF(a.TPTriggerPx),
+			"state":       "live",
+			"cTime":       strconv.FormatInt(a.CreatedAt.UnixMilli(), 10),
+		})
+	}
+	writeOK(w, out)
+}
+
+// fills-history: каждый market-ордер — одно исполнение, новые первыми.
+// Пагинация не нужна — стенд маленький, begin фильтрует по времени.
+func (s *Server) handleFills(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	begin := parseMs(r.URL.Query().Get("begin"))
+	list := make([]*Order, 0, len(s.orders))
+	for _, o := range s.orders {
+		if !o.CreatedAt.Before(begin) {
+			list = append(list, o)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		oi, _ := strconv.Atoi(list[i].OrdID)
+		oj, _ := strconv.Atoi(list[j].OrdID)
+		return oi > oj
+	})
+
+	out := make([]map[string]string, 0, len(list))
+	for _, o := range list {
+		out = append(out, map[string]string{
+			"instType": "SWAP",
+			"instId":   o.InstID,
+			"billId":   o.OrdID,
+			"tradeId":  o.OrdID,
+			"ordId":    o.OrdID,
+			"clOrdId":  o.ClOrdID,
+			"side":     o.Side,
+			"posSide":  o.PosSide,
+			"fillPx":   fmtF(o.AvgPx),
+			"fillSz":   fmtF(o.Size),
+			"fee":      fmtF(-o.Fee),
+			"feeCcy":   "USDT",
+			"fillPnl":  fmtF(o.Pnl),
+			"ts":       strconv.FormatInt(o.CreatedAt.UnixMilli(), 10),
+		})
+	}
+	writeOK(w, out)
+}
+
+// ===== /account =====
+
+func (s *Server) handleBills(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := r.URL.Query()
+	begin := parseMs(q.Get("begin"))
+	out := make([]map[string]string, 0, len(s.bills))
+	for i := len(s.bills) - 1; i >= 0; i-- {
+		b := s.bills[i]
+		if b.CreatedAt.Before(begin) || (q.Get("type") != "" && q.Get("type") != b.Type) {
+			continue
+		}
+		out = append(out, map[string]string{
+			"billId":   b.BillID,
+			"instType": "SWAP",
+			"instId":   b.InstID,
+			"type":     b.Type,
+			"balChg":   fmtF(b.BalChg),
+			"ccy":      "USDT",
+			"ts":       strconv.FormatInt(b.CreatedAt.UnixMilli(), 10),
+		})
+	}
+	writeOK(w, out)
+}
+
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]posKey, 0, len(s.positions))
+	for k, p := range s.positions {
+		if p.size > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].instID+keys[i].posSide < keys[j].instID+keys[j].posSide
+	})
+
+	out := make([]map[string]string, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, s.positionRowLocked(k))
+	}
+	writeOK(w, out)
+}
+
+// positionRowLocked — позиция в формате OKX (REST и канал positions; pos "0" — закрыта).
+func (s *Server) positionRowLocked(k posKey) map[string]string {
+	p := s.positions[k]
+	in := s.instruments[k.instID]
+	last := s.last[k.instID]
+	upl := (last - p.avgPx) * p.size * in.CtVal
+	if k.posSide == "short" {
+		upl = -upl
+	}
+	var uplRatio float64
+	if notional := p.avgPx * p.size * in.CtVal; notional > 0 && p.lever > 0 {
+		uplRatio = upl / (notional / float64(p.lever))
+	}
+	return map[string]string{
+		"instId":      k.instID,
+		"instType":    "SWAP",
+		"mgnMode":     "cross",
+		"posSide":     k.posSide,
+		"pos":         fmtF(p.size),
+		"avgPx":       fmtF(p.avgPx),
+		"last":        fmtF(last),
+		"markPx":      fmtF(last),
+		"upl":         fmtF(upl),
+		"uplRatio":    fmtF(uplRatio),
+		"lever":       strconv.Itoa(p.lever),
+		"realizedPnl": fmtF(p.realized),
+		"notionalUsd": fmtF(last * p.size * in.CtVal),
+		"cTime":       strconv.FormatInt(p.openedAt.UnixMilli(), 10),
+	}
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeOK(w, []map[string]any{s.balanceRowLocked()})
+}
+
+// balanceRowLocked — счёт в формате OKX: eq с нереализованным PnL, availEq — без.
+func (s *Server) balanceRowLocked() map[string]any {
+	eq := s.balance
+	for k, p := range s.positions {
+		if p.size <= 0 {
+			continue
+		}
+		upl := (s.last[k.instID] - p.avgPx) * p.size * s.instruments[k.instID].CtVal
+		if k.posSide == "short" {
+			upl = -upl
+		}
+		eq += upl
+	}
+	return map[string]any{
+		"totalEq": fmtF(eq),
+		"details": []map[string]string{{"ccy": "USDT", "eq": fmtF(eq), "availEq": fmtF(s.balance)}},
+	}
+}
+
+func (s *Server) handleSetLeverage(w http.ResponseWriter, r *http.Request) {
+	var req map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, "50000", "bad body: "+err.Error())
+		return
+	}
+	lever, _ := strconv.Atoi(req["lever"])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, side := range []string{"long", "short"} {
+		if req["posSide"] != "" && req["posSide"] != side {
+			continue
+		}
+		k := posKey{instID: req["instId"], posSide: side}
+		if s.positions[k] == nil {
+			s.positions[k] = &position{}
+		}
+		s.positions[k].lever = lever
+	}
+	writeOK(w, []map[string]string{{"instId": req["instId"], "lever": req["lever"], "mgnMode": "cross"}})
+}
+
+// ===== /public, /market =====
+
+func (s *Server) handleInstruments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	in, ok := s.instruments[r.URL.Query().Get("instId")]
+	if !ok {
+		writeOK(w, []any{})
+		return
+	}
+	writeOK(w, []map[string]string{{
+		"instId":    in.InstID,
+		"instType":  "SWAP",
+		"tickSz":    fmtF(in.TickSz),
+		"lotSz":     fmtF(in.LotSz),
+		"minSz":     fmtF(in.MinSz),
+		"ctVal":     fmtF(in.CtVal),
+		"ctMult":    "1",
+		"ctType":    "linear",
+		"settleCcy": "USDT",
+		"ctValCcy":  strings.SplitN(in.InstID, "-", 2)[0],
+		"state":     "live",
+	}})
+}
+
+// handleCandles — как у OKX: newest-first, confirm последним элементом.
+func (s *Server) handleCandles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > 300 {
+		limit = 100
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hist := s.history[q.Get("instId")][q.Get("bar")]
+	rows := make([][]string, 0, limit)
+	for i := len(hist) - 1; i >= 0 && len(rows) < limit; i-- {
+		rows = append(rows, candleRow(hist[i]))
+	}
+	writeOK(w, rows)
+}
+
+func (s *Server) handleTicker(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst := r.URL.Query().Get("instId")
+	last, ok := s.last[inst]
+	if !ok {
+		writeErr(w, "51001", "Instrument ID does not exist")
+		return
+	}
+	writeOK(w, []map[string]string{{"instId": inst, "last": fmtF(last)}})
+}
+
+// handleTickers — 24h high/low берём по 1m истории (или ±5% от last, если её нет).
+func (s *Server) handleTickers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]map[string]string, 0, len(s.instruments))
+	for inst := range s.instruments {
+		last := s.last[inst]
+		hi, lo := last*1.05, last*0.95
+		if hist := s.history[inst]["1m"]; len(hist) > 0 {
+			hi, lo = hist[0].High, hist[0].Low
+			for _, c := range hist {
+				if c.High > hi {
+					hi = c.High
+				}
+				if c.Low < lo {
+					lo = c.Low
+				}
+			}
+		}
+		out = append(out, map[string]string{
+			"instType": "SWAP",
+			"instId":   inst,
+			"last":     fmtF(last),
+			"high24h":  fmtF(hi),
+			"low24h":   fmtF(lo),
+		})
+	}
+	writeOK(w, out)
+}
@@ -0,0 +1,308 @@
+// Package okxfake — локальный стенд OKX v5 для e2e-прогонов без реальных денег.
+//
+// Поднимает httptest.Server с подмножеством REST (trade/account/public/market)
+// и WS (business — свечи, public — tickers, private — orders/orders-algo/positions/account). Цена двигается скриптом: Script
+// кладёт в очередь будущие 1m свечи, Step применяет по одной (срабатывают
+// SL/TP, в WS уходит закрытая свеча).
+//
+//	srv := okxfake.New()
+//	defer srv.Close()
+//	cfg.OKX.RestURL, cfg.OKX.WSURL = srv.URL(), srv.WSURL()
+package okxfake
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"trade_bot/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+// Instrument — спецификация SWAP-инструмента на стенде.
+type Instrument struct {
+	InstID string
+	TickSz float64
+	LotSz  float64
+	MinSz  float64
+	CtVal  float64
+	Last   float64 // стартовая цена
+}
+
+type Server struct {
+	http     *httptest.Server
+	upgrader websocket.Upgrader
+
+	// TakerFee — комиссия за market-исполнение (доля от notional), по умолчанию 0.0005.
+	TakerFee float64
+
+	mu          sync.Mutex
+	seq         int
+	balance     float64
+	instruments map[string]Instrument
+	last        map[string]float64
+	positions   map[posKey]*position
+	orders      map[string]*Order
+	limits      map[string]*Order // лимиты на открытие: живые и снятые без исполнения
+	algos       map[string]*Algo
+	bills       []Bill
+	history     map[string]map[string][]models.CandleTick // instId -> bar -> свечи по времени
+	scripts     map[string][]models.CandleTick            // instId -> очередь будущих 1m свечей
+	subs        map[*wsConn]struct{}
+	privOut     []privFrame // приватные пуши, уходят после Unlock (flushPrivate)
+}
+
+type posKey struct {
+	instID  string
+	posSide string
+}
+
+type position struct {
+	size     float64
+	avgPx    float64
+	lever    int
+	realized float64
+	openedAt time.Time
+}
+
+// Order — исполненный ордер; лимит до исполнения живёт в limits.
+type Order struct {
+	OrdID       string
+	ClOrdID     string
+	OrdType     string  // "" — market
+	Px          float64 // цена лимита
+	State       string  // live / canceled для лимитов, "" — исполнен
+	AlgoID      string  // ордер от сработавшего SL/TP
+	AlgoClOrdID string
+	InstID      string
+	Side        string // buy/sell
+	PosSide     string // long/short
+	Size        float64
+	AvgPx       float64
+	Fee         float64
+	Pnl         float64 // реализованный PnL закрывающего ордера, без комиссии
+	ReduceOnly  bool
+	CreatedAt   time.Time
+}
+
+// Algo — условный ордер (SL или TP).
+type Algo struct {
+	AlgoID      string
+	AlgoClOrdID string
+	InstID      string
+	Side        string
+	PosSide     string
+	Size        float64
+	SLTriggerPx float64
+	TPTriggerPx float64
+	State       string // live / effective / canceled
+	CreatedAt   time.Time
+}
+
+// Bill — движение по счёту (пока только funding, type=8).
+type Bill struct {
+	BillID    string
+	InstID    string
+	Type      string
+	BalChg    float64
+	CreatedAt time.Time
+}
+
+// New поднимает стенд с балансом 10 000 USDT.
+func New() *Server {
+	s := &Server{
+		TakerFee:    0.0005,
+		balance:     10_000,
+		instruments: make(map[string]Instrument),
+		last:        make(map[string]float64),
+		positions:   make(map[posKey]*position),
+		orders:      make(map[string]*Order),
+		limits:      make(map[string]*Order),
+		algos:       make(map[string]*Algo),
+		history:     make(map[string]map[string][]models.CandleTick),
+		scripts:     make(map[string][]models.CandleTick),
+		subs:        make(map[*wsConn]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v5/trade/order", s.handleOrder)
+	mux.HandleFunc("/api/v5/trade/cancel-order", s.private(s.handleCancelOrder))
+	mux.HandleFunc("/api/v5/trade/order-algo", s.private(s.handlePlaceAlgo))
+	mux.HandleFunc("/api/v5/trade/cancel-algos", s.private(s.handleCancelAlgos))
+	mux.HandleFunc("/api/v5/trade/orders-algo-pending", s.private(s.handlePendingAlgos))
+	mux.HandleFunc("/api/v5/trade/fills-history", s.private(s.handleFills))
+	mux.HandleFunc("/api/v5/account/positions", s.private(s.handlePositions))
+	mux.HandleFunc("/api/v5/account/bills", s.private(s.handleBills))
+	mux.HandleFunc("/api/v5/account/balance", s.private(s.handleBalance))
+	mux.HandleFunc("/api/v5/account/set-leverage", s.private(s.handleSetLeverage))
+	mux.HandleFunc("/api/v5/public/instruments", s.handleInstruments)
+	mux.HandleFunc("/api/v5/market/candles", s.handleCandles)
+	mux.HandleFunc("/api/v5/market/ticker", s.handleTicker)
+	mux.HandleFunc("/api/v5/market/tickers", s.handleTickers)
+	mux.HandleFunc("/ws/v5/business", s.handleWS)
+	mux.HandleFunc("/ws/v5/public", s.handleWS)
+	mux.HandleFunc("/ws/v5/private", s.handleWS)
+
+	s.http = httptest.NewServer(mux)
+	return s
+}
+
+func (s *Server) Close() {
+	s.mu.Lock()
+	for c := range s.subs {
+		_ = c.conn.Close()
+	}
+	s.mu.Unlock()
+	s.http.Close()
+}
+
+// URL — база для cfg.OKX.RestURL.
+func (s *Server) URL() string { return s.http.URL }
+
+// WSURL — база для cfg.OKX.WSURL.
+func (s *Server) WSURL() string { return "ws" + strings.TrimPrefix(s.http.URL, "http") }
+
+func (s *Server) AddInstrument(in Instrument) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if in.LotSz <= 0 {
+		in.LotSz = 1
+	}
+	if in.MinSz <= 0 {
+		in.MinSz = in.LotSz
+	}
+	if in.CtVal <= 0 {
+		in.CtVal = 1
+	}
+	s.instruments[in.InstID] = in
+	if in.Last > 0 {
+		s.last[in.InstID] = in.Last
+	}
+}
+
+func (s *Server) SetBalance(usdt float64) {
+	s.mu.Lock()
+	s.balance = usdt
+	s.mu.Unlock()
+}
+
+func (s *Server) Balance() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance
+}
+
+// SetHistory — история для /market/candles (bar в формате OKX: "1m", "15m", "1H").
+func (s *Server) SetHistory(instID, bar string, candles []models.CandleTick) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.history[instID] == nil {
+		s.history[instID] = make(map[string][]models.CandleTick)
+	}
+	s.history[instID][bar] = append([]models.CandleTick(nil), candles...)
+	if n := len(candles); n > 0 && bar == "1m" {
+		s.last[instID] = candles[n-1].Close
+	}
+}
+
+// Script добавляет будущие 1m свечи инструмента в очередь (см. Step).
+func (s *Server) Script(instID string, path ...models.CandleTick) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts[instID] = append(s.scripts[instID], path...)
+}
+
+// Step применяет по одной свече из скрипта каждого инструмента:
+// двигает last, исполняет сработавшие SL/TP и рассылает свечу в WS.
+// Возвращает, сколько свечей применено (0 — скрипты кончились).
+func (s *Server) Step() int {
+	s.mu.Lock()
+	var batch []models.CandleTick
+	for inst, q := range s.scripts {
+		if len(q) == 0 {
+			continue
+		}
+		c := q[0]
+		c.InstID = inst
+		s.scripts[inst] = q[1:]
+		batch = append(batch, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range batch {
+		s.PushCandle("1m", c)
+	}
+	return len(batch)
+}
+
+// PushCandle — закрытая свеча bar: в историю, в WS-подписчиков;
+// для 1m ещё и цена, лимиты и триггеры алгоритмов.
+func (s *Server) PushCandle(bar string, c models.CandleTick) {
+	s.mu.Lock()
+	if s.history[c.InstID] == nil {
+		s.history[c.InstID] = make(map[string][]models.CandleTick)
+	}
+	s.history[c.InstID][bar] = append(s.history[c.InstID][bar], c)
+	if bar == "1m" {
+		s.last[c.InstID] = c.Close
+		s.fillLimitsLocked(c)
+		s.triggerAlgosLocked(c)
+	}
+	subs := make([]*wsConn, 0, len(s.subs))
+	for conn := range s.subs {
+		subs = append(subs, conn)
+	}
+	s.mu.Unlock()
+	s.flushPrivate()
+
+	for _, conn := range subs {
+		conn.pushCandle(bar, c)
+	}
+}
+
+// Positions — снимок открытых позиций (size в контрактах).
+func (s *Server) Positions() map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]float64, len(s.positions))
+	for k, p := range s.positions {
+		if p.size > 0 {
+			out[k.instID+":"+k.posSide] = p.size
+		}
+	}
+	return out
+}
+
+// Orders — все исполненные ордера в порядке создания.
+func (s *Server) Orders() []Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Order, 0, len(s.orders))
+	for i := 1; i <= s.seq; i++ {
+		if o, ok := s.orders[strconv.Itoa(i)]; ok {
+			out = append(out, *o)
+		}
+	}
+	return out
+}
+
+// Algos — все алгоритмы (включая сработавшие и отменённые) в порядке создания.
+func (s *Server) Algos() []Algo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Algo, 0, len(s.algos))
+	for i := 1; i <= s.seq; i++ {
+		if a, ok := s.algos[strconv.Itoa(i)]; ok {
+			out = append(out, *a)
+		}
+	}
+	return out
+}
+
+func (s *Server) nextIDLocked() string {
+	s.seq++
+	return strconv.Itoa(s.seq)
+}
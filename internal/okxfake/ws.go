@@ -0,0 +1,169 @@
+package okxfake
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"trade_bot/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+type wsArg struct {
+	Channel  string `json:"channel"`
+	InstID   string `json:"instId,omitempty"`
+	InstType string `json:"instType,omitempty"` // приватные каналы
+	Ccy      string `json:"ccy,omitempty"`      // account
+}
+
+// wsConn — одно WS-подключение клиента со своими подписками.
+type wsConn struct {
+	conn *websocket.Conn
+
+	mu     sync.Mutex // gorilla: одна горутина на запись
+	subs   map[wsArg]struct{}
+	authed bool // прошёл login (/ws/v5/private)
+}
+
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &wsConn{conn: conn, subs: make(map[wsArg]struct{})}
+
+	s.mu.Lock()
+	s.subs[c] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, c)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if string(msg) == "ping" {
+			c.write([]byte("pong"))
+			continue
+		}
+
+		var req struct {
+			Op   string            `json:"op"`
+			Args []json.RawMessage `json:"args"`
+		}
+		if err := json.Unmarshal(msg, &req); err != nil {
+			continue
+		}
+		if req.Op == "login" {
+			c.login(req.Args)
+			continue
+		}
+		for _, raw := range req.Args {
+			var a wsArg
+			if err := json.Unmarshal(raw, &a); err != nil {
+				continue
+			}
+			if isPrivateChannel(a.Channel) && !c.loggedIn() {
+				c.writeEvent(map[string]any{"event": "error", "code": "60011", "msg": "Please log in"})
+				continue
+			}
+
+			c.mu.Lock()
+			switch req.Op {
+			case "subscribe":
+				c.subs[a] = struct{}{}
+			case "unsubscribe":
+				delete(c.subs, a)
+			}
+			c.mu.Unlock()
+
+			c.writeEvent(map[string]any{"event": req.Op, "arg": a})
+		}
+	}
+}
+
+// login — подпись не проверяем (как и в REST), нужен только apiKey.
+func (c *wsConn) login(args []json.RawMessage) {
+	var creds struct {
+		APIKey string `json:"apiKey"`
+	}
+	if len(args) > 0 {
+		_ = json.Unmarshal(args[0], &creds)
+	}
+	if creds.APIKey == "" {
+		c.writeEvent(map[string]any{"event": "error", "code": "60005", "msg": "Invalid apiKey"})
+		return
+	}
+	c.mu.Lock()
+	c.authed = true
+	c.mu.Unlock()
+	c.writeEvent(map[string]any{"event": "login", "code": "0", "msg": ""})
+}
+
+func (c *wsConn) loggedIn() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.authed
+}
+
+func (c *wsConn) writeEvent(ev map[string]any) {
+	b, _ := json.Marshal(ev)
+	c.write(b)
+}
+
+// pushCandle шлёт закрытую свечу подписчикам candle<bar>;
+// для 1m — ещё и tickers (last = close), как делает /ws/v5/public.
+func (c *wsConn) pushCandle(bar string, tick models.CandleTick) {
+	candle := wsArg{Channel: "candle" + bar, InstID: tick.InstID}
+	if c.subscribed(candle) {
+		frame, _ := json.Marshal(map[string]any{
+			"arg":  candle,
+			"data": [][]string{candleRow(tick)},
+		})
+		c.write(frame)
+	}
+
+	ticker := wsArg{Channel: "tickers", InstID: tick.InstID}
+	if bar == "1m" && c.subscribed(ticker) {
+		frame, _ := json.Marshal(map[string]any{
+			"arg":  ticker,
+			"data": []map[string]string{{"instId": tick.InstID, "last": fmtF(tick.Close)}},
+		})
+		c.write(frame)
+	}
+}
+
+func (c *wsConn) subscribed(a wsArg) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.subs[a]
+	return ok
+}
+
+func (c *wsConn) write(b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.conn.WriteMessage(websocket.TextMessage, b)
+}
+
+// candleRow — строка OKX: [ts, o, h, l, c, vol, volCcy, volCcyQuote, confirm].
+func candleRow(c models.CandleTick) []string {
+	return []string{
+		strconv.FormatInt(c.Start.UnixMilli(), 10),
+		fmtF(c.Open),
+		fmtF(c.High),
+		fmtF(c.Low),
+		fmtF(c.Close),
+		fmtF(c.Volume),
+		fmtF(c.Volume),
+		fmtF(c.QuoteVolume),
+		"1",
+	}
+}
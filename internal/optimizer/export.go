@@ -0,0 +1,163 @@
+package optimizer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strconv"
+	"time"
+	"trade_bot/internal/backtest"
+)
+
+type jsonRun struct {
+	Params map[string]float64 `json:"params"`
+	Stats  backtest.Stats     `json:"stats"`
+	Score  float64            `json:"score"`
+}
+
+type jsonFold struct {
+	N         int       `json:"n"`
+	TrainFrom time.Time `json:"train_from"`
+	TrainTo   time.Time `json:"train_to"`
+	TestFrom  time.Time `json:"test_from"`
+	TestTo    time.Time `json:"test_to"`
+	NoValid   bool      `json:"no_valid,omitempty"`
+	Best      *jsonRun  `json:"best,omitempty"`
+	TrainTop  []jsonRun `json:"train_top"`
+	Test      *jsonTest `json:"test,omitempty"`
+}
+
+type jsonTest struct {
+	Stats backtest.Stats `json:"stats"`
+	Score float64        `json:"score"`
+}
+
+func toJSONRun(r Run) jsonRun {
+	return jsonRun{Params: r.Params.Map(), Stats: finiteStats(r.Stats), Score: finite(r.Score)}
+}
+
+// JSON не умеет ±Inf: profit factor без убыточных сделок, score ниже min-trades
+func finiteStats(s backtest.Stats) backtest.Stats {
+	s.ProfitFactor = finite(s.ProfitFactor)
+	return s
+}
+
+// WriteJSON — весь отчёт: ranking или folds + сводка out-of-sample.
+func WriteJSON(path string, rep Report) error {
+	out := struct {
+		Objective Objective       `json:"objective"`
+		Ranking   []jsonRun       `json:"ranking,omitempty"`
+		Folds     []jsonFold      `json:"folds,omitempty"`
+		OOS       *backtest.Stats `json:"oos,omitempty"`
+	}{Objective: rep.Objective}
+
+	for _, r := range rep.Ranking {
+		out.Ranking = append(out.Ranking, toJSONRun(r))
+	}
+	for _, f := range rep.Folds {
+		jf := jsonFold{
+			N: f.N, TrainFrom: f.TrainFrom, TrainTo: f.TrainTo, TestFrom: f.TestFrom, TestTo: f.TestTo,
+			NoValid: f.NoValid,
+		}
+		for _, r := range f.TrainTop {
+			jf.TrainTop = append(jf.TrainTop, toJSONRun(r))
+		}
+		if !f.NoValid {
+			best := toJSONRun(f.Best)
+			jf.Best = &best
+			jf.Test = &jsonTest{Stats: finiteStats(f.Test), Score: finite(f.TestScore)}
+		}
+		out.Folds = append(out.Folds, jf)
+	}
+	if len(rep.Folds) > 0 {
+		oos := finiteStats(rep.OOS)
+		out.OOS = &oos
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// WriteCSV — строка на комбинацию (без folds) или на окно walk-forward.
+func WriteCSV(path string, rep Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	ff := func(v float64) string { return strconv.FormatFloat(finite(v), 'f', -1, 64) }
+	stats := func(s backtest.Stats) []string {
+		return []string{
+			strconv.Itoa(s.Trades), ff(s.WinRate), ff(s.ExpectancyR), ff(s.TotalR),
+			ff(s.ProfitFactor), ff(s.MaxDrawdownR), ff(s.SharpeR),
+		}
+	}
+	statsHdr := []string{"trades", "win_rate", "expectancy_r", "total_r", "profit_factor", "max_dd_r", "sharpe_r"}
+
+	var keys []string
+	switch {
+	case len(rep.Ranking) > 0:
+		keys = rep.Ranking[0].Params.Keys
+	case len(rep.Folds) > 0 && len(rep.Folds[0].TrainTop) > 0:
+		keys = rep.Folds[0].TrainTop[0].Params.Keys
+	}
+
+	if len(rep.Folds) == 0 {
+		_ = w.Write(append(append(append([]string{}, keys...), statsHdr...), "score"))
+		for _, r := range rep.Ranking {
+			row := make([]string, 0, len(keys)+len(statsHdr)+1)
+			for _, v := range r.Params.Values {
+				row = append(row, ff(v))
+			}
+			row = append(row, stats(r.Stats)...)
+			_ = w.Write(append(row, ff(r.Score)))
+		}
+	} else {
+		hdr := []string{"fold", "train_from", "train_to", "test_from", "test_to"}
+		hdr = append(hdr, keys...)
+		hdr = append(hdr, "train_score")
+		for _, h := range statsHdr {
+			hdr = append(hdr, "test_"+h)
+		}
+		_ = w.Write(append(hdr, "test_score", "note"))
+
+		day := "2006-01-02"
+		for _, fo := range rep.Folds {
+			row := []string{
+				strconv.Itoa(fo.N),
+				fo.TrainFrom.UTC().Format(day), fo.TrainTo.UTC().Format(day),
+				fo.TestFrom.UTC().Format(day), fo.TestTo.UTC().Format(day),
+			}
+			if fo.NoValid {
+				// пустые параметры и test — только пометка
+				row = append(row, make([]string, len(keys)+1+len(statsHdr)+1)...)
+				_ = w.Write(append(row, "no valid params"))
+				continue
+			}
+			for _, v := range fo.Best.Params.Values {
+				row = append(row, ff(v))
+			}
+			row = append(row, ff(fo.Best.Score))
+			row = append(row, stats(fo.Test)...)
+			_ = w.Write(append(row, ff(fo.TestScore), ""))
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func finite(v float64) float64 {
+	switch {
+	case v > 1e300:
+		return 1e300
+	case v < -1e300:
+		return -1e300
+	}
+	return v
+}
@@ -0,0 +1,171 @@
+// Package optimizer — перебор параметров стратегии и трейлинга поверх backtest.
+//
+// Параметры адресуются yaml-тегами: strategy.<tag> — поле config.StrategyConfig,
+// trailing.<tag> — поле models.TrailingConfig. Например:
+//
+//	strategy.donchian_period=10:40:5
+//	trailing.be_trigger_r=0.4,0.6,0.8
+//	trailing.partial_enabled=0,1
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+)
+
+// Range — значения одного параметра.
+type Range struct {
+	Key    string // strategy.donchian_period
+	Values []float64
+}
+
+// ParseRange: "key=from:to:step" или "key=v1,v2,v3".
+func ParseRange(s string) (Range, error) {
+	key, spec, ok := strings.Cut(s, "=")
+	if !ok {
+		return Range{}, fmt.Errorf("range %q: want key=from:to:step or key=v1,v2", s)
+	}
+	r := Range{Key: strings.TrimSpace(key)}
+	if _, err := field(new(config.Config), new(models.Settings), r.Key); err != nil {
+		return Range{}, err
+	}
+
+	if parts := strings.Split(spec, ":"); len(parts) == 3 {
+		var v [3]float64
+		for i, p := range parts {
+			f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				return Range{}, fmt.Errorf("range %q: %w", s, err)
+			}
+			v[i] = f
+		}
+		from, to, step := v[0], v[1], v[2]
+		if step <= 0 || to < from {
+			return Range{}, fmt.Errorf("range %q: need from <= to and step > 0", s)
+		}
+		// шаг считаем от индекса, чтобы не копить ошибку float
+		for i := 0; ; i++ {
+			x := from + float64(i)*step
+			if x > to+step*1e-9 {
+				break
+			}
+			r.Values = append(r.Values, math.Round(x*1e9)/1e9)
+		}
+		return r, nil
+	}
+
+	for _, p := range strings.Split(spec, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return Range{}, fmt.Errorf("range %q: %w", s, err)
+		}
+		r.Values = append(r.Values, f)
+	}
+	return r, nil
+}
+
+// Point — одна комбинация: значения в порядке Keys.
+type Point struct {
+	Keys   []string
+	Values []float64
+}
+
+func (p Point) String() string {
+	parts := make([]string, len(p.Keys))
+	for i, k := range p.Keys {
+		parts[i] = k + "=" + strconv.FormatFloat(p.Values[i], 'f', -1, 64)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Map — для JSON.
+func (p Point) Map() map[string]float64 {
+	m := make(map[string]float64, len(p.Keys))
+	for i, k := range p.Keys {
+		m[k] = p.Values[i]
+	}
+	return m
+}
+
+// Grid — декартово произведение диапазонов.
+func Grid(ranges []Range) []Point {
+	keys := make([]string, len(ranges))
+	for i, r := range ranges {
+		keys[i] = r.Key
+	}
+
+	out := []Point{{Keys: keys}}
+	for _, r := range ranges {
+		next := make([]Point, 0, len(out)*len(r.Values))
+		for _, p := range out {
+			for _, v := range r.Values {
+				vals := append(append([]float64(nil), p.Values...), v)
+				next = append(next, Point{Keys: keys, Values: vals})
+			}
+		}
+		out = next
+	}
+	return out
+}
+
+// Apply пишет значения точки в копии cfg.Strategy и set.TrailingConfig.
+func Apply(cfg *config.Config, set *models.Settings, p Point) error {
+	for i, k := range p.Keys {
+		f, err := field(cfg, set, k)
+		if err != nil {
+			return err
+		}
+		v := p.Values[i]
+		switch f.Kind() {
+		case reflect.Int, reflect.Int64:
+			f.SetInt(int64(math.Round(v)))
+		case reflect.Float64:
+			f.SetFloat(v)
+		case reflect.Bool:
+			f.SetBool(v != 0)
+		default:
+			return fmt.Errorf("%s: unsupported type %s", k, f.Type())
+		}
+	}
+	return nil
+}
+
+func field(cfg *config.Config, set *models.Settings, key string) (reflect.Value, error) {
+	group, tag, ok := strings.Cut(key, ".")
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("key %q: want strategy.<yaml> or trailing.<yaml>", key)
+	}
+
+	var v reflect.Value
+	switch group {
+	case "strategy":
+		v = reflect.ValueOf(&cfg.Strategy).Elem()
+	case "trailing":
+		v = reflect.ValueOf(&set.TrailingConfig).Elem()
+	default:
+		return reflect.Value{}, fmt.Errorf("key %q: unknown group %q", key, group)
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != tag {
+			continue
+		}
+		ft := t.Field(i).Type
+		if ft != reflect.TypeOf(time.Duration(0)) {
+			switch ft.Kind() {
+			case reflect.Int, reflect.Int64, reflect.Float64, reflect.Bool:
+				return v.Field(i), nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("key %q: field %s is %s, not a number", key, t.Field(i).Name, ft)
+	}
+	return reflect.Value{}, fmt.Errorf("key %q: no such field", key)
+}
@@ -0,0 +1,93 @@
+package optimizer
+
+import (
+	"slices"
+	"testing"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		key     string
+		want    []float64
+		wantErr bool
+	}{
+		{in: "strategy.donchian_period=10:40:10", key: "strategy.donchian_period", want: []float64{10, 20, 30, 40}},
+		// шаг от индекса: 0.1+0.1+0.1 не даёт 0.30000000000000004
+		{in: "trailing.be_trigger_r=0.1:0.3:0.1", key: "trailing.be_trigger_r", want: []float64{0.1, 0.2, 0.3}},
+		{in: "strategy.donchian_period=10:25:10", key: "strategy.donchian_period", want: []float64{10, 20}},
+		{in: " trailing.partial_enabled = 0, 1", key: "trailing.partial_enabled", want: []float64{0, 1}},
+		{in: "strategy.min_body_pct=0.2", key: "strategy.min_body_pct", want: []float64{0.2}},
+
+		{in: "strategy.donchian_period", wantErr: true},
+		{in: "donchian_period=10", wantErr: true},
+		{in: "strategy.no_such=1", wantErr: true},
+		{in: "paper.slippage_pct=1", wantErr: true},
+		{in: "strategy.ltf=1,2", wantErr: true},            // строка
+		{in: "strategy.progress_every=1,2", wantErr: true}, // Duration
+		{in: "strategy.donchian_period=10:40:0", wantErr: true},
+		{in: "strategy.donchian_period=40:10:5", wantErr: true},
+		{in: "strategy.donchian_period=10:x:5", wantErr: true},
+		{in: "strategy.donchian_period=10,x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r, err := ParseRange(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", r)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Key != tt.key || !slices.Equal(r.Values, tt.want) {
+				t.Fatalf("got %s=%v, want %s=%v", r.Key, r.Values, tt.key, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrid(t *testing.T) {
+	pts := Grid([]Range{
+		{Key: "strategy.donchian_period", Values: []float64{10, 20}},
+		{Key: "trailing.be_trigger_r", Values: []float64{0.4, 0.6, 0.8}},
+	})
+	want := []string{
+		"strategy.donchian_period=10 trailing.be_trigger_r=0.4",
+		"strategy.donchian_period=10 trailing.be_trigger_r=0.6",
+		"strategy.donchian_period=10 trailing.be_trigger_r=0.8",
+		"strategy.donchian_period=20 trailing.be_trigger_r=0.4",
+		"strategy.donchian_period=20 trailing.be_trigger_r=0.6",
+		"strategy.donchian_period=20 trailing.be_trigger_r=0.8",
+	}
+	got := make([]string, len(pts))
+	for i, p := range pts {
+		got[i] = p.String()
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("grid:\n%v\nwant:\n%v", got, want)
+	}
+
+	// без диапазонов — одна точка с базовыми параметрами
+	if pts := Grid(nil); len(pts) != 1 || len(pts[0].Values) != 0 {
+		t.Fatalf("Grid(nil) = %+v", pts)
+	}
+}
+
+func TestApply(t *testing.T) {
+	cfg, set := &config.Config{}, &models.Settings{}
+	p := Point{
+		Keys:   []string{"strategy.donchian_period", "trailing.be_trigger_r", "trailing.partial_enabled"},
+		Values: []float64{19.6, 0.7, 1},
+	}
+	if err := Apply(cfg, set, p); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Strategy.DonchianPeriod != 20 || set.TrailingConfig.BETriggerR != 0.7 || !set.TrailingConfig.PartialEnabled {
+		t.Fatalf("applied: period=%d be=%v partial=%t", cfg.Strategy.DonchianPeriod, set.TrailingConfig.BETriggerR, set.TrailingConfig.PartialEnabled)
+	}
+}
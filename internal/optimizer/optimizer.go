@@ -0,0 +1,199 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+	"trade_bot/internal/backtest"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+)
+
+// Objective — чем ранжируем комбинации.
+type Objective string
+
+const (
+	ObjExpectancy Objective = "expectancy" // средний R на сделку
+	ObjSharpe     Objective = "sharpe"     // mean/std R сделок
+	ObjMAR        Objective = "mar"        // total R / max drawdown R
+)
+
+func ParseObjective(s string) (Objective, error) {
+	switch o := Objective(s); o {
+	case ObjExpectancy, ObjSharpe, ObjMAR:
+		return o, nil
+	}
+	return "", fmt.Errorf("unknown objective %q (expectancy|sharpe|mar)", s)
+}
+
+// Score — больше = лучше. Меньше minTrades сделок — -Inf, чтобы редкие
+// удачные комбинации не выигрывали на шуме.
+func (o Objective) Score(s backtest.Stats, minTrades int) float64 {
+	if s.Trades == 0 || s.Trades < minTrades {
+		return math.Inf(-1)
+	}
+	switch o {
+	case ObjSharpe:
+		return s.SharpeR
+	case ObjMAR:
+		if s.MaxDrawdownR <= 0 {
+			return s.TotalR
+		}
+		return s.TotalR / s.MaxDrawdownR
+	default:
+		return s.ExpectancyR
+	}
+}
+
+type Options struct {
+	Ranges    []Range
+	Objective Objective
+	MinTrades int
+	Workers   int // 0 -> NumCPU
+	Folds     int // walk-forward: 0/1 — один прогон на всём периоде
+}
+
+// Run — одна комбинация на одном окне.
+type Run struct {
+	Params Point
+	Stats  backtest.Stats
+	Score  float64
+}
+
+// Fold — окно walk-forward: лучшая комбинация на train и её результат на test.
+// NoValid — ни одна комбинация не набрала MinTrades на train: Best пустой,
+// test не гоняем (в out-of-sample окно не входит).
+type Fold struct {
+	N          int
+	TrainFrom  time.Time
+	TrainTo    time.Time
+	TestFrom   time.Time
+	TestTo     time.Time
+	NoValid    bool
+	Best       Run
+	Test       backtest.Stats
+	TestScore  float64
+	TrainTop   []Run
+	TestTrades []backtest.Trade
+}
+
+type Report struct {
+	Objective Objective
+	Ranking   []Run  // без folds: все комбинации по убыванию Score
+	Folds     []Fold // с folds
+	OOS       backtest.Stats
+}
+
+// Optimize перебирает сетку на свечах [from, to). Свечи до from — прогрев.
+// С Folds > 1 период режется на Folds+1 равных кусков: train = кусок k,
+// test = кусок k+1 (rolling), итог — склейка out-of-sample сделок.
+func Optimize(ctx context.Context, cfg *config.Config, base backtest.Options, candles []models.CandleTick, from, to time.Time, opt Options) (Report, error) {
+	points := Grid(opt.Ranges)
+	if len(points) == 0 {
+		return Report{}, fmt.Errorf("empty grid")
+	}
+	// проверяем ключи заранее, а не в воркерах
+	{
+		c, s := *cfg, base.Settings
+		if err := Apply(&c, &s, points[0]); err != nil {
+			return Report{}, err
+		}
+	}
+
+	sorted := backtest.Sorted(candles)
+	rep := Report{Objective: opt.Objective}
+
+	if opt.Folds <= 1 {
+		runs := sweep(ctx, cfg, base, sorted, from, to, points, opt)
+		rep.Ranking = runs
+		return rep, ctx.Err()
+	}
+
+	step := to.Sub(from) / time.Duration(opt.Folds+1)
+	var oos []backtest.Trade
+	for k := 0; k < opt.Folds; k++ {
+		f := Fold{
+			N:         k + 1,
+			TrainFrom: from.Add(time.Duration(k) * step),
+			TrainTo:   from.Add(time.Duration(k+1) * step),
+			TestFrom:  from.Add(time.Duration(k+1) * step),
+			TestTo:    from.Add(time.Duration(k+2) * step),
+		}
+
+		runs := sweep(ctx, cfg, base, sorted, f.TrainFrom, f.TrainTo, points, opt)
+		if ctx.Err() != nil {
+			return rep, ctx.Err()
+		}
+		f.TrainTop = runs[:min(len(runs), 10)]
+		if math.IsInf(runs[0].Score, -1) {
+			f.NoValid = true
+			rep.Folds = append(rep.Folds, f)
+			continue
+		}
+		f.Best = runs[0]
+
+		res := runOne(cfg, base, sorted, f.TestFrom, f.TestTo, f.Best.Params)
+		f.Test = res.Stats
+		f.TestScore = opt.Objective.Score(res.Stats, 0)
+		f.TestTrades = res.Trades
+		oos = append(oos, res.Trades...)
+
+		rep.Folds = append(rep.Folds, f)
+	}
+	rep.OOS = backtest.CalcStats(oos)
+	return rep, nil
+}
+
+func sweep(ctx context.Context, cfg *config.Config, base backtest.Options, sorted []models.CandleTick, from, to time.Time, points []Point, opt Options) []Run {
+	workers := opt.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	runs := make([]Run, len(points))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res := runOne(cfg, base, sorted, from, to, points[i])
+				runs[i] = Run{
+					Params: points[i],
+					Stats:  res.Stats,
+					Score:  opt.Objective.Score(res.Stats, opt.MinTrades),
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range points {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Score > runs[j].Score })
+	return runs
+}
+
+// runOne — бэктест одной точки на окне [from, to): свечи до to, сделки с from.
+func runOne(cfg *config.Config, base backtest.Options, sorted []models.CandleTick, from, to time.Time, p Point) backtest.Result {
+	c := *cfg
+	opt := base
+	opt.From = from
+	_ = Apply(&c, &opt.Settings, p)
+
+	n := sort.Search(len(sorted), func(i int) bool { return !sorted[i].End.Before(to) })
+	return backtest.Run(&c, opt, sorted[:n])
+}
@@ -6,8 +6,8 @@ import (
 	"strings"
 	"sync"
 	"trade_bot/internal/models"
-	"trade_bot/internal/modules/okx_client/service"
 	okx_websocket "trade_bot/internal/modules/okx_websocket/service"
+	"trade_bot/internal/runner/sessions"
 )
 
 type SettingsStore interface {
@@ -20,11 +20,13 @@ type Manager struct {
 	mu      sync.Mutex
 	runners map[int64]*Runner
 	mkt     *okx_websocket.Client
+	mx      sessions.ExchangeFactory
 }
 
-func NewManager(mkt *okx_websocket.Client) *Manager {
+func NewManager(mkt *okx_websocket.Client, mx sessions.ExchangeFactory) *Manager {
 	return &Manager{
 		mkt:     mkt,
+		mx:      mx,
 		runners: make(map[int64]*Runner),
 	}
 }
@@ -48,9 +50,8 @@ func (m *Manager) StopForUser(ctx context.Context, user *models.UserSettings) er
 }
 
 func (m *Manager) StatusForUser(ctx context.Context, user *models.UserSettings) (string, error) {
-	// тут либо переиспользуешь существующий exchange.Client,
-	// либо создаёшь временный
-	mx := service.NewClient(user) // подставь свой конструктор
+	// временный клиент той биржи, что выбрана у юзера
+	mx := m.mx(user)
 
 	positions, err := mx.OpenPositions(ctx)
 	if err != nil {
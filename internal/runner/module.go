@@ -5,7 +5,11 @@ import (
 	"time"
 	"trade_bot/internal/helper"
 	"trade_bot/internal/models"
+	paper "trade_bot/internal/modules/paper/service"
+	strategy "trade_bot/internal/modules/strategy/service"
+	"trade_bot/internal/runner/pg"
 	"trade_bot/internal/runner/router"
+	"trade_bot/internal/runner/sessions"
 
 	"go.uber.org/fx"
 )
@@ -13,13 +17,33 @@ import (
 func Module() fx.Option {
 	return fx.Module("runner",
 		fx.Provide(
-			router.NewRouter, // *Router
+			router.NewExchangeFactory, // sessions.ExchangeFactory
+			router.NewRouter,          // *Router
+			pg.NewTrailStore,          // *pg.TrailStore
+			func(s *pg.TrailStore) sessions.TrailStore {
+				return s
+			},
+			pg.NewRiskStore, // *pg.RiskStore
+			func(s *pg.RiskStore) sessions.RiskStore {
+				return s
+			},
+			pg.NewJournal, // *pg.Journal
+			func(j *pg.Journal) sessions.Journal {
+				return j
+			},
+			func(c *strategy.Correlation) sessions.CorrelationSource {
+				return c
+			},
+			func(a *strategy.ATR) sessions.ATRSource {
+				return a
+			},
 		),
 		fx.Invoke(func(
 			lc fx.Lifecycle,
 			r *router.Router,
 			sigs chan models.Signal, // ⬅️ read-only
 			candles chan models.CandleTick, // канал для стопов
+			pe *paper.Engine,
 		) {
 			lc.Append(fx.Hook{
 				OnStart: func(startCtx context.Context) error {
@@ -61,6 +85,8 @@ func Module() fx.Option {
 								if helper.NormTF(ct.TimeframeRaw) != "1m" {
 									continue
 								}
+								// paper-счета: цена и SL/TP до трейлинга
+								pe.OnCandle(ct)
 								agg.Put(ct)
 							}
 						}
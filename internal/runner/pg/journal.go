@@ -0,0 +1,163 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trade_bot/internal/models"
+	"trade_bot/internal/runner/pg/trades"
+	"trade_bot/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Journal — журнал сделок и их событий, реализует sessions.Journal.
+type Journal struct {
+	db     *db.PgTxManager
+	trades *trades.Trades
+}
+
+// NewJournal instance
+func NewJournal(db *db.PgTxManager) *Journal {
+	return &Journal{
+		db:     db,
+		trades: trades.New(),
+	}
+}
+
+// CreateTrade — новая запись по сигналу, возвращает id
+func (j *Journal) CreateTrade(
+	ctx context.Context,
+	tr *models.Trade,
+) (id int64, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.CreateTrade: %w", err)
+		}
+	}()
+	err = j.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			id, err = j.trades.Insert(ctx, tx, tr)
+			return err
+		})
+	return id, err
+}
+
+// SetStatus in db
+func (j *Journal) SetStatus(
+	ctx context.Context,
+	tradeID int64,
+	status models.TradeStatus,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.SetStatus: %w", err)
+		}
+	}()
+	return j.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return j.trades.SetStatus(ctx, tx, tradeID, status)
+		})
+}
+
+// SetOpened — вход исполнен: параметры, ордера, цена входа
+func (j *Journal) SetOpened(
+	ctx context.Context,
+	tr *models.Trade,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.SetOpened: %w", err)
+		}
+	}()
+	return j.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return j.trades.SetOpened(ctx, tx, tr)
+		})
+}
+
+// SetClosed — выход: цена, причина, PnL
+func (j *Journal) SetClosed(
+	ctx context.Context,
+	tr *models.Trade,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.SetClosed: %w", err)
+		}
+	}()
+	return j.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return j.trades.SetClosed(ctx, tx, tr)
+		})
+}
+
+// AddEvent in db
+func (j *Journal) AddEvent(
+	ctx context.Context,
+	ev *models.TradeEvent,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.AddEvent: %w", err)
+		}
+	}()
+	return j.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return j.trades.InsertEvent(ctx, tx, ev)
+		})
+}
+
+// Unsettled — закрытые с since сделки, ещё не сверенные с исполнениями биржи
+func (j *Journal) Unsettled(
+	ctx context.Context,
+	userID int64,
+	since time.Time,
+) (res []models.Trade, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.Unsettled: %w", err)
+		}
+	}()
+	err = j.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			res, err = j.trades.ListUnsettled(ctx, tx, userID, since)
+			return err
+		})
+	return res, err
+}
+
+// SetRealized — итог сверки: реальный PnL, комиссии, funding
+func (j *Journal) SetRealized(
+	ctx context.Context,
+	tr *models.Trade,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.SetRealized: %w", err)
+		}
+	}()
+	return j.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return j.trades.SetRealized(ctx, tx, tr)
+		})
+}
+
+// Totals — сумма закрытых сделок с since
+func (j *Journal) Totals(
+	ctx context.Context,
+	userID int64,
+	since time.Time,
+) (res models.PnLTotals, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.Totals: %w", err)
+		}
+	}()
+	err = j.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			res, err = j.trades.Totals(ctx, tx, userID, since)
+			return err
+		})
+	return res, err
+}
@@ -0,0 +1,60 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"trade_bot/internal/models"
+	"trade_bot/internal/runner/pg/risk_state"
+	"trade_bot/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RiskStore — риск-счётчики юзеров, реализует sessions.RiskStore.
+type RiskStore struct {
+	db   *db.PgTxManager
+	risk *risk_state.RiskState
+}
+
+// NewRiskStore instance
+func NewRiskStore(db *db.PgTxManager) *RiskStore {
+	return &RiskStore{
+		db:   db,
+		risk: risk_state.New(),
+	}
+}
+
+// SaveRisk upsert по юзеру
+func (s *RiskStore) SaveRisk(
+	ctx context.Context,
+	userID int64,
+	st *models.RiskState,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.SaveRisk: %w", err)
+		}
+	}()
+	return s.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return s.risk.Upsert(ctx, tx, userID, st)
+		})
+}
+
+// LoadRisk — сохранённые счётчики юзера, nil если их нет
+func (s *RiskStore) LoadRisk(
+	ctx context.Context,
+	userID int64,
+) (st *models.RiskState, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.LoadRisk: %w", err)
+		}
+	}()
+	err = s.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			st, err = s.risk.Get(ctx, tx, userID)
+			return err
+		})
+	return st, err
+}
@@ -0,0 +1,64 @@
+package risk_state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"trade_bot/internal/models"
+	"trade_bot/internal/runner/pg/risk_state/sql"
+
+	"github.com/bytedance/sonic"
+	"github.com/jackc/pgx/v5"
+)
+
+// RiskState implement db store
+type RiskState struct {
+	sql *sql.Queries
+}
+
+// New instance
+func New() *RiskState {
+	return &RiskState{
+		sql: sql.New(),
+	}
+}
+
+func (r *RiskState) Upsert(ctx context.Context, tx pgx.Tx, userID int64, st *models.RiskState) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("RiskState.Upsert: %w", err)
+		}
+	}()
+
+	var data []byte
+	data, err = sonic.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return r.sql.Upsert(ctx, tx, &sql.UpsertParams{
+		Chatid: userID,
+		State:  data,
+	})
+}
+
+// Get — nil без ошибки, если состояния ещё нет
+func (r *RiskState) Get(ctx context.Context, tx pgx.Tx, userID int64) (st *models.RiskState, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("RiskState.Get: %w", err)
+		}
+	}()
+	data, err := r.sql.GetByChat(ctx, tx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	st = &models.RiskState{}
+	if err = sonic.Unmarshal(data, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
@@ -0,0 +1,12 @@
+-- name: Upsert :exec
+INSERT INTO risk_state (
+    chatid, state, updated_at
+) VALUES (
+             @chatid, @state, now()
+         )
+ON CONFLICT (chatid)
+DO UPDATE SET state = EXCLUDED.state, updated_at = now();
+
+
+-- name: GetByChat :one
+SELECT state FROM risk_state WHERE chatid = @chatid;
@@ -0,0 +1,63 @@
+-- name: InsertTrade :one
+INSERT INTO trades (
+    chatid, inst_id, side, strategy, tf, signal_reason, signal_price, status
+) VALUES (
+             @chatid, @inst_id, @side, @strategy, @tf, @signal_reason, @signal_price, @status
+         ) returning id;
+
+
+-- name: SetStatus :exec
+UPDATE trades
+SET status = @status
+WHERE id = @id;
+
+
+-- name: SetOpened :exec
+UPDATE trades
+SET status = 'open', pos_side = @pos_side, params = @params, order_id = @order_id,
+    entry_order_ids = @entry_order_ids,
+    sl_algo_id = @sl_algo_id, tp_algo_id = @tp_algo_id, entry_price = @entry_price,
+    opened_at = @opened_at::timestamptz
+WHERE id = @id;
+
+
+-- name: SetClosed :exec
+UPDATE trades
+SET status = 'closed', exit_price = @exit_price, exit_reason = @exit_reason,
+    pnl_usdt = @pnl_usdt, pnl_r = @pnl_r, closed_at = @closed_at::timestamptz
+WHERE id = @id;
+
+
+-- name: InsertEvent :exec
+INSERT INTO trade_events (
+    trade_id, kind, data
+) VALUES (
+             @trade_id, @kind, @data
+         );
+
+
+-- name: ListUnsettled :many
+SELECT id, inst_id, pos_side, order_id, entry_order_ids, params, exit_price, pnl_r, opened_at, closed_at
+FROM trades
+WHERE chatid = @chatid AND status = 'closed' AND settled_at IS NULL AND closed_at >= @since::timestamptz
+ORDER BY closed_at;
+
+
+-- name: SetRealized :exec
+UPDATE trades
+SET exit_price = @exit_price, pnl_usdt = @pnl_usdt, pnl_r = @pnl_r,
+    gross_usdt = @gross_usdt, fee_usdt = @fee_usdt, funding_usdt = @funding_usdt,
+    settled_at = now()
+WHERE id = @id;
+
+
+-- name: Totals :one
+SELECT count(*)::bigint                                  AS trades,
+       count(*) FILTER (WHERE pnl_usdt > 0)::bigint      AS wins,
+       coalesce(sum(pnl_usdt), 0)::double precision     AS pnl_usdt,
+       coalesce(sum(pnl_r), 0)::double precision        AS pnl_r,
+       coalesce(sum(fee_usdt), 0)::double precision     AS fee_usdt,
+       coalesce(sum(funding_usdt), 0)::double precision AS funding_usdt,
+       count(*) FILTER (WHERE settled_at IS NULL)::bigint AS unsettled
+FROM trades
+WHERE chatid = @chatid AND status = 'closed' AND closed_at >= @since::timestamptz;
@@ -0,0 +1,205 @@
+package trades
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trade_bot/internal/models"
+	"trade_bot/internal/runner/pg/trades/sql"
+
+	"github.com/bytedance/sonic"
+	"github.com/jackc/pgx/v5"
+)
+
+// Trades implement db store
+type Trades struct {
+	sql *sql.Queries
+}
+
+// New instance
+func New() *Trades {
+	return &Trades{
+		sql: sql.New(),
+	}
+}
+
+func (t *Trades) Insert(ctx context.Context, tx pgx.Tx, tr *models.Trade) (id int64, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Trades.Insert: %w", err)
+		}
+	}()
+	return t.sql.InsertTrade(ctx, tx, &sql.InsertTradeParams{
+		Chatid:       tr.UserID,
+		InstID:       tr.InstID,
+		Side:         string(tr.Side),
+		Strategy:     string(tr.Strategy),
+		Tf:           tr.TF,
+		SignalReason: tr.Reason,
+		SignalPrice:  tr.SignalPrice,
+		Status:       string(tr.Status),
+	})
+}
+
+func (t *Trades) SetStatus(ctx context.Context, tx pgx.Tx, id int64, status models.TradeStatus) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Trades.SetStatus: %w", err)
+		}
+	}()
+	return t.sql.SetStatus(ctx, tx, &sql.SetStatusParams{
+		ID:     id,
+		Status: string(status),
+	})
+}
+
+func (t *Trades) SetOpened(ctx context.Context, tx pgx.Tx, tr *models.Trade) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Trades.SetOpened: %w", err)
+		}
+	}()
+
+	params := []byte("{}")
+	if tr.Params != nil {
+		params, err = sonic.Marshal(tr.Params)
+		if err != nil {
+			return err
+		}
+	}
+	return t.sql.SetOpened(ctx, tx, &sql.SetOpenedParams{
+		ID:            tr.ID,
+		PosSide:       tr.PosSide,
+		Params:        params,
+		OrderID:       tr.OrderID,
+		EntryOrderIds: tr.EntryOrderIDs,
+		SlAlgoID:      tr.SLAlgoID,
+		TpAlgoID:      tr.TPAlgoID,
+		EntryPrice:    tr.EntryPrice,
+		OpenedAt:      tr.OpenedAt,
+	})
+}
+
+func (t *Trades) SetClosed(ctx context.Context, tx pgx.Tx, tr *models.Trade) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Trades.SetClosed: %w", err)
+		}
+	}()
+	return t.sql.SetClosed(ctx, tx, &sql.SetClosedParams{
+		ID:         tr.ID,
+		ExitPrice:  tr.ExitPrice,
+		ExitReason: tr.ExitReason,
+		PnlUsdt:    tr.PnlUSDT,
+		PnlR:       tr.PnlR,
+		ClosedAt:   tr.ClosedAt,
+	})
+}
+
+func (t *Trades) InsertEvent(ctx context.Context, tx pgx.Tx, ev *models.TradeEvent) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Trades.InsertEvent: %w", err)
+		}
+	}()
+
+	data := []byte("{}")
+	if ev.Data != nil {
+		data, err = sonic.Marshal(ev.Data)
+		if err != nil {
+			return err
+		}
+	}
+	return t.sql.InsertEvent(ctx, tx, &sql.InsertEventParams{
+		TradeID: ev.TradeID,
+		Kind:    string(ev.Kind),
+		Data:    data,
+	})
+}
+
+func (t *Trades) ListUnsettled(ctx context.Context, tx pgx.Tx, userID int64, since time.Time) (res []models.Trade, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Trades.ListUnsettled: %w", err)
+		}
+	}()
+	rows, err := t.sql.ListUnsettled(ctx, tx, &sql.ListUnsettledParams{
+		Chatid: userID,
+		Since:  since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res = make([]models.Trade, 0, len(rows))
+	for _, r := range rows {
+		tr := models.Trade{
+			ID:            r.ID,
+			UserID:        userID,
+			InstID:        r.InstID,
+			PosSide:       r.PosSide,
+			OrderID:       r.OrderID,
+			EntryOrderIDs: r.EntryOrderIds,
+			ExitPrice:     r.ExitPrice,
+			PnlR:          r.PnlR,
+			Status:        models.TradeClosed,
+		}
+		if len(r.Params) > 0 {
+			var p models.TradeParams
+			if err := sonic.Unmarshal(r.Params, &p); err != nil {
+				return nil, fmt.Errorf("trade %d params: %w", r.ID, err)
+			}
+			tr.Params = &p
+		}
+		if r.OpenedAt != nil {
+			tr.OpenedAt = *r.OpenedAt
+		}
+		if r.ClosedAt != nil {
+			tr.ClosedAt = *r.ClosedAt
+		}
+		res = append(res, tr)
+	}
+	return res, nil
+}
+
+func (t *Trades) SetRealized(ctx context.Context, tx pgx.Tx, tr *models.Trade) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Trades.SetRealized: %w", err)
+		}
+	}()
+	return t.sql.SetRealized(ctx, tx, &sql.SetRealizedParams{
+		ID:          tr.ID,
+		ExitPrice:   tr.ExitPrice,
+		PnlUsdt:     tr.PnlUSDT,
+		PnlR:        tr.PnlR,
+		GrossUsdt:   tr.GrossUSDT,
+		FeeUsdt:     tr.FeeUSDT,
+		FundingUsdt: tr.FundingUSDT,
+	})
+}
+
+func (t *Trades) Totals(ctx context.Context, tx pgx.Tx, userID int64, since time.Time) (res models.PnLTotals, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Trades.Totals: %w", err)
+		}
+	}()
+	row, err := t.sql.Totals(ctx, tx, &sql.TotalsParams{
+		Chatid: userID,
+		Since:  since,
+	})
+	if err != nil {
+		return res, err
+	}
+	return models.PnLTotals{
+		From:        since,
+		Trades:      int(row.Trades),
+		Wins:        int(row.Wins),
+		PnlUSDT:     row.PnlUsdt,
+		PnlR:        row.PnlR,
+		FeeUSDT:     row.FeeUsdt,
+		FundingUSDT: row.FundingUsdt,
+		Unsettled:   int(row.Unsettled),
+	}, nil
+}
@@ -0,0 +1,77 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"trade_bot/internal/models"
+	"trade_bot/internal/runner/pg/trail_state"
+	"trade_bot/pkg/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TrailStore — трейл-состояние открытых позиций, реализует sessions.TrailStore.
+type TrailStore struct {
+	db    *db.PgTxManager
+	trail *trail_state.TrailState
+}
+
+// NewTrailStore instance
+func NewTrailStore(db *db.PgTxManager) *TrailStore {
+	return &TrailStore{
+		db:    db,
+		trail: trail_state.New(),
+	}
+}
+
+// SaveTrail upsert по (user, instId, posSide)
+func (s *TrailStore) SaveTrail(
+	ctx context.Context,
+	userID int64,
+	st *models.PositionTrailState,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.SaveTrail: %w", err)
+		}
+	}()
+	return s.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return s.trail.Upsert(ctx, tx, userID, st)
+		})
+}
+
+// DeleteTrail in db
+func (s *TrailStore) DeleteTrail(
+	ctx context.Context,
+	userID int64,
+	instID, posSide string,
+) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.DeleteTrail: %w", err)
+		}
+	}()
+	return s.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			return s.trail.Delete(ctx, tx, userID, instID, posSide)
+		})
+}
+
+// LoadTrails — всё сохранённое состояние юзера
+func (s *TrailStore) LoadTrails(
+	ctx context.Context,
+	userID int64,
+) (states []*models.PositionTrailState, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("pg.LoadTrails: %w", err)
+		}
+	}()
+	err = s.db.RunMaster(ctx,
+		func(ctxTx context.Context, tx pgx.Tx) error {
+			states, err = s.trail.GetByUser(ctx, tx, userID)
+			return err
+		})
+	return states, err
+}
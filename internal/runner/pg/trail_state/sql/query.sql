@@ -0,0 +1,17 @@
+-- name: Upsert :exec
+INSERT INTO position_trail_state (
+    chatid, inst_id, pos_side, state, updated_at
+) VALUES (
+             @chatid, @inst_id, @pos_side, @state, now()
+         )
+ON CONFLICT (chatid, inst_id, pos_side)
+DO UPDATE SET state = EXCLUDED.state, updated_at = now();
+
+
+-- name: Delete :exec
+DELETE FROM position_trail_state
+WHERE chatid = @chatid and inst_id = @inst_id and pos_side = @pos_side;
+
+
+-- name: GetByChat :many
+SELECT inst_id, pos_side, state FROM position_trail_state WHERE chatid = @chatid;
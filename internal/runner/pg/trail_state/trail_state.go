@@ -0,0 +1,81 @@
+package trail_state
+
+import (
+	"context"
+	"fmt"
+	"trade_bot/internal/models"
+	"trade_bot/internal/runner/pg/trail_state/sql"
+
+	"github.com/bytedance/sonic"
+	"github.com/jackc/pgx/v5"
+)
+
+// TrailState implement db store
+type TrailState struct {
+	sql *sql.Queries
+}
+
+// New instance
+func New() *TrailState {
+	return &TrailState{
+		sql: sql.New(),
+	}
+}
+
+func (t *TrailState) Upsert(ctx context.Context, tx pgx.Tx, userID int64, st *models.PositionTrailState) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("TrailState.Upsert: %w", err)
+		}
+	}()
+
+	var data []byte
+	data, err = sonic.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return t.sql.Upsert(ctx, tx, &sql.UpsertParams{
+		Chatid:  userID,
+		InstID:  st.InstID,
+		PosSide: st.PosSide,
+		State:   data,
+	})
+}
+
+func (t *TrailState) Delete(ctx context.Context, tx pgx.Tx, userID int64, instID, posSide string) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("TrailState.Delete: %w", err)
+		}
+	}()
+	return t.sql.Delete(ctx, tx, &sql.DeleteParams{
+		Chatid:  userID,
+		InstID:  instID,
+		PosSide: posSide,
+	})
+}
+
+func (t *TrailState) GetByUser(ctx context.Context, tx pgx.Tx, userID int64) (states []*models.PositionTrailState, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("TrailState.GetByUser: %w", err)
+		}
+	}()
+	resp, err := t.sql.GetByChat(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	states = make([]*models.PositionTrailState, 0, len(resp))
+	for _, r := range resp {
+		var st models.PositionTrailState
+		if err = sonic.Unmarshal(r.State, &st); err != nil {
+			return nil, err
+		}
+		// ключ строки — источник правды
+		st.InstID = r.InstID
+		st.PosSide = r.PosSide
+		states = append(states, &st)
+	}
+	return states, nil
+}
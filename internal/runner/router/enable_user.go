@@ -2,25 +2,28 @@ package router
 
 import (
 	"context"
+	"log"
 	"time"
+	"trade_bot/internal/helper"
 	"trade_bot/internal/models"
-	okx_client "trade_bot/internal/modules/okx_client/service"
 
 	"trade_bot/internal/runner/sessions"
 )
 
-func (r *Router) EnableUser(user *models.UserSettings, n TelegramNotifier) {
+// EnableUser поднимает торговую сессию юзера. false — сессия не запущена:
+// нет настроек или она уже работает.
+func (r *Router) EnableUser(user *models.UserSettings, n TelegramNotifier) bool {
 	if user == nil {
 		// обязательно лог/нотификация, чтобы видно было почему не включили
 		if n != nil {
 			//n.SendService(context.Background(), "⚠️ EnableUser called with nil user settings")
 		}
-		return
+		return false
 	}
 	r.mu.Lock()
 	if _, ok := r.users[user.UserID]; ok {
 		r.mu.Unlock()
-		return
+		return false
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -29,7 +32,13 @@ func (r *Router) EnableUser(user *models.UserSettings, n TelegramNotifier) {
 		UserID:   user.UserID,
 		Settings: user,
 		Notifier: n,
-		Okx:      okx_client.NewClient(user),
+		Okx:      r.exchange(user),
+		Store:    r.trails,
+		Risk:     r.risk,
+		Journal:  r.journal,
+		Corr:     r.corr,
+		ATR:      r.atr,
+		TrailTF:  r.trailTF,
 
 		Queue:       make(chan models.Signal, 64),
 		Pending:     make(map[string]bool),
@@ -48,7 +57,102 @@ func (r *Router) EnableUser(user *models.UserSettings, n TelegramNotifier) {
 
 	r.mu.Unlock()
 
+	// 1) трейл-состояние с прошлого запуска: BE/lock/partial/тайм-стоп
+	// продолжаются с того же места.
+	r.restoreTrails(ctx, sess)
+	// риск-стоп, серия убытков и пик equity — тоже с прошлого запуска
+	r.restoreRisk(ctx, sess)
+
 	// 2) воркеры запускаем уже без лока роутера
 	go sess.ConfirmWorker(ctx)
-	go sess.PositionCacheWorker(ctx)
+	go func() {
+		// сначала сверка с биржей (чужие/потерянные позиции, зависшие алго),
+		// потом кеш позиций — без него трейлинг не стартует
+		sess.ReconcileOnStart(ctx)
+		// приватный WS (где есть) держит кеш между опросами
+		go sess.PrivateStreamWorker(ctx)
+		sess.PositionCacheWorker(ctx)
+	}()
+	return true
+}
+
+func (r *Router) restoreTrails(ctx context.Context, sess *sessions.UserSession) {
+	if r.trails == nil {
+		return
+	}
+
+	loadCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	states, err := r.trails.LoadTrails(loadCtx, sess.UserID)
+	if err != nil {
+		log.Printf("[TRAIL] user=%d restore: %v", sess.UserID, err)
+		return
+	}
+	if len(states) == 0 {
+		return
+	}
+	if acc, ok := sess.Okx.(freshAccount); ok && acc.Fresh() {
+		r.dropTrails(ctx, sess, states)
+		return
+	}
+
+	sess.PosMu.Lock()
+	for _, st := range states {
+		sess.Positions[helper.TrailKey(st.InstID, st.PosSide)] = st
+	}
+	sess.PosMu.Unlock()
+
+	// пользователю об этом расскажет ReconcileOnStart
+	log.Printf("[TRAIL] user=%d restored %d positions", sess.UserID, len(states))
+}
+
+func (r *Router) restoreRisk(ctx context.Context, sess *sessions.UserSession) {
+	if r.risk == nil {
+		return
+	}
+	if acc, ok := sess.Okx.(freshAccount); ok && acc.Fresh() {
+		// пик и старт дня от сброшенного paper-счёта дали бы ложную просадку
+		return
+	}
+
+	loadCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	st, err := r.risk.LoadRisk(loadCtx, sess.UserID)
+	if err != nil {
+		log.Printf("[RISK] user=%d restore: %v", sess.UserID, err)
+		return
+	}
+	if st == nil {
+		return
+	}
+	sess.RestoreRisk(st)
+	log.Printf("[RISK] user=%d restored: streak=%d peak=%.2f halted until %s",
+		sess.UserID, st.LossStreak, st.PeakEq, st.HaltedUntil.Format(time.RFC3339))
+}
+
+// freshAccount — paper-счёт, начатый с нуля (см. paper.Account.Fresh).
+type freshAccount interface {
+	Fresh() bool
+}
+
+// dropTrails — сохранённые трейлы относятся к позициям счёта, которого больше нет
+// (paper-счёт не поднялся из БД): не восстанавливаем их и не даём сверке
+// записать эти позиции закрытыми, а удаляем и сообщаем юзеру.
+func (r *Router) dropTrails(ctx context.Context, sess *sessions.UserSession, states []*models.PositionTrailState) {
+	delCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	for _, st := range states {
+		if err := r.trails.DeleteTrail(delCtx, sess.UserID, st.InstID, st.PosSide); err != nil {
+			log.Printf("[TRAIL] user=%d drop %s %s: %v", sess.UserID, st.InstID, st.PosSide, err)
+		}
+	}
+	log.Printf("[TRAIL] user=%d paper account reset, dropped %d positions", sess.UserID, len(states))
+
+	if sess.Notifier != nil {
+		sess.Notifier.SendF(ctx, sess.UserID,
+			"⚠️ Paper-счёт сброшен на стартовый баланс, %d сохранённых позиций не восстановлены", len(states))
+	}
 }
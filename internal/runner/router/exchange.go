@@ -0,0 +1,26 @@
+package router
+
+import (
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	okx_client "trade_bot/internal/modules/okx_client/service"
+	paper "trade_bot/internal/modules/paper/service"
+	"trade_bot/internal/runner/sessions"
+)
+
+var (
+	_ sessions.Exchange = (*okx_client.Client)(nil)
+	_ sessions.Exchange = (*paper.Account)(nil)
+
+	_ sessions.PrivateStreamer = (*okx_client.Client)(nil)
+)
+
+// NewExchangeFactory — какую биржу получит сессия юзера.
+func NewExchangeFactory(cfg *config.Config, pe *paper.Engine) sessions.ExchangeFactory {
+	return func(user *models.UserSettings) sessions.Exchange {
+		if user.Settings.TradingSettings.Paper {
+			return pe.Account(user.UserID)
+		}
+		return okx_client.NewClient(cfg, user)
+	}
+}
@@ -0,0 +1,42 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// PnLPeriod — итог закрытых сделок за период (границы по UTC).
+type PnLPeriod struct {
+	Name string
+	models.PnLTotals
+}
+
+// PnLForUser — день / неделя / месяц из журнала сделок.
+// Работает и для остановленного бота: сессия не нужна.
+func (r *Router) PnLForUser(ctx context.Context, userID int64) ([]PnLPeriod, error) {
+	if r.journal == nil {
+		return nil, fmt.Errorf("журнал сделок не подключен")
+	}
+
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	// неделя с понедельника
+	week := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	periods := []PnLPeriod{
+		{Name: "Сегодня"},
+		{Name: "Неделя"},
+		{Name: "Месяц"},
+	}
+	for i, from := range []time.Time{day, week, month} {
+		t, err := r.journal.Totals(ctx, userID, from)
+		if err != nil {
+			return nil, err
+		}
+		periods[i].PnLTotals = t
+	}
+	return periods, nil
+}
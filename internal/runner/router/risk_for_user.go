@@ -0,0 +1,32 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"trade_bot/internal/runner/sessions"
+)
+
+// RiskForUser — состояние риск-стопа запущенной сессии.
+func (r *Router) RiskForUser(userID int64) (sessions.RiskStatus, bool) {
+	r.mu.RLock()
+	sess, ok := r.users[userID]
+	r.mu.RUnlock()
+
+	if !ok {
+		return sessions.RiskStatus{}, false
+	}
+	return sess.RiskStatus(), true
+}
+
+// ResetRiskForUser — ручное снятие риск-стопа: лимиты считаются заново от текущей equity.
+func (r *Router) ResetRiskForUser(ctx context.Context, userID int64) error {
+	r.mu.RLock()
+	sess, ok := r.users[userID]
+	r.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("бот не запущен для этого пользователя")
+	}
+	sess.ResetRisk(ctx)
+	return nil
+}
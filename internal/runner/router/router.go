@@ -8,6 +8,7 @@ import (
 	"trade_bot/internal/runner/sessions"
 
 	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
 
 	tgbot "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -27,11 +28,34 @@ type UserSettingsSnapshot struct {
 type Router struct {
 	mu    sync.RWMutex
 	users map[int64]*sessions.UserSession // userID -> сессия
+
+	exchange sessions.ExchangeFactory
+	trails   sessions.TrailStore
+	risk     sessions.RiskStore
+	journal  sessions.Journal
+	corr     sessions.CorrelationSource
+	atr      sessions.ATRSource
+	trailTF  string // ТФ стадии Trail — сигнальный ТФ стратегии
 }
 
-func NewRouter() *Router {
+func NewRouter(
+	exchange sessions.ExchangeFactory,
+	trails sessions.TrailStore,
+	risk sessions.RiskStore,
+	journal sessions.Journal,
+	corr sessions.CorrelationSource,
+	atr sessions.ATRSource,
+	cfg *config.Config,
+) *Router {
 	return &Router{
-		users: make(map[int64]*sessions.UserSession),
+		users:    make(map[int64]*sessions.UserSession),
+		exchange: exchange,
+		trails:   trails,
+		risk:     risk,
+		journal:  journal,
+		corr:     corr,
+		atr:      atr,
+		trailTF:  sessions.TrailTF(cfg.Strategy.LTF),
 	}
 }
 
@@ -44,6 +68,10 @@ func (r *Router) OnSignal(ctx context.Context, sig models.Signal) {
 		sig.TF, sig.Strategy)
 
 	for _, sess := range r.users {
+		// только подписчикам стратегии
+		if !sess.Settings.Settings.TradingSettings.Subscribed(sig.Strategy) {
+			continue
+		}
 		select {
 		case sess.Queue <- sig:
 		default:
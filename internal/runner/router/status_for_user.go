@@ -54,8 +54,12 @@ func buildStatusFromCache(sess *sessions.UserSession) []models.OpenPosition {
 			Status:  "OPEN",
 		}
 
-		// pnl (оценка по lastPx)
-		if p.Entry > 0 && p.LastPx > 0 && p.Size > 0 {
+		// pnl: с биржи, если есть; иначе оценка по lastPx
+		op.Realised = p.Realised
+		if p.Upl != 0 {
+			op.UnrealizedPnl = p.Upl
+			op.UnrealizedPnlPct = p.UplPct
+		} else if p.Entry > 0 && p.LastPx > 0 && p.Size > 0 {
 			if p.PosSide == "long" {
 				op.UnrealizedPnl = (p.LastPx - p.Entry) * p.Size
 				op.UnrealizedPnlPct = (p.LastPx/p.Entry - 1) * 100
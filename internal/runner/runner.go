@@ -5,9 +5,9 @@ import (
 	"sync"
 	"time"
 	"trade_bot/internal/models"
-	okx_client "trade_bot/internal/modules/okx_client/service"
 	okx_websocket "trade_bot/internal/modules/okx_websocket/service"
 	service2 "trade_bot/internal/modules/strategy/service"
+	"trade_bot/internal/runner/sessions"
 
 	tgbot "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -30,7 +30,7 @@ type Runner struct {
 	// deps
 	mkt *okx_websocket.Client
 	cfg *models.UserSettings
-	mx  *okx_client.Client
+	mx  sessions.Exchange
 	stg service2.Engine
 	n   TelegramNotifier
 
@@ -44,7 +44,7 @@ type Runner struct {
 	healthMu sync.Mutex
 }
 
-func New(user *models.UserSettings, n TelegramNotifier, mkt *okx_websocket.Client, stg service2.Engine) *Runner {
+func New(user *models.UserSettings, n TelegramNotifier, mkt *okx_websocket.Client, stg service2.Engine, mx sessions.Exchange) *Runner {
 	if user == nil {
 		panic("runner.New: user is nil")
 	}
@@ -56,7 +56,7 @@ func New(user *models.UserSettings, n TelegramNotifier, mkt *okx_websocket.Clien
 		cancel: cancel,
 
 		cfg: user,
-		mx:  okx_client.NewClient(user),
+		mx:  mx,
 		n:   n,
 		stg: stg,
 		mkt: mkt,
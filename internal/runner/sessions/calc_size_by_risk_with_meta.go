@@ -9,7 +9,7 @@ import (
 
 // calcSizeByRiskWithMeta считает размер позиции в КОНТРАКТАХ (sz),
 // исходя из:
-//   - целевого риска в USDT (RiskPct * equity),
+//   - целевого риска в USDT (RiskPct * riskMult * equity),
 //   - дистанции до стопа,
 //   - номинала контракта (ctVal),
 //   - плеча (Leverage),
@@ -24,6 +24,7 @@ func (s *UserSession) calcSizeByRiskWithMeta(
 	meta models.Instrument,
 	entryPrice float64,
 	slPrice float64,
+	riskMult float64,
 ) (float64, error) {
 
 	if entryPrice <= 0 || slPrice <= 0 {
@@ -38,7 +39,7 @@ func (s *UserSession) calcSizeByRiskWithMeta(
 		return 0, fmt.Errorf("equity <= 0")
 	}
 
-	riskFraction := s.Settings.Settings.TradingSettings.RiskPct / 100.0
+	riskFraction := s.Settings.Settings.TradingSettings.RiskPct * riskMult / 100.0
 	if riskFraction <= 0 {
 		return 0, fmt.Errorf("riskFraction <= 0")
 	}
@@ -0,0 +1,87 @@
+package sessions
+
+import (
+	"fmt"
+	"math"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+// defaultStopATRMult — k для StopModeATR, если юзер не задал.
+const defaultStopATRMult = 2.0
+
+// StopPct — расстояние SL в долях от entry по режиму стопа юзера.
+// ATR и границы канала берутся из сигнала; если ATR не прогрет или стратегия
+// не дала границ канала (стоп за ценой входа тоже) — откат на StopPct.
+// Общая для бота и бэктеста, как и CalcSLTP.
+func StopPct(ts models.TradingSettings, side string, entry float64, sig models.Signal) (float64, error) {
+	if entry <= 0 {
+		return 0, fmt.Errorf("entry <= 0")
+	}
+
+	switch ts.StopMode {
+	case models.StopModeATR:
+		if sig.ATR > 0 {
+			k := ts.StopATRMult
+			if k <= 0 {
+				k = defaultStopATRMult
+			}
+			return k * sig.ATR / entry, nil
+		}
+
+	case models.StopModeChannel:
+		var dist float64
+		if side == "BUY" {
+			dist = entry - sig.ChLow
+		} else {
+			dist = sig.ChHigh - entry
+		}
+		if sig.ChHigh > 0 && sig.ChLow > 0 && dist > 0 {
+			return dist / entry, nil
+		}
+	}
+
+	stopPct := ts.StopPct / 100.0
+	if stopPct <= 0 {
+		return 0, fmt.Errorf("stopPct <= 0 (set TradingSettings.StopPct)")
+	}
+	return stopPct, nil
+}
+
+// CalcSLTP — SL от StopPct и TP в rr*1R, округлённые до тика в безопасную сторону.
+// Чистая функция: её же использует бэктест, чтобы уровни совпадали с живыми.
+// side: "BUY"/"SELL", stopPct и rr — уже в долях/R (0.03, 2.0).
+func CalcSLTP(side string, entry, stopPct, rr, tickSz float64) (sl, tp, riskDist float64, err error) {
+	if side != "BUY" && side != "SELL" {
+		return 0, 0, 0, fmt.Errorf("unknown side %q", side)
+	}
+	if entry <= 0 {
+		return 0, 0, 0, fmt.Errorf("entry <= 0")
+	}
+
+	// 1) сырой SL от StopPct
+	var slRaw float64
+	if side == "BUY" {
+		slRaw = entry * (1 - stopPct)
+	} else {
+		slRaw = entry * (1 + stopPct)
+	}
+
+	// 2) округляем SL "в безопасную сторону"
+	// BUY: SL ниже -> roundDown
+	// SELL: SL выше -> roundUp
+	if side == "BUY" {
+		sl = helper.RoundDownToTick(slRaw, tickSz)
+	} else {
+		sl = helper.RoundUpToTick(slRaw, tickSz)
+	}
+
+	riskDist = math.Abs(entry - sl)
+	if riskDist <= 0 {
+		return 0, 0, 0, fmt.Errorf("riskDist <= 0 after rounding")
+	}
+
+	// 3) TP от 1R
+	tp = TPPrice(side, entry, riskDist, rr, tickSz)
+	return sl, tp, riskDist, nil
+}
@@ -0,0 +1,41 @@
+package sessions
+
+import (
+	"math"
+	"testing"
+	"trade_bot/internal/models"
+)
+
+func TestStopPctModes(t *testing.T) {
+	tests := []struct {
+		name string
+		ts   models.TradingSettings
+		side string
+		sig  models.Signal
+		want float64
+	}{
+		{"percent", models.TradingSettings{StopPct: 1}, "BUY", models.Signal{}, 0.01},
+		{"atr", models.TradingSettings{StopPct: 1, StopMode: models.StopModeATR, StopATRMult: 1.5}, "BUY", models.Signal{ATR: 2}, 0.03},
+		{"atr default mult", models.TradingSettings{StopPct: 1, StopMode: models.StopModeATR}, "SELL", models.Signal{ATR: 2}, 0.04},
+		{"atr not warmed up", models.TradingSettings{StopPct: 1, StopMode: models.StopModeATR}, "BUY", models.Signal{}, 0.01},
+		{"channel buy", models.TradingSettings{StopPct: 1, StopMode: models.StopModeChannel}, "BUY", models.Signal{ChHigh: 99, ChLow: 95}, 0.05},
+		{"channel sell", models.TradingSettings{StopPct: 1, StopMode: models.StopModeChannel}, "SELL", models.Signal{ChHigh: 102, ChLow: 90}, 0.02},
+		{"channel no edges", models.TradingSettings{StopPct: 1, StopMode: models.StopModeChannel}, "BUY", models.Signal{}, 0.01},
+		{"channel edge beyond entry", models.TradingSettings{StopPct: 1, StopMode: models.StopModeChannel}, "BUY", models.Signal{ChHigh: 110, ChLow: 101}, 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StopPct(tt.ts, tt.side, 100, tt.sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Fatalf("StopPct = %.6f, want %.6f", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := StopPct(models.TradingSettings{StopMode: models.StopModeChannel}, "BUY", 100, models.Signal{}); err == nil {
+		t.Fatal("no channel and no StopPct: want error")
+	}
+}
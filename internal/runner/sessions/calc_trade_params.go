@@ -4,41 +4,28 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"math"
 	"strings"
-	"trade_bot/internal/helper"
 	"trade_bot/internal/models"
 )
 
 // calcTradeParams считает SL, TP, размер позиции и сопутствующие параметры
-// по текущим настройкам стратегии.
+// по текущим настройкам стратегии. riskMult — доля от RiskPct (1 — полный риск).
 func (s *UserSession) calcTradeParams(
 	ctx context.Context,
-	symbol string,
-	side string,
-	entry float64,
+	sig models.Signal,
+	riskMult float64,
 ) (*models.TradeParams, error) {
-	side = strings.ToUpper(side)
+	side := strings.ToUpper(string(sig.Side))
 	if side != "BUY" && side != "SELL" {
 		return nil, fmt.Errorf("unknown side %q", side)
 	}
 
 	// денежный риск
-	riskPct := s.Settings.Settings.TradingSettings.RiskPct / 100.0
+	riskPct := s.Settings.Settings.TradingSettings.RiskPct * riskMult / 100.0
 	if riskPct <= 0 {
 		return nil, fmt.Errorf("riskPct <= 0")
 	}
 
-	// стоп-дистанция
-	stopPct := s.Settings.Settings.TradingSettings.StopPct / 100.0
-	if stopPct <= 0 {
-		return nil, fmt.Errorf("stopPct <= 0 (set TradingSettings.StopPct)")
-	}
-	// адекватный safety-guard, чтобы случайно не поставить 10% стоп
-	if stopPct > 0.20 {
-		return nil, fmt.Errorf("stopPct too big: %.4f", stopPct)
-	}
-
 	rr := s.Settings.Settings.TradingSettings.TakeProfitRR
 	if rr <= 0 {
 		rr = 2.0
@@ -49,11 +36,12 @@ func (s *UserSession) calcTradeParams(
 		lev = 1
 	}
 
-	instrument, err := s.Okx.GetInstrumentMeta(ctx, symbol)
+	instrument, err := s.Okx.GetInstrumentMeta(ctx, sig.InstID)
 	if err != nil {
 		return nil, fmt.Errorf("GetInstrumentMeta: %w", err)
 	}
 
+	entry := sig.Price
 	if entry <= 0 {
 		entry = instrument.LastPx
 	}
@@ -61,45 +49,22 @@ func (s *UserSession) calcTradeParams(
 		return nil, fmt.Errorf("entry <= 0")
 	}
 
-	// 1) сырой SL от StopPct
-	var slRaw float64
-	if side == "BUY" {
-		slRaw = entry * (1 - stopPct)
-	} else {
-		slRaw = entry * (1 + stopPct)
-	}
-
-	// 2) округляем SL "в безопасную сторону"
-	// BUY: SL ниже -> roundDown
-	// SELL: SL выше -> roundUp
-	var sl float64
-	if side == "BUY" {
-		sl = helper.RoundDownToTick(slRaw, instrument.TickSz)
-	} else {
-		sl = helper.RoundUpToTick(slRaw, instrument.TickSz)
+	// стоп-дистанция по режиму (percent / atr / channel)
+	stopPct, err := StopPct(s.Settings.Settings.TradingSettings, side, entry, sig)
+	if err != nil {
+		return nil, err
 	}
-
-	riskDist := math.Abs(entry - sl)
-	if riskDist <= 0 {
-		return nil, fmt.Errorf("riskDist <= 0 after rounding")
+	// адекватный safety-guard, чтобы случайно не поставить 10% стоп
+	if stopPct > 0.20 {
+		return nil, fmt.Errorf("stopPct too big: %.4f", stopPct)
 	}
 
-	// 3) TP от 1R
-	var tpRaw float64
-	if side == "BUY" {
-		tpRaw = entry + rr*riskDist
-	} else {
-		tpRaw = entry - rr*riskDist
+	// 1-3) SL/TP от стоп-дистанции и RR
+	sl, tp, riskDist, err := CalcSLTP(side, entry, stopPct, rr, instrument.TickSz)
+	if err != nil {
+		return nil, err
 	}
 
-	// BUY: TP выше -> roundUp
-	// SELL: TP ниже -> roundDown
-	var tp float64
-	if side == "BUY" {
-		tp = helper.RoundUpToTick(tpRaw, instrument.TickSz)
-	} else {
-		tp = helper.RoundDownToTick(tpRaw, instrument.TickSz)
-	}
 	ts := s.Settings.Settings.TradingSettings
 
 	log.Printf(
@@ -116,6 +81,7 @@ func (s *UserSession) calcTradeParams(
 		instrument,
 		entry,
 		sl,
+		riskMult,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("calcSizeByRisk: %w", err)
@@ -124,20 +90,37 @@ func (s *UserSession) calcTradeParams(
 		return nil, fmt.Errorf("size <= 0")
 	}
 
+	// 1R в USDT для журнала: для inverse зависит от курса settleCcy, не считаем
+	var riskUSDT float64
+	if instrument.Kind == models.ContractLinearUSDT {
+		riskUSDT = riskDist * size * instrument.CtVal
+	}
+
 	// полезный sanity для логов:
 	// stopDistPct := riskDist / entry
 	// estROEStop := stopDistPct * float64(lev) * 100.0
 
+	// лестница TP (если задана) — вместо одного TP на весь объём
+	tps := TPLadder(side, entry, riskDist, size, instrument.TickSz, instrument.LotSz, instrument.MinSz, ts.TPLadder)
+	if n := len(tps); n > 0 {
+		tp = tps[n-1].Price
+	}
+
 	return &models.TradeParams{
 		Entry:     entry,
 		SL:        sl,
 		TP:        tp,
 		Size:      size,
 		TickSize:  instrument.TickSz,
-		RiskPct:   s.Settings.Settings.TradingSettings.RiskPct, // денежный риск
+		RiskPct:   s.Settings.Settings.TradingSettings.RiskPct * riskMult, // денежный риск
 		RR:        rr,
 		RiskDist:  riskDist,
 		Leverage:  lev,
 		Direction: side,
+		CtVal:     instrument.CtVal,
+		RiskUSDT:  riskUSDT,
+		LotSz:     instrument.LotSz,
+		MinSz:     instrument.MinSz,
+		TPs:       tps,
 	}, nil
 }
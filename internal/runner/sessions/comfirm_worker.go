@@ -22,21 +22,68 @@ func (s *UserSession) ConfirmWorker(ctx context.Context) {
 		func() {
 			defer s.setPending(sig.InstID, false)
 
-			// 1) лимит по открытым позициям
-			if s.Settings.Settings.TradingSettings.MaxOpenPositions > 0 {
-				if positions, err := s.Okx.OpenPositions(ctx); err == nil &&
-					len(positions) >= s.Settings.Settings.TradingSettings.MaxOpenPositions {
-					if s.canSend("limit_open_positions", 30*time.Minute) {
-						s.Notifier.SendF(ctx, s.UserID,
-							"⚠️ [%s] Лимит открытых позиций (%d) достигнут, сигнал пропущен",
-							sig.InstID, s.Settings.Settings.TradingSettings.MaxOpenPositions,
-						)
-					}
-					return
+			tradeID := s.journalSignal(ctx, sig)
+
+			// 0.5) риск-лимиты: дневной убыток, серия, просадка
+			if halted, reason := s.CheckRisk(ctx); halted {
+				if s.canSend("risk_halt", 30*time.Minute) {
+					s.Notifier.SendF(ctx, s.UserID,
+						"🧯 [%s] Сигнал пропущен: риск-стоп (%s)", sig.InstID, reason)
+				}
+				s.journalStatus(ctx, tradeID, models.TradeRejected, models.EvRejected, "risk_halt")
+				return
+			}
+
+			// 1) портфельные лимиты: число позиций и направление (notional — после расчёта)
+			if reason := s.checkExposure(sig); reason != "" {
+				if s.canSend("limit_exposure:"+sig.InstID+":"+string(sig.Side), 30*time.Minute) {
+					s.Notifier.SendF(ctx, s.UserID,
+						"⚠️ [%s] %s: сигнал пропущен — %s", sig.InstID, sig.Side, reason)
+				}
+				s.journalStatus(ctx, tradeID, models.TradeRejected, models.EvRejected, "exposure: "+reason)
+				return
+			}
+
+			// 1.5) корреляция с открытыми позициями той же стороны
+			riskMult, corrReason := s.checkCorrelation(sig)
+			if riskMult == 0 {
+				if s.canSend("limit_corr:"+sig.InstID+":"+string(sig.Side), 30*time.Minute) {
+					s.Notifier.SendF(ctx, s.UserID,
+						"⚠️ [%s] %s: сигнал пропущен — %s", sig.InstID, sig.Side, corrReason)
+				}
+				s.journalStatus(ctx, tradeID, models.TradeRejected, models.EvRejected, "correlation: "+corrReason)
+				return
+			}
+			if corrReason != "" {
+				s.Notifier.SendF(ctx, s.UserID,
+					"↘️ [%s] %s: риск x%.2f — %s", sig.InstID, sig.Side, riskMult, corrReason)
+				s.journalEvent(ctx, tradeID, models.EvDownsized, map[string]any{
+					"risk_mult": riskMult,
+					"reason":    corrReason,
+				})
+			}
+
+			// 2) расчёт параметров
+			params, err := s.calcTradeParams(ctx, sig, riskMult)
+			if err != nil {
+				s.Notifier.SendF(ctx, s.UserID,
+					"❗️ [%s] Ошибка расчёта параметров сделки: %v", sig.InstID, err)
+				s.journalStatus(ctx, tradeID, models.TradeFailed, models.EvError, "calc: "+err.Error())
+				return
+			}
+
+			// 2.5) notional входа известен только теперь — лимиты по нему до Confirm,
+			// чтобы не спрашивать юзера о входе, который всё равно не пройдёт
+			if reason := s.checkNotional(sig, params); reason != "" {
+				if s.canSend("limit_exposure:"+sig.InstID+":"+string(sig.Side), 30*time.Minute) {
+					s.Notifier.SendF(ctx, s.UserID,
+						"⚠️ [%s] %s: сигнал пропущен — %s", sig.InstID, sig.Side, reason)
 				}
+				s.journalStatus(ctx, tradeID, models.TradeRejected, models.EvRejected, "exposure: "+reason)
+				return
 			}
 
-			// 2) Confirm (если включен)
+			// 3) Confirm (если включен)
 			prompt := fmt.Sprintf(
 				"🔔 [%s] %s %s @ %.4f\n%s\nSL/TP будут выставлены после входа. Войти?",
 				sig.InstID, sig.Strategy, sig.Side, sig.Price, sig.Reason,
@@ -49,38 +96,36 @@ func (s *UserSession) ConfirmWorker(ctx context.Context) {
 			if !ok {
 				s.setCooldown(sig.InstID, time.Now().Add(s.Settings.Settings.TradingSettings.CooldownPerSymbol))
 				s.Notifier.SendF(ctx, s.UserID, "⛔️ [%s] Вход отменён/таймаут", sig.InstID)
+				s.journalStatus(ctx, tradeID, models.TradeRejected, models.EvRejected, "declined_or_timeout")
 				return
 			}
-
-			// 3) расчёт параметров
-			params, err := s.calcTradeParams(ctx, sig.InstID, string(sig.Side), sig.Price)
-			if err != nil {
-				s.Notifier.SendF(ctx, s.UserID,
-					"❗️ [%s] Ошибка расчёта параметров сделки: %v", sig.InstID, err)
-				return
+			if s.Settings.Settings.TradingSettings.ConfirmRequired {
+				s.journalEvent(ctx, tradeID, models.EvConfirmed, nil)
 			}
 
 			// 4) открытие + TP/SL
-			res, err := s.OpenPositionWithTpSl(ctx, sig, params)
+			res, err := s.OpenPositionWithTpSl(ctx, sig, params, tradeID)
 			if err != nil {
 				s.Notifier.SendF(ctx, s.UserID,
 					"❗️ [%s] Ошибка открытия ордера: %v", sig.InstID, err)
+				s.journalStatus(ctx, tradeID, models.TradeFailed, models.EvError, "open: "+err.Error())
 				return
 			}
 
-			// 5) сохраняем трейл-состояние (только если есть SL algoId)
+			s.cacheOpened(sig.InstID, res.PosSide, params)
+
+			// 5) сохраняем трейл-состояние. Без SL (биржа не приняла) трейлинг стоит —
+			// trailOne пропускает пустой AlgoID, — но состояние нужно: по нему
+			// закрытие позиции закроет сделку в журнале, а сверка при старте
+			// попробует выставить SL заново
 			if res.SLAlgoID == "" {
-				// трейлинг не сможет двигать SL — просто выходим
-				return
+				s.Notifier.SendF(ctx, s.UserID,
+					"⚠️ [%s] %s: позиция без SL, трейлинг выключен — проверьте её на бирже", sig.InstID, res.PosSide)
 			}
 
 			key := sig.InstID + ":" + res.PosSide
 
-			s.PosMu.Lock()
-			if s.Positions == nil {
-				s.Positions = make(map[string]*models.PositionTrailState)
-			}
-			s.Positions[key] = &models.PositionTrailState{
+			st := &models.PositionTrailState{
 				InstID:   sig.InstID,
 				PosSide:  res.PosSide,
 				Entry:    res.Entry,
@@ -90,10 +135,25 @@ func (s *UserSession) ConfirmWorker(ctx context.Context) {
 				TickSz:   params.TickSize,
 				AlgoID:   res.SLAlgoID, // ✅ SL algoId
 				Size:     params.Size,
+				TPs:      res.TPs,
+				LotSz:    params.LotSz,
+				MinSz:    params.MinSz,
 				MFE:      res.Entry,
 				OpenedAt: time.Now(),
+
+				TradeID:  tradeID,
+				InitSize: params.Size,
+				RiskUSDT: params.RiskUSDT,
+			}
+
+			s.PosMu.Lock()
+			if s.Positions == nil {
+				s.Positions = make(map[string]*models.PositionTrailState)
 			}
+			s.Positions[key] = st
 			s.PosMu.Unlock()
+
+			s.saveTrail(ctx, st)
 		}()
 	}
 }
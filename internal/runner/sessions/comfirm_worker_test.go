@@ -0,0 +1,43 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// failSL — биржа, которая не принимает SL (TP проходят).
+type failSL struct{ Exchange }
+
+func (e failSL) PlaceSingleAlgo(ctx context.Context, instID, posSide string, size, triggerPx float64, isTP bool) (string, error) {
+	if !isTP {
+		return "", errors.New("sl rejected")
+	}
+	return e.Exchange.PlaceSingleAlgo(ctx, instID, posSide, size, triggerPx, isTP)
+}
+
+// Вход без SL: трейл-состояние всё равно есть, сверка при старте выставляет SL.
+func TestEntryWithoutSLKeepsTrailState(t *testing.T) {
+	srv := newFakeOKX(t)
+	s := newFakeSession(t, srv)
+	okx := s.Okx
+	s.Okx = failSL{okx}
+
+	st := openLong(t, s)
+	if st.AlgoID != "" || st.SL != 99 {
+		t.Fatalf("trail state = %+v, want no SL algo and SL level 99", st)
+	}
+
+	s.Okx = okx
+	rep, err := s.Reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Resumed) != 1 {
+		t.Fatalf("report = %+v, want the position resumed", rep)
+	}
+	st = trailState(s, fakeInst, "long")
+	if st == nil || st.AlgoID == "" || st.SL != 99 {
+		t.Fatalf("trail state after reconcile = %+v, want SL algo at 99", st)
+	}
+}
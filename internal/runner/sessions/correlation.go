@@ -0,0 +1,53 @@
+package sessions
+
+import (
+	"fmt"
+	"trade_bot/internal/models"
+)
+
+// CorrelationSource — скользящая корреляция доходностей (считает стратегия по LTF-свечам).
+type CorrelationSource interface {
+	// Corr — корреляция a и b; ok == false — данных мало.
+	Corr(a, b string) (float64, bool)
+}
+
+// checkCorrelation — сигнал против открытых позиций той же стороны.
+// Возвращает множитель риска (1 — как есть, 0 — пропустить) и причину.
+func (s *UserSession) checkCorrelation(sig models.Signal) (float64, string) {
+	ts := s.Settings.Settings.TradingSettings
+	if s.Corr == nil || ts.MaxCorrelation <= 0 {
+		return 1, ""
+	}
+
+	posSide := "long"
+	if sig.Side == models.SideSell {
+		posSide = "short"
+	}
+
+	s.PosCacheMu.RLock()
+	open := make([]string, 0, len(s.PositionsCache))
+	for k, p := range s.PositionsCache {
+		if k.PosSide == posSide && p.Size > 0 && k.InstID != sig.InstID {
+			open = append(open, k.InstID)
+		}
+	}
+	s.PosCacheMu.RUnlock()
+
+	var worst float64
+	var worstInst string
+	for _, inst := range open {
+		c, ok := s.Corr.Corr(sig.InstID, inst)
+		if ok && c > worst {
+			worst, worstInst = c, inst
+		}
+	}
+	if worst < ts.MaxCorrelation {
+		return 1, ""
+	}
+
+	reason := fmt.Sprintf("корреляция с %s %s = %.2f (порог %.2f)", worstInst, posSide, worst, ts.MaxCorrelation)
+	if ts.CorrSizeMult <= 0 {
+		return 0, reason
+	}
+	return min(ts.CorrSizeMult, 1), reason
+}
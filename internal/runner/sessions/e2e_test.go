@@ -0,0 +1,167 @@
+package sessions
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+	"trade_bot/internal/okxfake"
+)
+
+// e2e на стенде okxfake: сигнал -> ConfirmWorker -> вход и SL/TP на бирже,
+// дальше цена идёт по скрипту 1m свечей.
+
+// liveAlgos — живые SL и TP по инструменту на стенде.
+func liveAlgos(srv *okxfake.Server) (sl, tp []okxfake.Algo) {
+	for _, a := range srv.Algos() {
+		if a.InstID != fakeInst || a.State != "live" {
+			continue
+		}
+		if a.SLTriggerPx > 0 {
+			sl = append(sl, a)
+		}
+		if a.TPTriggerPx > 0 {
+			tp = append(tp, a)
+		}
+	}
+	return sl, tp
+}
+
+// step — следующая свеча скрипта: биржа двигает цену и триггеры,
+// сессия получает ту же закрытую 1m свечу, как из стрима.
+func step(t *testing.T, srv *okxfake.Server, s *UserSession, bar models.CandleTick) {
+	t.Helper()
+	srv.Script(fakeInst, bar)
+	if srv.Step() != 1 {
+		t.Fatal("script is empty")
+	}
+	s.OnCandleClose(s.Ctx, bar)
+}
+
+func TestE2EEntryPlacesSLAndTP(t *testing.T) {
+	srv := newFakeOKX(t)
+	s := newFakeSession(t, srv)
+	st := openLong(t, s)
+
+	// риск 1% от 10 000 USDT при 1R = 1.0 и контракте 0.01 -> 10 000 контрактов
+	if got := srv.Positions()[fakeInst+":long"]; got != 10000 || st.Size != 10000 {
+		t.Fatalf("position = %v, state size = %v, want 10000", got, st.Size)
+	}
+	if st.Entry != 100 || st.SL != 99 || st.TP != 103 || st.RiskDist != 1 {
+		t.Fatalf("state = entry %v SL %v TP %v R %v, want 100/99/103/1", st.Entry, st.SL, st.TP, st.RiskDist)
+	}
+
+	sl, tp := liveAlgos(srv)
+	if len(sl) != 1 || sl[0].SLTriggerPx != 99 || sl[0].Size != 10000 || sl[0].AlgoID != st.AlgoID {
+		t.Fatalf("SL algos = %+v, want one at 99 for 10000 (%s)", sl, st.AlgoID)
+	}
+	if len(tp) != 1 || tp[0].TPTriggerPx != 103 || tp[0].Size != 10000 {
+		t.Fatalf("TP algos = %+v, want one at 103 for 10000", tp)
+	}
+	for _, a := range append(sl, tp...) {
+		if !models.IsBotClOrdID(a.AlgoClOrdID) || a.Side != "sell" || a.PosSide != "long" {
+			t.Fatalf("algo %+v: want bot-tagged sell/long", a)
+		}
+	}
+}
+
+func TestE2ETrailMovesSLToBEThenLock(t *testing.T) {
+	srv := newFakeOKX(t)
+	s := newFakeSession(t, srv)
+	openLong(t, s)
+
+	slot := helper.TrailSlot15m(time.Now())
+	at := func(m int) time.Time { return slot.Add(time.Duration(m) * time.Minute) }
+	wantSL := func(stage string, px float64, flag func(*models.PositionTrailState) bool) {
+		t.Helper()
+		st := trailState(s, fakeInst, "long")
+		if st == nil || math.Abs(st.SL-px) > 1e-9 || !flag(st) {
+			t.Fatalf("%s: state = %+v, want SL %v", stage, st, px)
+		}
+		sl, _ := liveAlgos(srv)
+		if len(sl) != 1 || math.Abs(sl[0].SLTriggerPx-px) > 1e-9 || sl[0].AlgoID != st.AlgoID {
+			t.Fatalf("%s: live SL algos = %+v, want one at %v (%s)", stage, sl, px, st.AlgoID)
+		}
+	}
+
+	// до 0.6R SL не трогаем
+	step(t, srv, s, bar1m(at(1), 100, 100.5, 99.8, 100.4))
+	wantSL("below BE trigger", 99, func(st *models.PositionTrailState) bool { return !st.MovedToBE })
+
+	// MFE 0.7R -> BE
+	step(t, srv, s, bar1m(at(2), 100.4, 100.7, 100.3, 100.6))
+	wantSL("BE", 100, func(st *models.PositionTrailState) bool { return st.MovedToBE && !st.LockedProfit })
+
+	// в этом слоте SL уже переносили: MFE 1R ждёт следующего 15m слота
+	step(t, srv, s, bar1m(at(5), 100.6, 101, 100.5, 100.9))
+	wantSL("same slot", 100, func(st *models.PositionTrailState) bool { return !st.LockedProfit })
+
+	// следующий слот: Lock на +0.3R
+	step(t, srv, s, bar1m(at(16), 100.9, 100.95, 100.7, 100.8))
+	wantSL("lock", 100.3, func(st *models.PositionTrailState) bool { return st.MovedToBE && st.LockedProfit })
+
+	// TP и позиция на месте
+	if _, tp := liveAlgos(srv); len(tp) != 1 || srv.Positions()[fakeInst+":long"] != 10000 {
+		t.Fatalf("TP = %+v, positions = %v", tp, srv.Positions())
+	}
+}
+
+func TestE2EStopLossClosesPosition(t *testing.T) {
+	srv := newFakeOKX(t)
+	s := newFakeSession(t, srv)
+	openLong(t, s)
+
+	// приватный WS: ждём первую пересинхронизацию (подписки уже есть)
+	s.PosCacheMu.Lock()
+	s.PosCacheAt = time.Time{}
+	s.PosCacheMu.Unlock()
+	go s.PrivateStreamWorker(s.Ctx)
+	waitFor(t, "private stream", func() bool {
+		s.PosCacheMu.RLock()
+		defer s.PosCacheMu.RUnlock()
+		return !s.PosCacheAt.IsZero()
+	})
+
+	slot := helper.TrailSlot15m(time.Now())
+	step(t, srv, s, bar1m(slot.Add(time.Minute), 100, 100.2, 98.5, 98.8))
+
+	if pos := srv.Positions(); len(pos) != 0 {
+		t.Fatalf("positions after SL = %v, want none", pos)
+	}
+	waitFor(t, "trail state removed", func() bool { return trailState(s, fakeInst, "long") == nil })
+
+	// SL исполнен по триггеру, TP снят вместе с позицией
+	var closed bool
+	for _, o := range srv.Orders() {
+		if o.ReduceOnly && o.AlgoID != "" && o.AvgPx == 99 && o.Size == 10000 {
+			closed = true
+		}
+	}
+	if !closed {
+		t.Fatalf("no SL fill at 99 in %+v", srv.Orders())
+	}
+	if sl, tp := liveAlgos(srv); len(sl)+len(tp) != 0 {
+		t.Fatalf("live algos after close: SL %+v TP %+v", sl, tp)
+	}
+
+	s.PosCacheMu.RLock()
+	_, cached := s.PositionsCache[models.PosKey{InstID: fakeInst, PosSide: "long"}]
+	s.PosCacheMu.RUnlock()
+	if cached {
+		t.Fatal("closed position still in cache")
+	}
+
+	n := s.Notifier.(*recNotifier)
+	waitFor(t, "SL notification", func() bool {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		for _, m := range n.msgs {
+			if strings.Contains(m, "SL") {
+				return true
+			}
+		}
+		return false
+	})
+}
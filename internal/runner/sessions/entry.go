@@ -0,0 +1,250 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+const (
+	defaultEntryTimeout = 20 * time.Second
+	entryPollEvery      = time.Second
+	// маркет на OKX исполняется сразу; paper-счёт ждёт следующую 1m свечу
+	marketFillTimeout = 90 * time.Second
+)
+
+// entryFill — итог входа: ордер на открытие и фактически набранная позиция
+// (avgPx/accFillSz из /trade/order, а не цена сигнала).
+type entryFill struct {
+	OrderID  string
+	OrderIDs []string // все ордера входа, OrderID первым
+	AvgPx    float64  // 0 — средняя неизвестна, остаётся params.Entry
+	Size     float64
+}
+
+// EntryLimitPx — цена лимита от цены сигнала: offsetPct > 0 уступает рынку
+// (BUY выше, SELL ниже), < 0 — ждём отката. Округление — не хуже заданной цены.
+func EntryLimitPx(side string, price, offsetPct, tickSz float64) float64 {
+	if side == "BUY" {
+		return helper.RoundDownToTick(price*(1+offsetPct/100), tickSz)
+	}
+	return helper.RoundUpToTick(price*(1-offsetPct/100), tickSz)
+}
+
+// enter открывает позицию по EntryMode юзера. Лимит ждёт исполнения до EntryTimeout,
+// затем остаток снимается; в limit_market он добирается по рынку.
+// Частично исполненный лимит — это позиция: возвращаем то, что набрали.
+func (s *UserSession) enter(
+	ctx context.Context,
+	sig models.Signal,
+	params *models.TradeParams,
+	side, openType int,
+	tradeID int64,
+) (entryFill, error) {
+	ts := s.Settings.Settings.TradingSettings
+
+	switch ts.EntryMode {
+	case models.EntryLimit, models.EntryPostOnly, models.EntryLimitMarket:
+	default:
+		orderID, err := s.Okx.PlaceMarket(ctx, sig.InstID, params.Size, side, params.Leverage, openType)
+		if err != nil {
+			return entryFill{}, fmt.Errorf("PlaceMarket: %w", err)
+		}
+		return s.marketFill(ctx, sig.InstID, orderID, params.Size, tradeID)
+	}
+
+	px := EntryLimitPx(params.Direction, params.Entry, ts.EntryOffsetPct, params.TickSize)
+	orderID, err := s.Okx.PlaceLimit(ctx, sig.InstID, params.Size, px, side, params.Leverage, ts.EntryMode == models.EntryPostOnly)
+	if err != nil {
+		return entryFill{}, fmt.Errorf("PlaceLimit: %w", err)
+	}
+
+	timeout := ts.EntryTimeout
+	if timeout <= 0 {
+		timeout = defaultEntryTimeout
+	}
+	ord, err := s.waitOrder(ctx, sig.InstID, orderID, timeout)
+	if err != nil || !ord.Done() {
+		// между опросом и отменой лимит мог доисполниться — итог берём после отмены
+		if cerr := s.Okx.CancelOrder(ctx, sig.InstID, orderID); cerr != nil {
+			log.Printf("[ENTRY] user=%d %s cancel %s: %v", s.UserID, sig.InstID, orderID, cerr)
+		}
+		ord, err = s.orderFill(ctx, sig.InstID, orderID)
+		if err != nil {
+			return entryFill{}, fmt.Errorf("limit %s state unknown after cancel: %w", orderID, err)
+		}
+	}
+
+	fill := entryFill{OrderID: orderID, OrderIDs: []string{orderID}, AvgPx: ord.AvgPx, Size: ord.AccFillSz}
+	limitSz := fill.Size
+
+	// добор остатка по рынку
+	rest := params.Size - fill.Size
+	if params.LotSz > 0 {
+		rest = math.Floor(rest/params.LotSz+1e-9) * params.LotSz
+	}
+	if ts.EntryMode == models.EntryLimitMarket && rest > 0 && rest >= params.MinSz {
+		mktID, err := s.Okx.PlaceMarket(ctx, sig.InstID, rest, side, params.Leverage, openType)
+		if err != nil {
+			if fill.Size <= 0 {
+				return entryFill{}, fmt.Errorf("PlaceMarket after limit timeout: %w", err)
+			}
+			s.journalEvent(ctx, tradeID, models.EvError, map[string]any{"stage": "entry_market", "error": err.Error()})
+		} else {
+			if mf, err := s.marketFill(ctx, sig.InstID, mktID, rest, tradeID); err == nil {
+				fill.OrderIDs = append(fill.OrderIDs, mktID)
+				mktPx := mf.AvgPx
+				if mktPx <= 0 {
+					mktPx = params.Entry
+				}
+				if fill.Size > 0 && fill.AvgPx > 0 {
+					fill.AvgPx = (fill.AvgPx*fill.Size + mktPx*mf.Size) / (fill.Size + mf.Size)
+				} else {
+					fill.AvgPx = mktPx
+				}
+				fill.Size += mf.Size
+			}
+		}
+	}
+
+	s.journalEvent(ctx, tradeID, models.EvEntry, map[string]any{
+		"mode":     string(ts.EntryMode),
+		"limit_px": px,
+		"order_id": orderID,
+		"limit_sz": limitSz,
+		"size":     fill.Size,
+		"avg_px":   fill.AvgPx,
+	})
+
+	if fill.Size <= 0 {
+		return entryFill{}, fmt.Errorf("limit @ %.6f not filled in %s", px, timeout)
+	}
+	return fill, nil
+}
+
+// marketFill — фактическая средняя и объём маркет-ордера (/trade/order).
+// Если биржа не ответила, остаёмся на расчётных entry/size: позиция уже открыта,
+// SL/TP важнее точности.
+func (s *UserSession) marketFill(ctx context.Context, instID, orderID string, size float64, tradeID int64) (entryFill, error) {
+	fill := entryFill{OrderID: orderID, OrderIDs: []string{orderID}, Size: size}
+
+	o, err := s.orderFill(ctx, instID, orderID)
+	if err != nil && o.OrdID != "" && !o.Done() {
+		// ордер принят, но ещё не исполнен — ждём, потом снимаем
+		o, err = s.waitOrder(ctx, instID, orderID, marketFillTimeout)
+		if err == nil && !o.Done() {
+			if cerr := s.Okx.CancelOrder(ctx, instID, orderID); cerr != nil {
+				log.Printf("[ENTRY] user=%d %s cancel market %s: %v", s.UserID, instID, orderID, cerr)
+			}
+			o, err = s.orderFill(ctx, instID, orderID)
+		}
+	}
+	if err != nil {
+		log.Printf("[ENTRY] user=%d %s market %s fill unknown: %v", s.UserID, instID, orderID, err)
+		s.journalEvent(ctx, tradeID, models.EvError, map[string]any{"stage": "entry_fill", "error": err.Error()})
+		return fill, nil
+	}
+	if o.AccFillSz <= 0 {
+		return entryFill{}, fmt.Errorf("market %s not filled (state=%s)", orderID, o.State)
+	}
+	fill.AvgPx, fill.Size = o.AvgPx, o.AccFillSz
+	return fill, nil
+}
+
+// waitOrder опрашивает ордер до исполнения/отмены или таймаута.
+// Возвращает последнее известное состояние; ошибка — если не удалось прочитать ни разу.
+func (s *UserSession) waitOrder(ctx context.Context, instID, ordID string, timeout time.Duration) (models.OrderInfo, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	tick := time.NewTicker(entryPollEvery)
+	defer tick.Stop()
+
+	var (
+		last    models.OrderInfo
+		lastErr error
+		seen    bool
+	)
+	for {
+		o, err := s.Okx.GetOrder(ctx, instID, ordID)
+		if err == nil {
+			last, seen = o, true
+			if o.Done() {
+				return o, nil
+			}
+		} else {
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-deadline.C:
+			if !seen {
+				return last, lastErr
+			}
+			return last, nil
+		case <-tick.C:
+		}
+	}
+}
+
+// orderFill — итоговое состояние ордера, с парой повторов: сразу после
+// отмены или маркета биржа может ещё не отдать avgPx/accFillSz.
+func (s *UserSession) orderFill(ctx context.Context, instID, ordID string) (models.OrderInfo, error) {
+	var (
+		o   models.OrderInfo
+		err error
+	)
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return o, ctx.Err()
+			case <-time.After(300 * time.Millisecond):
+			}
+		}
+		o, err = s.Okx.GetOrder(ctx, instID, ordID)
+		if err == nil && o.Done() {
+			return o, nil
+		}
+	}
+	if err != nil {
+		return o, err
+	}
+	return o, fmt.Errorf("order %s still %s", ordID, o.State)
+}
+
+// refitToFill — уровни под фактический вход: SL/TP переезжают за avgPx с тем же 1R,
+// объём и лестница TP — под исполненный размер.
+func refitToFill(p *models.TradeParams, avgPx, size float64, ladder []models.TPLevel) {
+	if avgPx > 0 && avgPx != p.Entry {
+		var sl float64
+		if p.Direction == "BUY" {
+			sl = helper.RoundDownToTick(avgPx-p.RiskDist, p.TickSize)
+		} else {
+			sl = helper.RoundUpToTick(avgPx+p.RiskDist, p.TickSize)
+		}
+		if dist := math.Abs(avgPx - sl); dist > 0 {
+			p.Entry, p.SL, p.RiskDist = avgPx, sl, dist
+			p.TP = TPPrice(p.Direction, avgPx, dist, p.RR, p.TickSize)
+		}
+	}
+	if size > 0 {
+		p.Size = size
+	}
+	// RiskUSDT считается только для linear — там и пересчитываем
+	if p.RiskUSDT > 0 {
+		p.RiskUSDT = p.RiskDist * p.Size * p.CtVal
+	}
+
+	if len(p.TPs) > 0 {
+		p.TPs = TPLadder(p.Direction, p.Entry, p.RiskDist, p.Size, p.TickSize, p.LotSz, p.MinSz, ladder)
+		if n := len(p.TPs); n > 0 {
+			p.TP = p.TPs[n-1].Price
+		}
+	}
+}
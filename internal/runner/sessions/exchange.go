@@ -0,0 +1,43 @@
+package sessions
+
+import (
+	"context"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// Exchange — всё, что торговой сессии нужно от биржи.
+// OKX-клиент — одна из реализаций; сессия не знает, с кем именно торгует.
+type Exchange interface {
+	// PlaceMarket — маркет-ордер на открытие. side: 1 = long, 3 = short.
+	PlaceMarket(ctx context.Context, instID string, vol float64, side, leverage, openType int) (string, error)
+	// PlaceLimit — лимитный ордер на открытие по px; postOnly — только maker.
+	PlaceLimit(ctx context.Context, instID string, vol, px float64, side, leverage int, postOnly bool) (string, error)
+	// GetOrder — состояние ордера: state, avgPx, accFillSz.
+	GetOrder(ctx context.Context, instID, ordID string) (models.OrderInfo, error)
+	// CancelOrder — снять невыполненный остаток ордера.
+	CancelOrder(ctx context.Context, instID, ordID string) error
+	// PlaceSingleAlgo — условный reduce-ордер (SL или TP), возвращает algoId.
+	PlaceSingleAlgo(ctx context.Context, instID, posSide string, size, triggerPx float64, isTP bool) (string, error)
+	CancelAlgo(ctx context.Context, instID, algoID string) error
+	// PendingAlgos — все висящие SL/TP аккаунта (для сверки при старте).
+	PendingAlgos(ctx context.Context) ([]models.AlgoOrder, error)
+	// CloseMarket — закрыть size контрактов позиции по рынку.
+	CloseMarket(ctx context.Context, instID, posSide string, size float64) (string, error)
+
+	OpenPositions(ctx context.Context) ([]models.OpenPosition, error)
+	// Fills — исполнения с since (для реального PnL сделок).
+	Fills(ctx context.Context, since time.Time) ([]models.Fill, error)
+	// FundingBills — начисления/списания funding с since.
+	FundingBills(ctx context.Context, since time.Time) ([]models.Bill, error)
+	USDTBalance(ctx context.Context) (float64, error)
+	// Equity — equity по USDT с нереализованным PnL (для риск-лимитов).
+	Equity(ctx context.Context) (float64, error)
+
+	GetInstrumentMeta(ctx context.Context, instID string) (models.Instrument, error)
+	SettleCcyToUSDT(ctx context.Context, settleCcy string) (float64, error)
+	SetLeverage(ctx context.Context, instID string, lever int, posSide string) error
+}
+
+// ExchangeFactory выбирает реализацию биржи под конкретного юзера.
+type ExchangeFactory func(user *models.UserSettings) Exchange
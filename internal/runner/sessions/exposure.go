@@ -0,0 +1,144 @@
+package sessions
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// exposure — открытый портфель по кешу позиций.
+type exposure struct {
+	Count     int
+	Long      int
+	Short     int
+	LongUSDT  float64
+	ShortUSDT float64
+}
+
+func (e exposure) total() float64 { return e.LongUSDT + e.ShortUSDT }
+
+// checkExposure — лимиты по числу позиций, до расчёта сделки.
+// Без REST по позициям: кеш держат PositionCacheWorker и приватный WS.
+// Возвращает текст причины отказа ("" — вход разрешён).
+func (s *UserSession) checkExposure(sig models.Signal) string {
+	ts := s.Settings.Settings.TradingSettings
+	e := s.exposureSnapshot()
+	long := sig.Side == models.SideBuy
+
+	if ts.MaxOpenPositions > 0 && e.Count >= ts.MaxOpenPositions {
+		return fmt.Sprintf("открыто %d позиций (лимит %d)", e.Count, ts.MaxOpenPositions)
+	}
+
+	if ts.MaxSameDirection > 0 {
+		same, dir := e.Short, "short"
+		if long {
+			same, dir = e.Long, "long"
+		}
+		if same >= ts.MaxSameDirection {
+			return fmt.Sprintf("в %s уже %d позиций (лимит %d)", dir, same, ts.MaxSameDirection)
+		}
+	}
+	return ""
+}
+
+// checkNotional — лимиты notional по уже рассчитанной сделке: размер входа
+// зависит от режима стопа и множителя риска, так что берём его из params.
+// Идёт до Confirm, поэтому equity — только из кеша приватного WS, без REST.
+func (s *UserSession) checkNotional(sig models.Signal, params *models.TradeParams) string {
+	ts := s.Settings.Settings.TradingSettings
+	if ts.MaxTotalNotionalX <= 0 && ts.MaxNetNotionalX <= 0 {
+		return ""
+	}
+	e := s.exposureSnapshot()
+	long := sig.Side == models.SideBuy
+
+	s.PosCacheMu.RLock()
+	eq, at := s.Equity, s.EquityAt
+	s.PosCacheMu.RUnlock()
+	if eq <= 0 || time.Since(at) >= equityFresh {
+		// без свежей equity лимиты в долях не посчитать — не блокируем торговлю
+		log.Printf("[EXPOSURE] user=%d no fresh equity, notional limits skipped", s.UserID)
+		return ""
+	}
+	ctVal := params.CtVal
+	if ctVal <= 0 {
+		ctVal = 1
+	}
+	cand := params.Size * params.Entry * ctVal
+
+	if ts.MaxTotalNotionalX > 0 {
+		if x := (e.total() + cand) / eq; x > ts.MaxTotalNotionalX {
+			return fmt.Sprintf("суммарный notional станет %.2fx equity (сейчас %.0f + вход ~%.0f USDT, лимит %.2fx)",
+				x, e.total(), cand, ts.MaxTotalNotionalX)
+		}
+	}
+
+	if ts.MaxNetNotionalX > 0 {
+		net := e.LongUSDT - e.ShortUSDT
+		if long {
+			net += cand
+		} else {
+			net -= cand
+		}
+		// вход, уменьшающий перекос, не режем
+		if x := math.Abs(net) / eq; x > ts.MaxNetNotionalX && math.Abs(net) > math.Abs(e.LongUSDT-e.ShortUSDT) {
+			return fmt.Sprintf("чистая экспозиция станет %+.2fx equity (long %.0f / short %.0f USDT, лимит %.2fx)",
+				net/eq, e.LongUSDT, e.ShortUSDT, ts.MaxNetNotionalX)
+		}
+	}
+
+	return ""
+}
+
+func (s *UserSession) exposureSnapshot() exposure {
+	s.PosCacheMu.RLock()
+	defer s.PosCacheMu.RUnlock()
+
+	var e exposure
+	for _, p := range s.PositionsCache {
+		if p.Size <= 0 {
+			continue
+		}
+		e.Count++
+		n := p.Notional
+		if n <= 0 {
+			n = p.Size * p.LastPx // биржа не прислала notional: грубо, ctVal = 1
+		}
+		if p.PosSide == "short" {
+			e.Short++
+			e.ShortUSDT += n
+		} else {
+			e.Long++
+			e.LongUSDT += n
+		}
+	}
+	return e
+}
+
+// cacheOpened — только что открытая позиция сразу попадает в кеш,
+// чтобы следующий сигнал учёл её в лимитах до ближайшего обновления с биржи.
+func (s *UserSession) cacheOpened(instID, posSide string, params *models.TradeParams) {
+	k := models.PosKey{InstID: instID, PosSide: posSide}
+	ctVal := params.CtVal
+	if ctVal <= 0 {
+		ctVal = 1
+	}
+
+	s.PosCacheMu.Lock()
+	defer s.PosCacheMu.Unlock()
+	if s.PositionsCache == nil {
+		s.PositionsCache = make(map[models.PosKey]models.CachedPos)
+	}
+	p := s.PositionsCache[k]
+	p.InstID, p.PosSide = instID, posSide
+	if p.Size <= 0 {
+		p.Entry = params.Entry
+	}
+	p.Size += params.Size
+	p.LastPx = params.Entry
+	p.Notional += params.Size * params.Entry * ctVal
+	p.UpdatedAt = time.Now()
+	s.PositionsCache[k] = p
+}
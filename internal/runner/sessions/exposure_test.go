@@ -0,0 +1,60 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// Лимит notional считается по фактическому размеру сделки, а не по equity·risk/StopPct:
+// ATR-стоп шире StopPct даёт вход меньше, и он проходит.
+func TestCheckNotionalUsesTradeSize(t *testing.T) {
+	s := &UserSession{
+		Settings: &models.UserSettings{Settings: models.Settings{TradingSettings: models.TradingSettings{
+			RiskPct: 1, StopPct: 0.5, MaxTotalNotionalX: 2,
+		}}},
+		PositionsCache: map[models.PosKey]models.CachedPos{
+			{InstID: "ETH-USDT-SWAP", PosSide: "long"}: {InstID: "ETH-USDT-SWAP", PosSide: "long", Size: 10, Notional: 15000},
+		},
+		Equity:   10000,
+		EquityAt: time.Now(),
+	}
+	sig := models.Signal{InstID: fakeInst, Side: models.SideBuy}
+
+	// по StopPct вход был бы 10000·1/0.5 = 20000 USDT; с 2% стопом — 5000
+	small := &models.TradeParams{Entry: 100, Size: 5000, CtVal: 0.01}
+	if reason := s.checkNotional(sig, small); reason != "" {
+		t.Fatalf("5000 USDT entry rejected: %s", reason)
+	}
+	big := &models.TradeParams{Entry: 100, Size: 6000, CtVal: 0.01}
+	if reason := s.checkNotional(sig, big); reason == "" {
+		t.Fatal("6000 USDT entry over 2x equity accepted")
+	}
+}
+
+// Проверка идёт до Confirm: equity только из кеша WS. Устаревшая или
+// ещё не пришедшая equity не блокирует вход и не уходит в REST (Okx == nil).
+func TestCheckNotionalStaleEquityDoesNotBlock(t *testing.T) {
+	s := &UserSession{
+		Settings: &models.UserSettings{Settings: models.Settings{TradingSettings: models.TradingSettings{
+			MaxTotalNotionalX: 1,
+		}}},
+		PositionsCache: map[models.PosKey]models.CachedPos{},
+		Equity:         1000,
+		EquityAt:       time.Now().Add(-equityFresh),
+	}
+	sig := models.Signal{InstID: fakeInst, Side: models.SideBuy}
+	big := &models.TradeParams{Entry: 100, Size: 5000, CtVal: 0.01}
+
+	if reason := s.checkNotional(sig, big); reason != "" {
+		t.Fatalf("stale equity blocked entry: %s", reason)
+	}
+	s.Equity = 0
+	if reason := s.checkNotional(sig, big); reason != "" {
+		t.Fatalf("missing equity blocked entry: %s", reason)
+	}
+	s.Equity, s.EquityAt = 1000, time.Now()
+	if reason := s.checkNotional(sig, big); reason == "" {
+		t.Fatal("5000 USDT entry over 1x fresh equity accepted")
+	}
+}
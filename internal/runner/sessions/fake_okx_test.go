@@ -0,0 +1,159 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+	"trade_bot/internal/modules/config"
+	okx "trade_bot/internal/modules/okx_client/service"
+	"trade_bot/internal/okxfake"
+
+	tgbot "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const fakeInst = "BTC-USDT-SWAP"
+
+// recNotifier — Telegram-заглушка: запоминает сообщения, подтверждает всё.
+type recNotifier struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (n *recNotifier) SendF(ctx context.Context, chatID int64, format string, args ...any) (tgbot.Message, error) {
+	return n.Send(ctx, chatID, fmt.Sprintf(format, args...))
+}
+
+func (n *recNotifier) Send(_ context.Context, _ int64, msg string) (tgbot.Message, error) {
+	n.mu.Lock()
+	n.msgs = append(n.msgs, msg)
+	n.mu.Unlock()
+	return tgbot.Message{}, nil
+}
+
+func (n *recNotifier) Confirm(context.Context, int64, string, time.Duration) bool { return true }
+
+// newFakeOKX — стенд с одним инструментом: тик 0.1, контракт 0.01 BTC, цена 100.
+func newFakeOKX(t *testing.T) *okxfake.Server {
+	t.Helper()
+	srv := okxfake.New()
+	t.Cleanup(srv.Close)
+	srv.AddInstrument(okxfake.Instrument{InstID: fakeInst, TickSz: 0.1, LotSz: 1, MinSz: 1, CtVal: 0.01, Last: 100})
+	return srv
+}
+
+// newFakeSession — сессия, как её собирает EnableUser, с OKX-клиентом на стенд.
+// Стоп 1% (1R = 1.0 от входа 100), риск 1%, трейлинг: BE на 0.6R, Lock на 0.9R -> +0.3R.
+func newFakeSession(t *testing.T, srv *okxfake.Server) *UserSession {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.OKX.RestURL, cfg.OKX.WSURL = srv.URL(), srv.WSURL()
+
+	user := &models.UserSettings{
+		UserID: 1,
+		Settings: models.Settings{
+			TradingSettings: models.TradingSettings{
+				OKXAPIKey:         "key",
+				OKXAPISecret:      "secret",
+				OKXPassphrase:     "pass",
+				Leverage:          5,
+				RiskPct:           1,
+				StopPct:           1,
+				TakeProfitRR:      3,
+				CooldownPerSymbol: time.Minute,
+			},
+			TrailingConfig: models.TrailingConfig{
+				BETriggerR:   0.6,
+				LockTriggerR: 0.9,
+				LockOffsetR:  0.3,
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return &UserSession{
+		UserID:   user.UserID,
+		Settings: user,
+		Notifier: &recNotifier{},
+		Okx:      okx.NewClient(cfg, user),
+
+		Queue:       make(chan models.Signal, 64),
+		Pending:     make(map[string]bool),
+		CooldownTil: make(map[string]time.Time),
+
+		PositionsCache: make(map[models.PosKey]models.CachedPos),
+		Positions:      make(map[string]*models.PositionTrailState),
+
+		Ctx:    ctx,
+		Cancel: cancel,
+
+		LastMsgAt: make(map[string]time.Time),
+	}
+}
+
+// trailState — копия трейл-состояния позиции (nil — стейта нет).
+func trailState(s *UserSession, instID, posSide string) *models.PositionTrailState {
+	s.PosMu.RLock()
+	defer s.PosMu.RUnlock()
+	st, ok := s.Positions[helper.TrailKey(instID, posSide)]
+	if !ok {
+		return nil
+	}
+	cp := *st
+	return &cp
+}
+
+// waitFor — ждёт cond до таймаута.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// openLong — сигнал BUY по 100 через ConfirmWorker; ждёт трейл-стейт.
+func openLong(t *testing.T, s *UserSession) *models.PositionTrailState {
+	t.Helper()
+	go s.ConfirmWorker(s.Ctx)
+	t.Cleanup(func() { close(s.Queue) })
+
+	s.Queue <- models.Signal{InstID: fakeInst, TF: "15m", Side: models.SideBuy, Price: 100, Strategy: models.DefaultStrategy}
+	var st *models.PositionTrailState
+	waitFor(t, "trail state", func() bool {
+		st = trailState(s, fakeInst, "long")
+		return st != nil
+	})
+	return st
+}
+
+// bar1m — закрытая 1m свеча, заканчивающаяся в end.
+func bar1m(end time.Time, o, h, l, c float64) models.CandleTick {
+	return models.CandleTick{
+		InstID: fakeInst, Open: o, High: h, Low: l, Close: c,
+		Start: end.Add(-time.Minute), End: end, TimeframeRaw: "1m",
+	}
+}
+
+// manualPost — «ручной» запрос к стенду мимо клиента бота (без тегов clOrdId).
+func manualPost(t *testing.T, srv *okxfake.Server, path string, body any) {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL()+path, bytes.NewReader(b))
+	req.Header.Set("OK-ACCESS-KEY", "manual")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("manual %s: %v", path, err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,135 @@
+package sessions
+
+import (
+	"context"
+	"log"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// Journal — журнал сделок (Postgres): сигнал -> вход -> события трейлинга -> выход.
+type Journal interface {
+	CreateTrade(ctx context.Context, tr *models.Trade) (int64, error)
+	SetStatus(ctx context.Context, tradeID int64, status models.TradeStatus) error
+	SetOpened(ctx context.Context, tr *models.Trade) error
+	SetClosed(ctx context.Context, tr *models.Trade) error
+	AddEvent(ctx context.Context, ev *models.TradeEvent) error
+
+	// сверка с исполнениями биржи
+	Unsettled(ctx context.Context, userID int64, since time.Time) ([]models.Trade, error)
+	SetRealized(ctx context.Context, tr *models.Trade) error
+	// Totals — итог закрытых сделок с since (для отчётов)
+	Totals(ctx context.Context, userID int64, since time.Time) (models.PnLTotals, error)
+}
+
+// Как и с TrailStore: ошибки журнала только логируем, торговлю не трогаем.
+// tradeID == 0 — сделки в журнале нет (журнал выключен или insert не прошёл).
+
+func journalCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), trailStoreTimeout)
+}
+
+// journalSignal заводит сделку по сигналу и пишет событие signal.
+func (s *UserSession) journalSignal(ctx context.Context, sig models.Signal) int64 {
+	if s.Journal == nil {
+		return 0
+	}
+	ctx, cancel := journalCtx(ctx)
+	defer cancel()
+
+	id, err := s.Journal.CreateTrade(ctx, &models.Trade{
+		UserID:      s.UserID,
+		InstID:      sig.InstID,
+		Side:        sig.Side,
+		Strategy:    sig.Strategy,
+		TF:          sig.TF,
+		Reason:      sig.Reason,
+		SignalPrice: sig.Price,
+		Status:      models.TradeSignal,
+	})
+	if err != nil {
+		log.Printf("[JOURNAL] user=%d create %s: %v", s.UserID, sig.InstID, err)
+		return 0
+	}
+	s.journalEvent(ctx, id, models.EvSignal, map[string]any{
+		"price":  sig.Price,
+		"reason": sig.Reason,
+	})
+	return id
+}
+
+func (s *UserSession) journalEvent(ctx context.Context, tradeID int64, kind models.TradeEventKind, data map[string]any) {
+	if s.Journal == nil || tradeID == 0 {
+		return
+	}
+	ctx, cancel := journalCtx(ctx)
+	defer cancel()
+	if err := s.Journal.AddEvent(ctx, &models.TradeEvent{TradeID: tradeID, Kind: kind, Data: data}); err != nil {
+		log.Printf("[JOURNAL] user=%d trade=%d event %s: %v", s.UserID, tradeID, kind, err)
+	}
+}
+
+// journalStatus — отказ/ошибка до входа: статус + событие с причиной.
+func (s *UserSession) journalStatus(ctx context.Context, tradeID int64, status models.TradeStatus, kind models.TradeEventKind, reason string) {
+	if s.Journal == nil || tradeID == 0 {
+		return
+	}
+	ctx, cancel := journalCtx(ctx)
+	defer cancel()
+	if err := s.Journal.SetStatus(ctx, tradeID, status); err != nil {
+		log.Printf("[JOURNAL] user=%d trade=%d status %s: %v", s.UserID, tradeID, status, err)
+	}
+	s.journalEvent(ctx, tradeID, kind, map[string]any{"reason": reason})
+}
+
+func (s *UserSession) journalOpened(ctx context.Context, tr *models.Trade) {
+	if s.Journal == nil || tr.ID == 0 {
+		return
+	}
+	ctx, cancel := journalCtx(ctx)
+	defer cancel()
+	if err := s.Journal.SetOpened(ctx, tr); err != nil {
+		log.Printf("[JOURNAL] user=%d trade=%d opened: %v", s.UserID, tr.ID, err)
+	}
+}
+
+// journalClose закрывает сделку: R = частичные выходы + остаток по exitPx,
+// в долях стартового объёма. exitPx == 0 — цена выхода неизвестна, считаем только частичные.
+// Итог сделки идёт и в серию убытков риск-лимитов — даже без журнала.
+func (s *UserSession) journalClose(ctx context.Context, st *models.PositionTrailState, exitPx float64, reason string) {
+	s.PosMu.RLock()
+	r := st.RealizedR
+	if exitPx > 0 {
+		r += st.ExitR(exitPx, st.Size)
+	}
+	s.PosMu.RUnlock()
+
+	if exitPx > 0 {
+		s.riskOnClose(ctx, r)
+	}
+	if s.Journal == nil || st.TradeID == 0 {
+		return
+	}
+
+	s.PosMu.RLock()
+	tr := &models.Trade{
+		ID:         st.TradeID,
+		ExitPrice:  exitPx,
+		ExitReason: reason,
+		PnlR:       r,
+		PnlUSDT:    r * st.RiskUSDT,
+		ClosedAt:   time.Now(),
+	}
+	s.PosMu.RUnlock()
+
+	ctx, cancel := journalCtx(ctx)
+	defer cancel()
+	s.journalEvent(ctx, tr.ID, models.EvClosed, map[string]any{
+		"price":  exitPx,
+		"reason": reason,
+		"pnl_r":  tr.PnlR,
+	})
+	if err := s.Journal.SetClosed(ctx, tr); err != nil {
+		log.Printf("[JOURNAL] user=%d trade=%d closed: %v", s.UserID, tr.ID, err)
+	}
+}
@@ -2,7 +2,7 @@ package sessions
 
 import (
 	"context"
-
+	"log"
 	"time"
 )
 
@@ -11,6 +11,8 @@ func (s *UserSession) PositionCacheWorker(ctx context.Context) {
 	defer ticker.Stop()
 
 	_ = s.RefreshPositions(ctx) // сразу при старте
+	s.settle(ctx)
+	s.CheckRisk(ctx)
 
 	for {
 		select {
@@ -18,6 +20,16 @@ func (s *UserSession) PositionCacheWorker(ctx context.Context) {
 			return
 		case <-ticker.C:
 			_ = s.RefreshPositions(ctx)
+			s.settle(ctx)
+			// лимиты по equity (в т.ч. нереализованной) и снятие стопа в новый день
+			s.CheckRisk(ctx)
 		}
 	}
 }
+
+// settle — сверка журнала после обновления позиций: закрытия уже видны.
+func (s *UserSession) settle(ctx context.Context) {
+	if err := s.SettleTrades(ctx); err != nil {
+		log.Printf("[SETTLE] user=%d: %v", s.UserID, err)
+	}
+}
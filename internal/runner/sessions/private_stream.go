@@ -0,0 +1,207 @@
+package sessions
+
+import (
+	"context"
+	"log"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+// PrivateStreamer — биржа умеет пушить ордера/позиции по приватному WS.
+// Необязательно: без него (paper) кеш живёт на опросе PositionCacheWorker.
+type PrivateStreamer interface {
+	StreamPrivate(ctx context.Context) <-chan models.PrivateEvent
+}
+
+// exitHint — фактическая цена и причина закрытия из канала orders,
+// ждёт закрытия позиции в канале positions.
+type exitHint struct {
+	Px     float64
+	Reason string
+	At     time.Time
+}
+
+// exitHintTTL — подсказки старше не используем: позиция успела переоткрыться.
+const exitHintTTL = 10 * time.Minute
+
+// PrivateStreamWorker держит кеш позиций и трейл-стейт в актуальном виде
+// между опросами и сообщает юзеру о срабатывании SL/TP.
+func (s *UserSession) PrivateStreamWorker(ctx context.Context) {
+	ps, ok := s.Okx.(PrivateStreamer)
+	if !ok {
+		return
+	}
+
+	for ev := range ps.StreamPrivate(ctx) {
+		switch ev.Kind {
+		case models.PrivConnected:
+			// за время разрыва могли пропустить закрытия — полная сверка
+			if err := s.RefreshPositions(ctx); err != nil {
+				log.Printf("[WS PRIVATE] user=%d refresh: %v", s.UserID, err)
+			}
+			if err := s.sweepTPs(ctx, s.trailStates()); err != nil {
+				log.Printf("[WS PRIVATE] user=%d tp sweep: %v", s.UserID, err)
+			}
+			s.settle(ctx)
+		case models.PrivPositions:
+			s.onPositions(ctx, ev.Positions)
+		case models.PrivOrders:
+			s.onOrders(ev.Orders)
+		case models.PrivAlgos:
+			s.onAlgos(ctx, ev.Algos)
+		case models.PrivAccount:
+			s.PosCacheMu.Lock()
+			s.Equity = ev.Equity
+			s.EquityAt = time.Now()
+			s.PosCacheMu.Unlock()
+		}
+	}
+}
+
+// trailStates — текущие трейл-состояния сессии.
+func (s *UserSession) trailStates() []*models.PositionTrailState {
+	s.PosMu.RLock()
+	defer s.PosMu.RUnlock()
+	out := make([]*models.PositionTrailState, 0, len(s.Positions))
+	for _, st := range s.Positions {
+		out = append(out, st)
+	}
+	return out
+}
+
+// onPositions — изменившиеся позиции: обновляем кеш, закрытые — закрываем.
+func (s *UserSession) onPositions(ctx context.Context, positions []models.OpenPosition) {
+	now := time.Now()
+	gone := make(map[models.PosKey]float64) // закрытые -> последняя цена из кеша
+
+	s.PosCacheMu.Lock()
+	for _, p := range positions {
+		k := models.PosKey{InstID: p.Symbol, PosSide: p.Side}
+		if p.HoldVol <= 0 {
+			if prev, ok := s.PositionsCache[k]; ok {
+				gone[k] = prev.LastPx
+				delete(s.PositionsCache, k)
+			}
+			continue
+		}
+		s.PositionsCache[k] = cachedPos(p, now)
+	}
+	s.PosCacheAt = now
+	s.PosCacheMu.Unlock()
+
+	var closed []*models.PositionTrailState
+	var resized []*models.PositionTrailState
+	s.PosMu.Lock()
+	for k := range gone {
+		key := helper.TrailKey(k.InstID, k.PosSide)
+		if st, ok := s.Positions[key]; ok {
+			delete(s.Positions, key)
+			closed = append(closed, st)
+		}
+	}
+	// объём уменьшился не нашими руками (частичный TP, ручное закрытие) —
+	// трейлинг должен переставлять SL на фактический объём
+	for _, p := range positions {
+		if p.HoldVol <= 0 {
+			continue
+		}
+		if st, ok := s.Positions[helper.TrailKey(p.Symbol, p.Side)]; ok && p.HoldVol < st.Size {
+			st.Size = p.HoldVol
+			resized = append(resized, st)
+		}
+	}
+	s.PosMu.Unlock()
+
+	for _, st := range resized {
+		s.saveTrail(ctx, st)
+	}
+	for _, st := range closed {
+		s.finishClosed(ctx, st, gone[models.PosKey{InstID: st.InstID, PosSide: st.PosSide}])
+	}
+}
+
+// onOrders — запоминаем фактическое закрытие: цена выхода для журнала.
+func (s *UserSession) onOrders(orders []models.OrderUpdate) {
+	for _, o := range orders {
+		if o.State != "filled" || !o.Closing() || o.AvgPx <= 0 {
+			continue
+		}
+		reason := "CLOSE"
+		switch models.ClOrdKind(o.AlgoClOrdID) {
+		case models.ClOrdSL:
+			reason = "SL"
+		case models.ClOrdTP:
+			reason = "TP"
+		}
+		s.putExitHint(o.InstID, o.PosSide, exitHint{Px: o.AvgPx, Reason: reason, At: time.Now()})
+	}
+}
+
+// onAlgos — сработавшие SL/TP бота: сообщение юзеру и событие в журнал.
+func (s *UserSession) onAlgos(ctx context.Context, algos []models.AlgoUpdate) {
+	for _, a := range algos {
+		if a.State != "effective" {
+			continue
+		}
+		kind := models.ClOrdKind(a.AlgoClOrdID)
+		if kind != models.ClOrdSL && kind != models.ClOrdTP {
+			continue
+		}
+
+		px := a.ActualPx
+		if px <= 0 {
+			px = a.SLTriggerPx
+			if kind == models.ClOrdTP {
+				px = a.TPTriggerPx
+			}
+		}
+
+		var tradeID int64
+		s.PosMu.RLock()
+		if st, ok := s.Positions[helper.TrailKey(a.InstID, a.PosSide)]; ok {
+			tradeID = st.TradeID
+		}
+		s.PosMu.RUnlock()
+
+		if kind == models.ClOrdSL {
+			s.Notifier.SendF(ctx, s.UserID, "🛑 [%s] %s: SL сработал @ %.6f (size=%.4f)",
+				a.InstID, a.PosSide, px, a.ActualSz)
+		} else {
+			s.Notifier.SendF(ctx, s.UserID, "🎯 [%s] %s: TP сработал @ %.6f (size=%.4f)",
+				a.InstID, a.PosSide, px, a.ActualSz)
+		}
+		if kind == models.ClOrdTP {
+			if st := s.tpFilled(a.InstID, a.PosSide, a.AlgoID); st != nil {
+				s.saveTrail(ctx, st)
+			}
+		}
+		s.journalEvent(ctx, tradeID, models.EvAlgoFired, map[string]any{
+			"algo_id": a.AlgoID,
+			"kind":    kind,
+			"price":   px,
+			"size":    a.ActualSz,
+		})
+	}
+}
+
+func (s *UserSession) putExitHint(instID, posSide string, h exitHint) {
+	s.PosMu.Lock()
+	defer s.PosMu.Unlock()
+	if s.exitHints == nil {
+		s.exitHints = make(map[string]exitHint)
+	}
+	s.exitHints[helper.TrailKey(instID, posSide)] = h
+}
+
+func (s *UserSession) takeExitHint(instID, posSide string) (exitHint, bool) {
+	s.PosMu.Lock()
+	defer s.PosMu.Unlock()
+	key := helper.TrailKey(instID, posSide)
+	h, ok := s.exitHints[key]
+	delete(s.exitHints, key)
+	if !ok || time.Since(h.At) > exitHintTTL {
+		return exitHint{}, false
+	}
+	return h, true
+}
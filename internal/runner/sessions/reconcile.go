@@ -0,0 +1,393 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+// ReconcileReport — итог сверки сессии с биржей.
+type ReconcileReport struct {
+	Resumed     []string // трейлинг продолжен по сохранённому состоянию
+	Adopted     []string // позиции бота, состояние собрано заново по его SL/TP
+	Protected   []string // позиции бота без SL: SL выставлен заново, позиция подхвачена
+	Unprotected []string // позиции бота без SL, выставить его не удалось
+	Ignored     []string // ручные позиции — вход без тега бота, не трогаем
+	Canceled    int      // отменено зависших SL/TP
+	Dropped     int      // сохранённые состояния уже закрытых позиций
+}
+
+func (r ReconcileReport) Empty() bool {
+	return len(r.Resumed) == 0 && len(r.Adopted) == 0 && len(r.Protected) == 0 &&
+		len(r.Unprotected) == 0 && len(r.Ignored) == 0 && r.Canceled == 0 && r.Dropped == 0
+}
+
+// botEntryLookback — насколько назад ищем входы бота, если биржа не отдала время открытия.
+const botEntryLookback = 7 * 24 * time.Hour
+
+// Reconcile сверяет позиции и висящие алго на бирже с трейл-состоянием сессии:
+//   - SL/TP без позиции отменяются;
+//   - лишние SL бота на позиции (не снятые при переносе стопа) отменяются;
+//   - ступени TP состояния, которых больше нет на бирже, считаются исполненными;
+//   - позиция с SL бота (algoClOrdId с BotClOrdPrefix) без состояния — подхватывается;
+//   - позиция без SL, но со входом бота (clOrdId с тегом op) — SL ставится заново;
+//   - остальные позиции — ручные, остаются как есть;
+//   - чужие (без тега бота) алго не трогаем никогда;
+//   - состояние без позиции удаляется.
+func (s *UserSession) Reconcile(ctx context.Context) (ReconcileReport, error) {
+	var rep ReconcileReport
+
+	positions, err := s.Okx.OpenPositions(ctx)
+	if err != nil {
+		return rep, fmt.Errorf("Reconcile positions: %w", err)
+	}
+	algos, err := s.Okx.PendingAlgos(ctx)
+	if err != nil {
+		return rep, fmt.Errorf("Reconcile algos: %w", err)
+	}
+
+	open := make(map[string]models.OpenPosition, len(positions))
+	for _, p := range positions {
+		if p.HoldVol > 0 {
+			open[helper.TrailKey(p.Symbol, p.Side)] = p
+		}
+	}
+
+	// closing-алго по позициям
+	type posAlgos struct{ sl, tp []models.AlgoOrder }
+	byPos := make(map[string]*posAlgos)
+	for _, a := range algos {
+		if !a.Closing() {
+			continue // условные входы — не наша забота
+		}
+		k := helper.TrailKey(a.InstID, a.PosSide)
+		if _, ok := open[k]; !ok {
+			s.cancelStale(ctx, a, &rep)
+			continue
+		}
+		pa := byPos[k]
+		if pa == nil {
+			pa = &posAlgos{}
+			byPos[k] = pa
+		}
+		switch models.ClOrdKind(a.AlgoClOrdID) {
+		case models.ClOrdSL:
+			pa.sl = append(pa.sl, a)
+		case models.ClOrdTP:
+			pa.tp = append(pa.tp, a)
+		}
+	}
+
+	s.PosMu.RLock()
+	existing := make(map[string]*models.PositionTrailState, len(s.Positions))
+	for k, st := range s.Positions {
+		existing[k] = st
+	}
+	s.PosMu.RUnlock()
+
+	// состояния закрытых за время простоя позиций
+	for k, st := range existing {
+		if _, ok := open[k]; ok {
+			continue
+		}
+		s.PosMu.Lock()
+		delete(s.Positions, k)
+		s.PosMu.Unlock()
+		s.deleteTrail(ctx, st.InstID, st.PosSide)
+		// цена выхода за простой неизвестна — в журнале только частичные
+		s.journalClose(ctx, st, 0, "OFFLINE")
+		rep.Dropped++
+	}
+
+	// исполнения входов — только если есть позиции без SL бота (nil — ещё не грузили)
+	var entryFills []models.Fill
+
+	keys := make([]string, 0, len(open))
+	for k := range open {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := open[k]
+		label := p.Symbol + " " + p.Side
+		pa := byPos[k]
+		if pa == nil {
+			pa = &posAlgos{}
+		}
+		st := existing[k]
+
+		// один SL бота на позицию: тот, что в состоянии, иначе самый свежий
+		sort.Slice(pa.sl, func(i, j int) bool { return pa.sl[i].CreatedAt.After(pa.sl[j].CreatedAt) })
+		var sl *models.AlgoOrder
+		for i := range pa.sl {
+			if st != nil && pa.sl[i].AlgoID == st.AlgoID {
+				sl = &pa.sl[i]
+			}
+		}
+		if sl == nil && len(pa.sl) > 0 {
+			sl = &pa.sl[0]
+		}
+		for _, a := range pa.sl {
+			if a.AlgoID != sl.AlgoID {
+				s.cancelStale(ctx, a, &rep)
+			}
+		}
+
+		if st != nil {
+			if sl == nil {
+				// SL бота на бирже нет (не встал после входа или снят) — ставим на прежний уровень
+				if sl = s.protectBotPosition(ctx, p, st.SL); sl == nil {
+					rep.Unprotected = append(rep.Unprotected, label)
+					continue
+				}
+			}
+			// ступени TP, исполнившиеся за простой, больше не живые
+			s.dropFilledTPs(ctx, []*models.PositionTrailState{st}, s.liveTPs([]*models.PositionTrailState{st}), algos)
+			if st.AlgoID != sl.AlgoID {
+				s.PosMu.Lock()
+				st.AlgoID = sl.AlgoID
+				st.SL = sl.SLTriggerPx
+				s.PosMu.Unlock()
+				s.saveTrail(ctx, st)
+			}
+			rep.Resumed = append(rep.Resumed, label)
+			continue
+		}
+
+		protected := false
+		if sl == nil {
+			if !s.botEntry(ctx, p, positions, &entryFills) {
+				rep.Ignored = append(rep.Ignored, label)
+				continue
+			}
+			if sl = s.protectBotPosition(ctx, p, 0); sl == nil {
+				rep.Unprotected = append(rep.Unprotected, label)
+				continue
+			}
+			protected = true
+		}
+
+		var tp *models.AlgoOrder
+		if len(pa.tp) > 0 {
+			tp = &pa.tp[0]
+		}
+		st = s.adoptTrail(ctx, p, *sl, tp)
+		if st == nil {
+			rep.Ignored = append(rep.Ignored, label)
+			continue
+		}
+
+		s.PosMu.Lock()
+		s.Positions[k] = st
+		s.PosMu.Unlock()
+		s.saveTrail(ctx, st)
+		if protected {
+			rep.Protected = append(rep.Protected, label)
+		} else {
+			rep.Adopted = append(rep.Adopted, label)
+		}
+	}
+
+	return rep, nil
+}
+
+// adoptTrail собирает трейл-состояние позиции бота по её SL/TP.
+// 1R: из SL, если он ещё по убыточную сторону; иначе из TP и RR; иначе из StopPct.
+// Флаги BE/lock/partial восстанавливаются по положению SL и размеру TP.
+func (s *UserSession) adoptTrail(
+	ctx context.Context,
+	p models.OpenPosition,
+	sl models.AlgoOrder,
+	tp *models.AlgoOrder,
+) *models.PositionTrailState {
+	ts := s.Settings.Settings.TradingSettings
+	tc := s.Settings.Settings.TrailingConfig
+
+	entry := p.EntryPrice
+	if entry <= 0 || sl.SLTriggerPx <= 0 {
+		return nil
+	}
+	dir := 1.0
+	if p.Side == "short" {
+		dir = -1.0
+	}
+
+	rr := ts.TakeProfitRR
+	if rr <= 0 {
+		rr = 2.0
+	}
+
+	// прогресс SL от входа в сторону профита (в цене)
+	slGain := dir * (sl.SLTriggerPx - entry)
+
+	var riskDist float64
+	switch {
+	case slGain < 0:
+		riskDist = -slGain
+	case tp != nil && tp.TPTriggerPx > 0:
+		riskDist = math.Abs(tp.TPTriggerPx-entry) / rr
+	default:
+		riskDist = entry * ts.StopPct / 100.0
+	}
+	if riskDist <= 0 {
+		return nil
+	}
+
+	st := &models.PositionTrailState{
+		InstID:   p.Symbol,
+		PosSide:  p.Side,
+		Entry:    entry,
+		SL:       sl.SLTriggerPx,
+		RiskDist: riskDist,
+		AlgoID:   sl.AlgoID,
+		Size:     p.HoldVol,
+		MFE:      entry,
+		OpenedAt: p.OpenedAt,
+	}
+	if tp != nil {
+		st.TP = tp.TPTriggerPx
+		// после partial SL переставляется на остаток, а TP остаётся на весь объём
+		st.TookPartial = tp.Size > p.HoldVol*1.0001
+	}
+	if st.OpenedAt.IsZero() {
+		st.OpenedAt = time.Now()
+	}
+	if p.LastPrice > 0 && dir*(p.LastPrice-entry) > 0 {
+		st.MFE = p.LastPrice
+	}
+
+	eps := riskDist * 0.01
+	st.MovedToBE = slGain >= tc.BEOffsetR*riskDist-eps
+	st.LockedProfit = tc.LockOffsetR > 0 && slGain >= tc.LockOffsetR*riskDist-eps
+
+	if meta, err := s.Okx.GetInstrumentMeta(ctx, p.Symbol); err == nil {
+		st.TickSz = meta.TickSz
+		st.LotSz = meta.LotSz
+		st.MinSz = meta.MinSz
+	}
+	return st
+}
+
+// botEntry — позиция открыта ордером бота: среди исполнений с момента открытия
+// есть вход с тегом op на ту же сторону. Исполнения грузятся один раз на сверку.
+func (s *UserSession) botEntry(ctx context.Context, p models.OpenPosition, positions []models.OpenPosition, fills *[]models.Fill) bool {
+	if *fills == nil {
+		since := time.Now().Add(-botEntryLookback)
+		for _, op := range positions {
+			if !op.OpenedAt.IsZero() && op.OpenedAt.Before(since) {
+				since = op.OpenedAt
+			}
+		}
+		list, err := s.Okx.Fills(ctx, since.Add(-time.Minute))
+		if err != nil {
+			log.Printf("[RECONCILE] user=%d fills: %v", s.UserID, err)
+			return false
+		}
+		*fills = append(make([]models.Fill, 0, len(list)), list...)
+	}
+
+	for _, f := range *fills {
+		if f.InstID != p.Symbol || f.PosSide != p.Side || models.ClOrdKind(f.ClOrdID) != models.ClOrdOpen {
+			continue
+		}
+		if p.OpenedAt.IsZero() || !f.Ts.Before(p.OpenedAt.Add(-time.Minute)) {
+			return true
+		}
+	}
+	return false
+}
+
+// protectBotPosition — позиция бота без SL (не встал после входа или снят руками):
+// ставим SL на slPx, 0 — по StopPct от входа. nil — цена уже за стопом или биржа отказала.
+func (s *UserSession) protectBotPosition(ctx context.Context, p models.OpenPosition, slPx float64) *models.AlgoOrder {
+	ts := s.Settings.Settings.TradingSettings
+	side := "BUY"
+	if p.Side == "short" {
+		side = "SELL"
+	}
+	meta, err := s.Okx.GetInstrumentMeta(ctx, p.Symbol)
+	if err != nil {
+		log.Printf("[RECONCILE] user=%d meta %s: %v", s.UserID, p.Symbol, err)
+		return nil
+	}
+	sl := slPx
+	if sl <= 0 {
+		if sl, _, _, err = CalcSLTP(side, p.EntryPrice, ts.StopPct/100.0, 1, meta.TickSz); err != nil || sl <= 0 {
+			return nil
+		}
+	}
+	if p.LastPrice > 0 && ((side == "BUY" && p.LastPrice <= sl) || (side == "SELL" && p.LastPrice >= sl)) {
+		return nil
+	}
+
+	algoID, err := s.Okx.PlaceSingleAlgo(ctx, p.Symbol, p.Side, p.HoldVol, sl, false)
+	if err != nil {
+		log.Printf("[RECONCILE] user=%d SL %s %s: %v", s.UserID, p.Symbol, p.Side, err)
+		return nil
+	}
+	return &models.AlgoOrder{
+		AlgoID:      algoID,
+		InstID:      p.Symbol,
+		PosSide:     p.Side,
+		Size:        p.HoldVol,
+		SLTriggerPx: sl,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// cancelStale — отменяет зависшее алго бота; чужие (без тега) не трогаем.
+func (s *UserSession) cancelStale(ctx context.Context, a models.AlgoOrder, rep *ReconcileReport) {
+	if !models.IsBotClOrdID(a.AlgoClOrdID) {
+		return
+	}
+	if err := s.Okx.CancelAlgo(ctx, a.InstID, a.AlgoID); err != nil {
+		log.Printf("[RECONCILE] user=%d cancel %s %s: %v", s.UserID, a.InstID, a.AlgoID, err)
+		return
+	}
+	rep.Canceled++
+}
+
+// ReconcileOnStart — сверка при старте сессии + отчёт в Telegram.
+func (s *UserSession) ReconcileOnStart(ctx context.Context) {
+	rep, err := s.Reconcile(ctx)
+	if err != nil {
+		log.Printf("[RECONCILE] user=%d: %v", s.UserID, err)
+		s.Notifier.SendF(ctx, s.UserID, "⚠️ Сверка с биржей не удалась: %v", err)
+		return
+	}
+	if rep.Empty() {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("🔄 Сверка с биржей после запуска\n")
+	if len(rep.Resumed) > 0 {
+		fmt.Fprintf(&b, "\n♻️ Трейлинг продолжен (%d): %s", len(rep.Resumed), strings.Join(rep.Resumed, ", "))
+	}
+	if len(rep.Adopted) > 0 {
+		fmt.Fprintf(&b, "\n🧩 Подхвачены позиции бота (%d): %s", len(rep.Adopted), strings.Join(rep.Adopted, ", "))
+	}
+	if len(rep.Protected) > 0 {
+		fmt.Fprintf(&b, "\n🛡 Позиции бота без SL, стоп выставлен (%d): %s", len(rep.Protected), strings.Join(rep.Protected, ", "))
+	}
+	if len(rep.Unprotected) > 0 {
+		fmt.Fprintf(&b, "\n⚠️ Позиции бота без SL, выставить не удалось — проверьте вручную (%d): %s", len(rep.Unprotected), strings.Join(rep.Unprotected, ", "))
+	}
+	if len(rep.Ignored) > 0 {
+		fmt.Fprintf(&b, "\n👤 Ручные позиции, бот не трогает (%d): %s", len(rep.Ignored), strings.Join(rep.Ignored, ", "))
+	}
+	if rep.Canceled > 0 {
+		fmt.Fprintf(&b, "\n🧹 Отменено зависших SL/TP: %d", rep.Canceled)
+	}
+	if rep.Dropped > 0 {
+		fmt.Fprintf(&b, "\n🗑 Закрыты за время простоя (состояние сброшено): %d", rep.Dropped)
+	}
+	s.Notifier.Send(ctx, s.UserID, b.String())
+}
@@ -0,0 +1,62 @@
+package sessions
+
+import (
+	"context"
+	"testing"
+	"trade_bot/internal/models"
+	"trade_bot/internal/okxfake"
+)
+
+func TestReconcileOwnership(t *testing.T) {
+	srv := newFakeOKX(t)
+	srv.AddInstrument(okxfake.Instrument{InstID: "ETH-USDT-SWAP", TickSz: 0.01, LotSz: 1, MinSz: 1, CtVal: 0.1, Last: 50})
+	srv.AddInstrument(okxfake.Instrument{InstID: "SOL-USDT-SWAP", TickSz: 0.01, LotSz: 1, MinSz: 1, CtVal: 1, Last: 20})
+	s := newFakeSession(t, srv)
+	ctx := context.Background()
+
+	// вход бота, SL не встал
+	if _, err := s.Okx.PlaceMarket(ctx, fakeInst, 10, 1, 5, 1); err != nil {
+		t.Fatal(err)
+	}
+	// ручная позиция
+	manualPost(t, srv, "/api/v5/trade/order", map[string]any{
+		"instId": "ETH-USDT-SWAP", "side": "buy", "posSide": "long", "ordType": "market", "sz": "5",
+	})
+	// ручной условный ордер и зависший SL бота — оба без позиции
+	manualPost(t, srv, "/api/v5/trade/order-algo", map[string]string{
+		"instId": "SOL-USDT-SWAP", "side": "sell", "posSide": "long", "ordType": "conditional",
+		"sz": "1", "slTriggerPx": "19",
+	})
+	if _, err := s.Okx.PlaceSingleAlgo(ctx, "SOL-USDT-SWAP", "long", 1, 18, false); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := s.Reconcile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rep.Protected) != 1 || rep.Protected[0] != fakeInst+" long" {
+		t.Fatalf("Protected = %v, want the bot position", rep.Protected)
+	}
+	if len(rep.Ignored) != 1 || rep.Ignored[0] != "ETH-USDT-SWAP long" {
+		t.Fatalf("Ignored = %v, want the manual position", rep.Ignored)
+	}
+	if rep.Canceled != 1 {
+		t.Fatalf("Canceled = %d, want only the bot SL", rep.Canceled)
+	}
+
+	st := trailState(s, fakeInst, "long")
+	if st == nil || st.AlgoID == "" || st.SL != 99 {
+		t.Fatalf("trail state = %+v, want SL at 99 from StopPct", st)
+	}
+	var manualLive bool
+	for _, a := range srv.Algos() {
+		if a.InstID == "SOL-USDT-SWAP" && !models.IsBotClOrdID(a.AlgoClOrdID) {
+			manualLive = a.State == "live"
+		}
+	}
+	if !manualLive {
+		t.Fatal("manual algo was canceled")
+	}
+}
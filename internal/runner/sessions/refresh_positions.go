@@ -2,6 +2,7 @@ package sessions
 
 import (
 	"context"
+	"math"
 	"trade_bot/internal/helper"
 
 	"time"
@@ -14,31 +15,26 @@ func (s *UserSession) RefreshPositions(ctx context.Context) error {
 		return err
 	}
 
+	now := time.Now()
 	next := make(map[models.PosKey]models.CachedPos, len(positions))
 	for _, p := range positions {
 		if p.HoldVol <= 0 {
 			continue
 		}
 		k := models.PosKey{InstID: p.Symbol, PosSide: p.Side}
-		next[k] = models.CachedPos{
-			InstID:  p.Symbol,
-			PosSide: p.Side,
-			Size:    p.HoldVol,
-			Entry:   p.EntryPrice,
-			LastPx:  p.LastPrice,
-		}
+		next[k] = cachedPos(p, now)
 	}
 
-	now := time.Now()
-
 	s.PosCacheMu.Lock()
+	prev := s.PositionsCache
 	s.PositionsCache = next
 	s.PosCacheAt = now
 	s.PosCacheMu.Unlock()
 
 	// подчистим трейл-стейт для закрытых позиций
+	var closed []*models.PositionTrailState
 	s.PosMu.Lock()
-	for key := range s.Positions {
+	for key, st := range s.Positions {
 		inst, side, ok := helper.SplitTrailKey(key)
 		if !ok {
 			delete(s.Positions, key)
@@ -46,9 +42,55 @@ func (s *UserSession) RefreshPositions(ctx context.Context) error {
 		}
 		if _, ok := next[models.PosKey{InstID: inst, PosSide: side}]; !ok {
 			delete(s.Positions, key)
+			closed = append(closed, st)
 		}
 	}
 	s.PosMu.Unlock()
 
+	for _, st := range closed {
+		s.finishClosed(ctx, st, prev[models.PosKey{InstID: st.InstID, PosSide: st.PosSide}].LastPx)
+	}
+
 	return nil
 }
+
+func cachedPos(p models.OpenPosition, now time.Time) models.CachedPos {
+	return models.CachedPos{
+		InstID:    p.Symbol,
+		PosSide:   p.Side,
+		Size:      p.HoldVol,
+		Entry:     p.EntryPrice,
+		LastPx:    p.LastPrice,
+		UpdatedAt: now,
+
+		Upl:      p.UnrealizedPnl,
+		UplPct:   p.UnrealizedPnlPct,
+		Realised: p.Realised,
+		Notional: p.NotionalUSD,
+	}
+}
+
+// finishClosed — позиция закрылась на бирже (трейл-стейт уже убран из Positions):
+// чистим хранилище и закрываем сделку в журнале. Цена выхода — из приватного WS,
+// если исполнение уже пришло, иначе SL или TP — что ближе к последней цене.
+func (s *UserSession) finishClosed(ctx context.Context, st *models.PositionTrailState, last float64) {
+	s.deleteTrail(ctx, st.InstID, st.PosSide)
+
+	px, reason := exitEstimate(st, last)
+	if h, ok := s.takeExitHint(st.InstID, st.PosSide); ok {
+		px, reason = h.Px, h.Reason
+	}
+	s.journalClose(ctx, st, px, reason)
+	s.CheckRisk(ctx)
+}
+
+// exitEstimate — оценка выхода позиции, закрытой биржевым SL/TP.
+func exitEstimate(st *models.PositionTrailState, last float64) (float64, string) {
+	if st.TP <= 0 || last <= 0 {
+		return st.SL, "SL"
+	}
+	if math.Abs(last-st.TP) < math.Abs(last-st.SL) {
+		return st.TP, "TP"
+	}
+	return st.SL, "SL"
+}
@@ -0,0 +1,281 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+// equityFresh — equity из приватного WS свежее этого не перезапрашиваем.
+const equityFresh = 2 * time.Minute
+
+// riskState — счётчики риск-лимитов (под s.mu). Каждое изменение пишется в
+// RiskStore, при старте сессии они поднимаются оттуда (RestoreRisk); старт дня
+// за прошлые сутки не берём — его CheckRisk восстанавливает из журнала.
+type riskState struct {
+	day        time.Time // UTC-день, к которому относится dayStartEq
+	dayStartEq float64
+	peakEq     float64
+	lossStreak int
+
+	haltedUntil time.Time
+	haltReason  string
+}
+
+// RiskStatus — состояние риск-стопа для Telegram.
+type RiskStatus struct {
+	Halted     bool
+	Reason     string
+	Until      time.Time
+	DayStartEq float64
+	PeakEq     float64
+	LossStreak int
+}
+
+// CheckRisk обновляет equity-счётчики и проверяет лимиты.
+// Возвращает true, если приём сигналов остановлен (причина — во втором значении).
+func (s *UserSession) CheckRisk(ctx context.Context) (bool, string) {
+	ts := s.Settings.Settings.TradingSettings
+	if !ts.RiskLimitsEnabled() {
+		return false, ""
+	}
+
+	now := time.Now().UTC()
+	today := utcDay(now)
+
+	eq, err := s.equity(ctx)
+	if err != nil {
+		log.Printf("[RISK] user=%d equity: %v", s.UserID, err)
+		eq = 0
+	}
+
+	// первый расчёт за сессию: убыток сегодняшних закрытых сделок уже в equity,
+	// поэтому старт дня = текущая equity минус их PnL
+	var todayPnL float64
+	s.mu.Lock()
+	first := s.risk.day.IsZero()
+	s.mu.Unlock()
+	if first && eq > 0 && s.Journal != nil {
+		jctx, cancel := journalCtx(ctx)
+		if t, err := s.Journal.Totals(jctx, s.UserID, today); err == nil {
+			todayPnL = t.PnlUSDT
+		}
+		cancel()
+	}
+
+	s.mu.Lock()
+	r := &s.risk
+	var resumed, changed bool
+	if !r.haltedUntil.IsZero() && !now.Before(r.haltedUntil) {
+		// новый день — начинаем с чистого листа, иначе просадка сработает снова
+		r.haltedUntil, r.haltReason = time.Time{}, ""
+		r.lossStreak = 0
+		r.peakEq = 0
+		resumed, changed = true, true
+	}
+	if eq > 0 {
+		if !r.day.Equal(today) {
+			r.day = today
+			r.dayStartEq = eq - todayPnL
+			changed = true
+		}
+		if eq > r.peakEq {
+			r.peakEq = eq
+			changed = true
+		}
+	}
+	if !r.haltedUntil.IsZero() {
+		reason := r.haltReason
+		s.mu.Unlock()
+		if changed {
+			s.saveRisk(ctx)
+		}
+		return true, reason
+	}
+	reason := r.breachLocked(ts, eq)
+	if reason != "" {
+		// ставим стоп под тем же локом: параллельная проверка его уже увидит
+		r.haltedUntil = utcDay(now).AddDate(0, 0, 1)
+		r.haltReason = reason
+		changed = true
+	}
+	until := r.haltedUntil
+	s.mu.Unlock()
+
+	if changed {
+		s.saveRisk(ctx)
+	}
+	if resumed {
+		s.Notifier.SendF(ctx, s.UserID, "✅ Риск-стоп снят: новый день UTC, сигналы снова принимаются")
+	}
+	if reason == "" {
+		return false, ""
+	}
+	s.haltTrading(ctx, reason, until)
+	return true, reason
+}
+
+// breachLocked — текст нарушенного лимита с цифрами ("" — всё в норме).
+// eq == 0 — equity неизвестна, проверяем только серию убытков.
+func (r *riskState) breachLocked(ts models.TradingSettings, eq float64) string {
+	if ts.MaxConsecLosses > 0 && r.lossStreak >= ts.MaxConsecLosses {
+		return fmt.Sprintf("%d убыточных сделок подряд (лимит %d)", r.lossStreak, ts.MaxConsecLosses)
+	}
+	if eq <= 0 {
+		return ""
+	}
+
+	loss := r.dayStartEq - eq
+	if ts.MaxDailyLossUSDT > 0 && loss >= ts.MaxDailyLossUSDT {
+		return fmt.Sprintf("дневной убыток %.2f USDT (лимит %.2f USDT; equity %.2f → %.2f)",
+			loss, ts.MaxDailyLossUSDT, r.dayStartEq, eq)
+	}
+	if ts.MaxDailyLossPct > 0 && r.dayStartEq > 0 {
+		if pct := loss / r.dayStartEq * 100; pct >= ts.MaxDailyLossPct {
+			return fmt.Sprintf("дневной убыток %.2f%% (лимит %.2f%%; equity %.2f → %.2f)",
+				pct, ts.MaxDailyLossPct, r.dayStartEq, eq)
+		}
+	}
+	if ts.MaxDrawdownPct > 0 && r.peakEq > 0 {
+		if dd := (r.peakEq - eq) / r.peakEq * 100; dd >= ts.MaxDrawdownPct {
+			return fmt.Sprintf("просадка от пика %.2f%% (лимит %.2f%%; пик %.2f, сейчас %.2f)",
+				dd, ts.MaxDrawdownPct, r.peakEq, eq)
+		}
+	}
+	return ""
+}
+
+// haltTrading — стоп уже стоит до until: сообщаем юзеру, опционально закрываем всё.
+func (s *UserSession) haltTrading(ctx context.Context, reason string, until time.Time) {
+	log.Printf("[RISK] user=%d halt until %s: %s", s.UserID, until.Format(time.RFC3339), reason)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧯 Риск-стоп: %s\n", reason)
+	fmt.Fprintf(&b, "Новые сигналы не принимаются до 00:00 UTC (через %s).\n",
+		strings.TrimSuffix(time.Until(until).Round(time.Minute).String(), "0s"))
+	if s.Settings.Settings.TradingSettings.FlattenOnHalt {
+		closed, errs := s.flattenAll(ctx)
+		fmt.Fprintf(&b, "Закрыто позиций: %d", closed)
+		if len(errs) > 0 {
+			fmt.Fprintf(&b, ", ошибки: %s", strings.Join(errs, "; "))
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("Открытые позиции остаются со своими SL/TP.\n")
+	}
+	b.WriteString("Снять вручную: /risk_reset")
+
+	s.Notifier.Send(ctx, s.UserID, b.String())
+}
+
+// flattenAll закрывает все позиции по рынку и снимает SL/TP бота по ним.
+func (s *UserSession) flattenAll(ctx context.Context) (int, []string) {
+	positions, err := s.Okx.OpenPositions(ctx)
+	if err != nil {
+		return 0, []string{"позиции: " + err.Error()}
+	}
+	algos, err := s.Okx.PendingAlgos(ctx)
+	if err != nil {
+		log.Printf("[RISK] user=%d pending algos: %v", s.UserID, err)
+	}
+
+	var closed int
+	var errs []string
+	for _, p := range positions {
+		if p.HoldVol <= 0 {
+			continue
+		}
+		if _, err := s.Okx.CloseMarket(ctx, p.Symbol, p.Side, p.HoldVol); err != nil {
+			errs = append(errs, p.Symbol+": "+err.Error())
+			continue
+		}
+		closed++
+
+		for _, a := range algos {
+			if a.InstID == p.Symbol && a.PosSide == p.Side && models.IsBotClOrdID(a.AlgoClOrdID) {
+				_ = s.Okx.CancelAlgo(ctx, a.InstID, a.AlgoID)
+			}
+		}
+
+		key := helper.TrailKey(p.Symbol, p.Side)
+		s.PosMu.Lock()
+		st, ok := s.Positions[key]
+		delete(s.Positions, key)
+		s.PosMu.Unlock()
+		if ok {
+			s.deleteTrail(ctx, st.InstID, st.PosSide)
+			s.journalClose(ctx, st, p.LastPrice, "RISK_HALT")
+		}
+	}
+
+	_ = s.RefreshPositions(ctx)
+	return closed, errs
+}
+
+// ResetRisk — ручной сброс: снимаем стоп и считаем лимиты от текущей equity.
+func (s *UserSession) ResetRisk(ctx context.Context) {
+	eq, err := s.equity(ctx)
+	if err != nil {
+		eq = 0
+	}
+
+	s.mu.Lock()
+	s.risk = riskState{}
+	if eq > 0 {
+		s.risk.day = utcDay(time.Now().UTC())
+		s.risk.dayStartEq = eq
+		s.risk.peakEq = eq
+	}
+	s.mu.Unlock()
+	s.saveRisk(ctx)
+}
+
+func (s *UserSession) RiskStatus() RiskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return RiskStatus{
+		Halted:     !s.risk.haltedUntil.IsZero() && time.Now().Before(s.risk.haltedUntil),
+		Reason:     s.risk.haltReason,
+		Until:      s.risk.haltedUntil,
+		DayStartEq: s.risk.dayStartEq,
+		PeakEq:     s.risk.peakEq,
+		LossStreak: s.risk.lossStreak,
+	}
+}
+
+// riskOnClose — итог закрытой сделки для серии убытков (r в R).
+func (s *UserSession) riskOnClose(ctx context.Context, r float64) {
+	s.mu.Lock()
+	prev := s.risk.lossStreak
+	switch {
+	case r < 0:
+		s.risk.lossStreak++
+	case r > 0:
+		s.risk.lossStreak = 0
+	}
+	changed := s.risk.lossStreak != prev
+	s.mu.Unlock()
+
+	if changed {
+		s.saveRisk(ctx)
+	}
+}
+
+// equity — из приватного WS, если свежая, иначе с биржи.
+func (s *UserSession) equity(ctx context.Context) (float64, error) {
+	s.PosCacheMu.RLock()
+	eq, at := s.Equity, s.EquityAt
+	s.PosCacheMu.RUnlock()
+	if eq > 0 && time.Since(at) < equityFresh {
+		return eq, nil
+	}
+	return s.Okx.Equity(ctx)
+}
+
+func utcDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,67 @@
+package sessions
+
+import (
+	"context"
+	"log"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// RiskStore — постоянное хранилище риск-счётчиков (Postgres),
+// чтобы рестарт не снимал риск-стоп и не обнулял серию убытков и пик.
+// LoadRisk: nil без ошибки — сохранённого состояния нет.
+type RiskStore interface {
+	SaveRisk(ctx context.Context, userID int64, st *models.RiskState) error
+	LoadRisk(ctx context.Context, userID int64) (*models.RiskState, error)
+}
+
+// saveRisk пишет снимок s.risk. Снимок берётся под riskSaveMu,
+// поэтому параллельные записи не откатывают состояние назад.
+// Ошибку только логируем — торговлю из-за БД не останавливаем.
+func (s *UserSession) saveRisk(ctx context.Context) {
+	if s.Risk == nil {
+		return
+	}
+	s.riskSaveMu.Lock()
+	defer s.riskSaveMu.Unlock()
+
+	s.mu.Lock()
+	snap := s.risk.state()
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), trailStoreTimeout)
+	defer cancel()
+	if err := s.Risk.SaveRisk(ctx, s.UserID, snap); err != nil {
+		log.Printf("[RISK] user=%d save: %v", s.UserID, err)
+	}
+}
+
+// RestoreRisk — счётчики с прошлого запуска (до старта воркеров).
+// Старт дня берём только сегодняшний: за другой день CheckRisk
+// пересчитает его из журнала.
+func (s *UserSession) RestoreRisk(st *models.RiskState) {
+	r := riskState{
+		peakEq:      st.PeakEq,
+		lossStreak:  st.LossStreak,
+		haltedUntil: st.HaltedUntil,
+		haltReason:  st.HaltReason,
+	}
+	if st.Day.Equal(utcDay(time.Now().UTC())) {
+		r.day, r.dayStartEq = st.Day, st.DayStartEq
+	}
+
+	s.mu.Lock()
+	s.risk = r
+	s.mu.Unlock()
+}
+
+func (r *riskState) state() *models.RiskState {
+	return &models.RiskState{
+		Day:         r.day,
+		DayStartEq:  r.dayStartEq,
+		PeakEq:      r.peakEq,
+		LossStreak:  r.lossStreak,
+		HaltedUntil: r.haltedUntil,
+		HaltReason:  r.haltReason,
+	}
+}
@@ -0,0 +1,75 @@
+package sessions
+
+import (
+	"context"
+	"testing"
+	"time"
+	"trade_bot/internal/models"
+)
+
+type memRiskStore struct {
+	st *models.RiskState
+}
+
+func (m *memRiskStore) SaveRisk(_ context.Context, _ int64, st *models.RiskState) error {
+	m.st = st
+	return nil
+}
+
+func (m *memRiskStore) LoadRisk(context.Context, int64) (*models.RiskState, error) {
+	return m.st, nil
+}
+
+func newRiskSession(store RiskStore) *UserSession {
+	return &UserSession{
+		UserID: 1,
+		Settings: &models.UserSettings{Settings: models.Settings{
+			TradingSettings: models.TradingSettings{MaxConsecLosses: 2, MaxDrawdownPct: 10},
+		}},
+		Notifier: &recNotifier{},
+		Risk:     store,
+		Equity:   1000,
+		EquityAt: time.Now(),
+	}
+}
+
+// Серия убытков, пик и риск-стоп переживают рестарт: новая сессия
+// поднимает их из стора и остаётся на стопе до конца дня.
+func TestRiskStateSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store := &memRiskStore{}
+
+	s := newRiskSession(store)
+	if halted, _ := s.CheckRisk(ctx); halted {
+		t.Fatal("halted before any loss")
+	}
+	s.riskOnClose(ctx, -1)
+	if store.st == nil || store.st.LossStreak != 1 || store.st.PeakEq != 1000 {
+		t.Fatalf("saved = %+v, want streak 1, peak 1000", store.st)
+	}
+
+	// рестарт до второго убытка: серия продолжается
+	s = newRiskSession(store)
+	s.RestoreRisk(store.st)
+	s.riskOnClose(ctx, -1)
+	halted, reason := s.CheckRisk(ctx)
+	if !halted {
+		t.Fatal("streak of 2 losses must halt")
+	}
+	if store.st.HaltReason != reason || !store.st.HaltedUntil.After(time.Now()) {
+		t.Fatalf("saved halt = %q until %s", store.st.HaltReason, store.st.HaltedUntil)
+	}
+
+	// рестарт на стопе: стоп держится, причина та же
+	s = newRiskSession(store)
+	s.RestoreRisk(store.st)
+	if halted, got := s.CheckRisk(ctx); !halted || got != reason {
+		t.Fatalf("after restart halted=%v reason=%q, want %q", halted, got, reason)
+	}
+
+	// ручной сброс тоже пишется
+	s.ResetRisk(ctx)
+	if !store.st.HaltedUntil.IsZero() || store.st.LossStreak != 0 {
+		t.Fatalf("after reset saved = %+v", store.st)
+	}
+}
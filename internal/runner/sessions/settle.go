@@ -0,0 +1,148 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+	"trade_bot/internal/models"
+)
+
+const (
+	// /account/bills отдаёт funding только за 7 дней — старше не сверяем
+	settleLookback = 7 * 24 * time.Hour
+	// closed_at в журнале — момент, когда бот увидел закрытие, исполнение раньше;
+	// запас на расхождение часов
+	settleSlack = time.Minute
+)
+
+// SettleTrades сверяет закрытые сделки журнала с исполнениями OKX:
+// реальный PnL закрывающих fills + комиссии входа/выхода + funding за время удержания.
+// Сделка без полного набора закрывающих исполнений ждёт следующего прохода.
+func (s *UserSession) SettleTrades(ctx context.Context) error {
+	if s.Journal == nil {
+		return nil
+	}
+
+	trades, err := s.Journal.Unsettled(ctx, s.UserID, time.Now().Add(-settleLookback))
+	if err != nil {
+		return fmt.Errorf("SettleTrades unsettled: %w", err)
+	}
+	if len(trades) == 0 {
+		return nil
+	}
+
+	since := time.Now()
+	for _, tr := range trades {
+		from := tr.OpenedAt
+		if from.IsZero() {
+			from = tr.ClosedAt.Add(-settleLookback)
+		}
+		if from.Before(since) {
+			since = from
+		}
+	}
+	since = since.Add(-settleSlack)
+
+	fills, err := s.Okx.Fills(ctx, since)
+	if err != nil {
+		return fmt.Errorf("SettleTrades fills: %w", err)
+	}
+	funding, err := s.Okx.FundingBills(ctx, since)
+	if err != nil {
+		return fmt.Errorf("SettleTrades funding: %w", err)
+	}
+
+	for i := range trades {
+		tr := &trades[i]
+		if !settleTrade(tr, fills, funding) {
+			continue
+		}
+		if err := s.Journal.SetRealized(ctx, tr); err != nil {
+			log.Printf("[SETTLE] user=%d trade=%d: %v", s.UserID, tr.ID, err)
+			continue
+		}
+		log.Printf("[SETTLE] user=%d trade=%d %s %s net=%.4f (gross=%.4f fee=%.4f funding=%.4f) R=%.2f",
+			s.UserID, tr.ID, tr.InstID, tr.PosSide,
+			tr.PnlUSDT, tr.GrossUSDT, tr.FeeUSDT, tr.FundingUSDT, tr.PnlR)
+	}
+	return nil
+}
+
+// settleTrade раскладывает исполнения на сделку tr и заполняет её PnL.
+// Вход — fills всех ордеров входа сделки; выходы — закрывающие fills той же позиции
+// (instId + posSide) между входом и closed_at. На instId:posSide в каждый
+// момент максимум одна сделка бота, так что окно однозначно.
+// false — данных по сделке на бирже пока нет (или не все).
+func settleTrade(tr *models.Trade, fills []models.Fill, funding []models.Bill) bool {
+	entryIDs := tr.EntryOrderIDs
+	if len(entryIDs) == 0 && tr.OrderID != "" {
+		entryIDs = []string{tr.OrderID}
+	}
+	if len(entryIDs) == 0 {
+		return false
+	}
+
+	var (
+		openAt          time.Time
+		openSz, closeSz float64
+		gross, fee      float64
+		exitNotional    float64
+		lastClose       time.Time
+	)
+	for _, f := range fills {
+		if !slices.Contains(entryIDs, f.OrdID) {
+			continue
+		}
+		if openAt.IsZero() || f.Ts.Before(openAt) {
+			openAt = f.Ts
+		}
+		openSz += f.Sz
+		fee += f.Fee
+	}
+	if openSz <= 0 {
+		return false
+	}
+
+	closeSide := "sell"
+	if tr.PosSide == "short" {
+		closeSide = "buy"
+	}
+	until := tr.ClosedAt.Add(settleSlack)
+	for _, f := range fills {
+		if f.InstID != tr.InstID || f.PosSide != tr.PosSide || f.Side != closeSide ||
+			f.Ts.Before(openAt) || f.Ts.After(until) {
+			continue
+		}
+		closeSz += f.Sz
+		gross += f.Pnl
+		fee += f.Fee
+		exitNotional += f.Px * f.Sz
+		if f.Ts.After(lastClose) {
+			lastClose = f.Ts
+		}
+	}
+	if closeSz < openSz*0.999 {
+		return false
+	}
+
+	// funding по instId: у бота на инструменте одна сторона, hedge-позиции
+	// в обе стороны сразу делят funding на обе сделки
+	var fund float64
+	for _, b := range funding {
+		if b.InstID == tr.InstID && !b.Ts.Before(openAt) && !b.Ts.After(lastClose) {
+			fund += b.BalChg
+		}
+	}
+
+	tr.GrossUSDT = gross
+	tr.FeeUSDT = fee
+	tr.FundingUSDT = fund
+	tr.PnlUSDT = gross + fee + fund
+	tr.ExitPrice = exitNotional / closeSz
+	if tr.Params != nil && tr.Params.RiskUSDT > 0 {
+		tr.PnlR = tr.PnlUSDT / tr.Params.RiskUSDT
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package sessions
+
+import (
+	"math"
+	"testing"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// limit_market: лимит набрал часть, остаток — по рынку; комиссии входа — с обоих ордеров.
+func TestSettleTradeSumsAllEntryOrders(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	tr := &models.Trade{
+		InstID: fakeInst, PosSide: "long",
+		OrderID: "lim", EntryOrderIDs: []string{"lim", "mkt"},
+		Params:   &models.TradeParams{RiskUSDT: 10},
+		OpenedAt: t0, ClosedAt: t0.Add(time.Hour),
+	}
+	fills := []models.Fill{
+		{OrdID: "lim", InstID: fakeInst, Side: "buy", PosSide: "long", Px: 100, Sz: 6, Fee: -0.1, Ts: t0},
+		{OrdID: "mkt", InstID: fakeInst, Side: "buy", PosSide: "long", Px: 100.2, Sz: 4, Fee: -0.2, Ts: t0.Add(20 * time.Second)},
+		{OrdID: "sl", InstID: fakeInst, Side: "sell", PosSide: "long", Px: 102, Sz: 10, Fee: -0.5, Pnl: 19.2, Ts: t0.Add(30 * time.Minute)},
+	}
+
+	if !settleTrade(tr, fills, nil) {
+		t.Fatal("trade not settled")
+	}
+	if math.Abs(tr.FeeUSDT-(-0.8)) > 1e-9 {
+		t.Fatalf("FeeUSDT = %.4f, want -0.8 (both entry orders + exit)", tr.FeeUSDT)
+	}
+	if math.Abs(tr.PnlUSDT-18.4) > 1e-9 || math.Abs(tr.PnlR-1.84) > 1e-9 {
+		t.Fatalf("PnL = %.4f USDT / %.4fR, want 18.4 / 1.84", tr.PnlUSDT, tr.PnlR)
+	}
+
+	// выход ещё не исполнен — ждём
+	tr.ClosedAt = t0.Add(10 * time.Minute)
+	if settleTrade(tr, fills, nil) {
+		t.Fatal("settled before the exit fills")
+	}
+}
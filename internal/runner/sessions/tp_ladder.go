@@ -0,0 +1,228 @@
+package sessions
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+// TPPrice — TP на rr*1R от входа, округлённый до тика в безопасную сторону.
+func TPPrice(side string, entry, riskDist, rr, tickSz float64) float64 {
+	// BUY: TP выше -> roundUp
+	// SELL: TP ниже -> roundDown
+	if side == "BUY" {
+		return helper.RoundUpToTick(entry+rr*riskDist, tickSz)
+	}
+	return helper.RoundDownToTick(entry-rr*riskDist, tickSz)
+}
+
+// TPLadder — ступени TP-лестницы без algoId: цены от 1R, объёмы — доли size.
+// Ступени без R/Frac отбрасываются, лишние сверх MaxTPLevels — тоже.
+// Общая для бота и бэктеста.
+func TPLadder(side string, entry, riskDist, size, tickSz, lotSz, minSz float64, ladder []models.TPLevel) []models.TPOrder {
+	levels := make([]models.TPLevel, 0, len(ladder))
+	for _, l := range ladder {
+		if l.R > 0 && l.Frac > 0 {
+			levels = append(levels, l)
+		}
+	}
+	sort.SliceStable(levels, func(i, j int) bool { return levels[i].R < levels[j].R })
+	if len(levels) > models.MaxTPLevels {
+		levels = levels[:models.MaxTPLevels]
+	}
+
+	tps := make([]models.TPOrder, len(levels))
+	for i, l := range levels {
+		tps[i] = models.TPOrder{
+			Price: TPPrice(side, entry, riskDist, l.R, tickSz),
+			Frac:  l.Frac,
+		}
+	}
+	fitTPSizes(tps, size, size, lotSz, minSz)
+	return tps
+}
+
+// fitTPSizes — объёмы ступеней: Frac*init нарастающим итогом, но не больше size
+// (после частичных выходов дальние ступени урезаются), вниз к lotSz; меньше minSz — 0.
+func fitTPSizes(tps []models.TPOrder, init, size, lotSz, minSz float64) {
+	left := size
+	for i := range tps {
+		sz := math.Min(tps[i].Frac*init, left)
+		if lotSz > 0 {
+			sz = math.Floor(sz/lotSz+1e-9) * lotSz
+		}
+		if sz <= 0 || (minSz > 0 && sz < minSz) {
+			sz = 0
+		}
+		tps[i].Size = sz
+		left -= sz
+	}
+}
+
+// resizeTPs — переставляет невыполненные ступени лестницы под текущий st.Size.
+// Вызывается после переноса SL: SL уже стоит на остаток, TP не должны его превышать.
+// Сначала сверяет ступени с биржей: исполненная без события WS не должна
+// отъедать объём у живых.
+func (s *UserSession) resizeTPs(ctx context.Context, st *models.PositionTrailState) {
+	if err := s.sweepTPs(ctx, []*models.PositionTrailState{st}); err != nil {
+		log.Printf("[TP] user=%d %s %s: resize skipped: %v", s.UserID, st.InstID, st.PosSide, err)
+		return
+	}
+
+	s.PosMu.RLock()
+	tps := st.TPs
+	init, size := st.InitSize, st.Size
+	s.PosMu.RUnlock()
+	if init <= 0 {
+		init = size
+	}
+
+	// живые ступени по порядку, их новые объёмы
+	var live []int
+	for i, tp := range tps {
+		if tp.AlgoID != "" {
+			live = append(live, i)
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	// доля уже исполненных ступеней не учитывается: их объём ушёл из st.Size
+	want := make([]models.TPOrder, len(live))
+	for j, i := range live {
+		want[j] = tps[i]
+	}
+	fitTPSizes(want, init, size, st.LotSz, st.MinSz)
+
+	// переставленные ступени: индекс -> algoId до перестановки
+	moved := make(map[int]string)
+	for j, i := range live {
+		if math.Abs(want[j].Size-tps[i].Size) < 1e-12 {
+			continue
+		}
+		moved[i] = tps[i].AlgoID
+		_ = s.Okx.CancelAlgo(ctx, st.InstID, tps[i].AlgoID)
+		tps[i].AlgoID = ""
+		tps[i].Size = want[j].Size
+		if want[j].Size <= 0 {
+			continue
+		}
+		algoID, err := s.Okx.PlaceSingleAlgo(ctx, st.InstID, st.PosSide, want[j].Size, tps[i].Price, true)
+		if err != nil {
+			tps[i].Size = 0
+			s.journalEvent(ctx, st.TradeID, models.EvError, map[string]any{"stage": "tp_resize", "error": err.Error()})
+			continue
+		}
+		tps[i].AlgoID = algoID
+		s.journalEvent(ctx, st.TradeID, models.EvTPPlaced, map[string]any{
+			"algo_id": algoID,
+			"price":   tps[i].Price,
+			"size":    want[j].Size,
+		})
+	}
+	if len(moved) == 0 {
+		return
+	}
+
+	// пишем только свои ступени и только если их не трогали, пока ходили на биржу
+	// (старая ступень успела исполниться — tpFilled её уже очистил)
+	var orphans []string
+	s.PosMu.Lock()
+	for i, prev := range moved {
+		if st.TPs[i].AlgoID != prev {
+			if tps[i].AlgoID != "" {
+				orphans = append(orphans, tps[i].AlgoID)
+			}
+			continue
+		}
+		st.TPs[i] = tps[i]
+	}
+	s.PosMu.Unlock()
+
+	// ступень уже исполнена — новый TP на её объём лишний
+	for _, algoID := range orphans {
+		_ = s.Okx.CancelAlgo(ctx, st.InstID, algoID)
+	}
+}
+
+// tpFilled — ступень лестницы исполнилась (приватный WS): больше её не трогаем.
+// Возвращает изменённое состояние (nil — ступень не наша).
+func (s *UserSession) tpFilled(instID, posSide, algoID string) *models.PositionTrailState {
+	s.PosMu.Lock()
+	defer s.PosMu.Unlock()
+	st, ok := s.Positions[helper.TrailKey(instID, posSide)]
+	if !ok {
+		return nil
+	}
+	for i := range st.TPs {
+		if st.TPs[i].AlgoID == algoID {
+			st.TPs[i] = models.TPOrder{Price: st.TPs[i].Price, Frac: st.TPs[i].Frac}
+			return st
+		}
+	}
+	return nil
+}
+
+// liveTPs — algoId выставленных ступеней (снимок до запроса висящих алго:
+// ступень, выставленную позже, по этому ответу не судим).
+func (s *UserSession) liveTPs(sts []*models.PositionTrailState) map[string]bool {
+	s.PosMu.RLock()
+	defer s.PosMu.RUnlock()
+	known := make(map[string]bool)
+	for _, st := range sts {
+		for _, tp := range st.TPs {
+			if tp.AlgoID != "" {
+				known[tp.AlgoID] = true
+			}
+		}
+	}
+	return known
+}
+
+// dropFilledTPs — ступени из known, которых нет среди висящих алго, исполнились
+// без события приватного WS (paper, обрыв WS, рестарт): очищаем, как tpFilled.
+func (s *UserSession) dropFilledTPs(ctx context.Context, sts []*models.PositionTrailState, known map[string]bool, algos []models.AlgoOrder) {
+	pending := make(map[string]bool, len(algos))
+	for _, a := range algos {
+		pending[a.AlgoID] = true
+	}
+
+	var changed []*models.PositionTrailState
+	s.PosMu.Lock()
+	for _, st := range sts {
+		dirty := false
+		for i, tp := range st.TPs {
+			if known[tp.AlgoID] && !pending[tp.AlgoID] {
+				st.TPs[i] = models.TPOrder{Price: tp.Price, Frac: tp.Frac}
+				dirty = true
+			}
+		}
+		if dirty {
+			changed = append(changed, st)
+		}
+	}
+	s.PosMu.Unlock()
+
+	for _, st := range changed {
+		s.saveTrail(ctx, st)
+	}
+}
+
+// sweepTPs — dropFilledTPs по свежему списку висящих алго;
+// без выставленных ступеней на биржу не ходит.
+func (s *UserSession) sweepTPs(ctx context.Context, sts []*models.PositionTrailState) error {
+	known := s.liveTPs(sts)
+	if len(known) == 0 {
+		return nil
+	}
+	algos, err := s.Okx.PendingAlgos(ctx)
+	if err != nil {
+		return err
+	}
+	s.dropFilledTPs(ctx, sts, known, algos)
+	return nil
+}
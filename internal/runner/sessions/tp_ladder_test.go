@@ -0,0 +1,212 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+// algoExchange — только SL/TP: остальное из Exchange тесту не нужно.
+// pending — висящие на «бирже» алго.
+type algoExchange struct {
+	Exchange
+	seq      int
+	canceled []string
+	pending  map[string]bool
+	onCancel func(algoID string)
+}
+
+func newAlgoExchange(pending ...string) *algoExchange {
+	e := &algoExchange{pending: make(map[string]bool)}
+	for _, id := range pending {
+		e.pending[id] = true
+	}
+	return e
+}
+
+func (e *algoExchange) CancelAlgo(_ context.Context, _, algoID string) error {
+	e.canceled = append(e.canceled, algoID)
+	delete(e.pending, algoID)
+	if e.onCancel != nil {
+		e.onCancel(algoID)
+	}
+	return nil
+}
+
+func (e *algoExchange) PlaceSingleAlgo(context.Context, string, string, float64, float64, bool) (string, error) {
+	e.seq++
+	id := fmt.Sprintf("new%d", e.seq)
+	e.pending[id] = true
+	return id, nil
+}
+
+func (e *algoExchange) PendingAlgos(context.Context) ([]models.AlgoOrder, error) {
+	out := make([]models.AlgoOrder, 0, len(e.pending))
+	for id := range e.pending {
+		out = append(out, models.AlgoOrder{AlgoID: id})
+	}
+	return out, nil
+}
+
+func TestResizeTPsSkipsRungFilledMeanwhile(t *testing.T) {
+	ex := newAlgoExchange("tp1", "tp2")
+	s := &UserSession{Okx: ex, Positions: make(map[string]*models.PositionTrailState)}
+	st := &models.PositionTrailState{
+		InstID: fakeInst, PosSide: "long",
+		InitSize: 100, Size: 50, LotSz: 1, MinSz: 1,
+	}
+	st.TPs[0] = models.TPOrder{AlgoID: "tp1", Price: 101, Size: 40, Frac: 0.4}
+	st.TPs[1] = models.TPOrder{AlgoID: "tp2", Price: 102, Size: 40, Frac: 0.4}
+	s.Positions[helper.TrailKey(st.InstID, st.PosSide)] = st
+
+	// первая ступень исполняется, пока её переставляем
+	ex.onCancel = func(algoID string) {
+		if algoID == "tp1" {
+			s.tpFilled(fakeInst, "long", "tp1")
+		}
+	}
+	s.resizeTPs(context.Background(), st)
+
+	// tp1 по-прежнему 40 (Frac*init <= size) — её не трогаем; tp2 урезается до 10
+	if st.TPs[0].AlgoID != "tp1" || st.TPs[0].Size != 40 {
+		t.Fatalf("rung 1 = %+v, want untouched tp1", st.TPs[0])
+	}
+	if st.TPs[1].AlgoID != "new1" || st.TPs[1].Size != 10 {
+		t.Fatalf("rung 2 = %+v, want new1 size 10", st.TPs[1])
+	}
+
+	// теперь объём ещё меньше: переставляются обе, а tp1 в это время исполняется
+	st.Size = 30
+	s.resizeTPs(context.Background(), st)
+
+	if st.TPs[0].AlgoID != "" || st.TPs[0].Size != 0 {
+		t.Fatalf("filled rung 1 = %+v, want cleared by tpFilled", st.TPs[0])
+	}
+	if got := ex.canceled[len(ex.canceled)-1]; got != "new2" {
+		t.Fatalf("last canceled = %s, want orphan new2 for the filled rung", got)
+	}
+}
+
+// Ступень исполнилась, а события WS не было (paper, обрыв, рестарт):
+// resizeTPs сверяется с биржей и не отдаёт её долю живым ступеням.
+func TestResizeTPsIgnoresRungFilledWithoutEvent(t *testing.T) {
+	ex := newAlgoExchange("tp2") // tp1 уже исполнен
+	s := &UserSession{Okx: ex, Positions: make(map[string]*models.PositionTrailState)}
+	st := &models.PositionTrailState{
+		InstID: fakeInst, PosSide: "long",
+		InitSize: 100, Size: 60, LotSz: 1, MinSz: 1,
+	}
+	st.TPs[0] = models.TPOrder{AlgoID: "tp1", Price: 101, Size: 40, Frac: 0.4}
+	st.TPs[1] = models.TPOrder{AlgoID: "tp2", Price: 102, Size: 40, Frac: 0.4}
+	s.Positions[helper.TrailKey(st.InstID, st.PosSide)] = st
+
+	s.resizeTPs(context.Background(), st)
+
+	if st.TPs[0].AlgoID != "" || st.TPs[0].Size != 0 {
+		t.Fatalf("filled rung 1 = %+v, want cleared", st.TPs[0])
+	}
+	if st.TPs[1].AlgoID != "tp2" || st.TPs[1].Size != 40 {
+		t.Fatalf("rung 2 = %+v, want untouched tp2 size 40", st.TPs[1])
+	}
+	if len(ex.canceled) != 0 || ex.seq != 0 {
+		t.Fatalf("canceled %v, placed %d: want no exchange changes", ex.canceled, ex.seq)
+	}
+}
+
+func TestTPLadder(t *testing.T) {
+	tests := []struct {
+		name       string
+		side       string
+		riskDist   float64
+		ladder     []models.TPLevel
+		wantPrices []float64
+		wantSizes  []float64
+	}{
+		{
+			// пустые ступени выкидываются, остальные — по R; последняя урезана до остатка
+			name:     "sorted and capped by size",
+			side:     "BUY",
+			riskDist: 1,
+			ladder: []models.TPLevel{
+				{R: 2, Frac: 0.3}, {R: 1, Frac: 0.5}, {R: 0, Frac: 0.2}, {R: 3, Frac: 0.5}, {R: 4},
+			},
+			wantPrices: []float64{101, 102, 103},
+			wantSizes:  []float64{5, 3, 2},
+		},
+		{
+			// цены — к тику в безопасную сторону: 100.33 -> 100.4, 99.67 -> 99.6
+			name:       "buy rounds up",
+			side:       "BUY",
+			riskDist:   0.33,
+			ladder:     []models.TPLevel{{R: 1, Frac: 1}},
+			wantPrices: []float64{100.4},
+			wantSizes:  []float64{10},
+		},
+		{
+			name:       "sell rounds down",
+			side:       "SELL",
+			riskDist:   0.33,
+			ladder:     []models.TPLevel{{R: 1, Frac: 1}},
+			wantPrices: []float64{99.6},
+			wantSizes:  []float64{10},
+		},
+		{
+			name:     "max levels",
+			side:     "SELL",
+			riskDist: 1,
+			ladder: []models.TPLevel{
+				{R: 5, Frac: 0.1}, {R: 4, Frac: 0.1}, {R: 3, Frac: 0.1}, {R: 2, Frac: 0.1}, {R: 1, Frac: 0.1},
+			},
+			wantPrices: []float64{99, 98, 97, 96},
+			wantSizes:  []float64{1, 1, 1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tps := TPLadder(tt.side, 100, tt.riskDist, 10, 0.1, 1, 1, tt.ladder)
+			if len(tps) != len(tt.wantPrices) {
+				t.Fatalf("got %d rungs %+v, want %d", len(tps), tps, len(tt.wantPrices))
+			}
+			for i, tp := range tps {
+				if math.Abs(tp.Price-tt.wantPrices[i]) > 1e-9 || tp.Size != tt.wantSizes[i] || tp.AlgoID != "" {
+					t.Fatalf("rung %d = %+v, want price %v size %v", i, tp, tt.wantPrices[i], tt.wantSizes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFitTPSizes(t *testing.T) {
+	tests := []struct {
+		name         string
+		fracs        []float64
+		init, size   float64
+		lotSz, minSz float64
+		want         []float64
+	}{
+		{"full size", []float64{0.25, 0.25, 0.5}, 10, 10, 0.5, 1, []float64{2.5, 2.5, 5}},
+		{"after partial exit", []float64{0.25, 0.25, 0.5}, 10, 6, 0.5, 1, []float64{2.5, 2.5, 1}},
+		// остаток 1.7 -> вниз к лоту 1.5; 0.2 меньше лота -> 0
+		{"floor to lot", []float64{0.25, 0.25, 0.5}, 10, 4.2, 0.5, 0.5, []float64{2.5, 1.5, 0}},
+		// 1 < minSz — ступень не ставим, её объём остаётся дальним
+		{"below min size", []float64{0.1, 0.5}, 10, 10, 1, 2, []float64{0, 5}},
+		{"no lot", []float64{0.3, 0.7}, 1, 1, 0, 0, []float64{0.3, 0.7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tps := make([]models.TPOrder, len(tt.fracs))
+			for i, f := range tt.fracs {
+				tps[i].Frac = f
+			}
+			fitTPSizes(tps, tt.init, tt.size, tt.lotSz, tt.minSz)
+			for i, tp := range tps {
+				if math.Abs(tp.Size-tt.want[i]) > 1e-9 {
+					t.Fatalf("sizes = %+v, want %v", tps, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package sessions
+
+import (
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+// defaultTrailTF — ТФ ATR/баров стадии Trail, если сигнальный ТФ не задан.
+const defaultTrailTF = "15m"
+
+// TrailTF — ТФ ATR/баров стадии Trail: сигнальный ТФ стратегии (cfg.Strategy.LTF).
+func TrailTF(ltf string) string {
+	if tf := helper.NormTF(ltf); tf != "" {
+		return tf
+	}
+	return defaultTrailTF
+}
+
+// ATRSource — ATR и экстремумы последних баров (считает стратегия по закрытым свечам).
+type ATRSource interface {
+	// Value — ATR; 0 — не прогрет.
+	Value(instID, tf string) float64
+	// LowHigh — min low / max high последних n баров; ok == false — баров мало.
+	LowHigh(instID, tf string, n int) (lo, hi float64, ok bool)
+}
+
+// TrailMarketFrom — рынок для стадии Trail из src по ТФ tf (nil — только последняя цена).
+// Общая для бота и бэктеста.
+func TrailMarketFrom(src ATRSource, instID, tf string, last float64, tr models.TrailingConfig) models.TrailMarket {
+	m := models.TrailMarket{Last: last}
+	if src == nil {
+		return m
+	}
+	m.ATR = src.Value(instID, tf)
+	if tr.TrailBars > 0 {
+		if lo, hi, ok := src.LowHigh(instID, tf, tr.TrailBars); ok {
+			m.BarLow, m.BarHigh = lo, hi
+		}
+	}
+	return m
+}
@@ -17,14 +17,44 @@ func (s *UserSession) trailOne(ctx context.Context, ct models.CandleTick, p mode
 	key := helper.TrailKey(ct.InstID, p.PosSide)
 
 	// trail state
+	var before models.PositionTrailState
 	s.PosMu.RLock()
 	st := s.Positions[key]
+	if st != nil {
+		before = *st
+	}
 	s.PosMu.RUnlock()
-	if st == nil || st.AlgoID == "" || st.RiskDist <= 0 {
+	if st == nil || before.AlgoID == "" || before.RiskDist <= 0 {
 		return
 	}
 
-	// sync from cache
+	// изменения флагов, SL, size, TP — в БД сразу (если позиция не закрыта
+	// в этом же вызове); MFE отдельно не пишем на каждый новый экстремум 1m,
+	// а сбрасываем раз в 15m слот — на свече, закрывающей слот
+	defer func() {
+		s.PosMu.RLock()
+		alive := s.Positions[key] == st
+		after := *st
+		s.PosMu.RUnlock()
+		if !alive {
+			return
+		}
+		after.MFE = before.MFE
+		if after != before || helper.TrailSlot15m(ct.End).Equal(ct.End) {
+			s.saveTrail(ctx, st)
+		}
+	}()
+
+	mkt := TrailMarketFrom(s.ATR, st.InstID, TrailTF(s.TrailTF), ct.Close, s.Settings.Settings.TrailingConfig)
+
+	// sync from cache, MFE и решение — под PosMu: Size и TPs параллельно
+	// пишет приватный WS (onPositions, tpFilled)
+	s.PosMu.Lock()
+	if s.Positions[key] != st {
+		// закрыли, пока ждали лок
+		s.PosMu.Unlock()
+		return
+	}
 	if p.Size > 0 {
 		st.Size = p.Size
 	}
@@ -35,13 +65,16 @@ func (s *UserSession) trailOne(ctx context.Context, ct models.CandleTick, p mode
 	st.UpdateMFE(ct.High, ct.Low)
 
 	// Решение только на 15m слот (даже если свеча 1m)
-	dec := decideTrail15m(st, s.Settings.Settings, ct.End)
+	dec := DecideTrail15m(st, s.Settings.Settings, mkt, ct.End)
+	size, algoID, oldSL, lastTrailAt := st.Size, st.AlgoID, st.SL, st.LastTrailAt
+	s.PosMu.Unlock()
+
 	if !dec.MoveSL && !dec.Close {
 		return
 	}
 
 	// rate limit по времени (на всякий)
-	if !st.LastTrailAt.IsZero() && ct.End.Sub(st.LastTrailAt) < 60*time.Second {
+	if !lastTrailAt.IsZero() && ct.End.Sub(lastTrailAt) < 60*time.Second {
 		return
 	}
 	// --- PARTIAL CLOSE ---
@@ -50,6 +83,8 @@ func (s *UserSession) trailOne(ctx context.Context, ct models.CandleTick, p mode
 
 		// уменьшаем локально size, чтобы дальше SL ставился на остаток
 		s.PosMu.Lock()
+		closedSz := min(dec.CloseSize, st.Size)
+		st.RealizedR += st.ExitR(ct.Close, closedSz)
 		if st.Size > dec.CloseSize {
 			st.Size -= dec.CloseSize
 		} else {
@@ -57,8 +92,23 @@ func (s *UserSession) trailOne(ctx context.Context, ct models.CandleTick, p mode
 			delete(s.Positions, key)
 		}
 		st.LastTrailAt = ct.End
+		closedAll := s.Positions[key] != st
 		s.PosMu.Unlock()
 
+		s.journalEvent(ctx, st.TradeID, models.EvPartial, map[string]any{
+			"price":  ct.Close,
+			"size":   closedSz,
+			"reason": dec.Reason,
+		})
+		if closedAll {
+			s.deleteTrail(ctx, st.InstID, st.PosSide)
+			// остаток уже учтён в RealizedR
+			s.PosMu.Lock()
+			st.Size = 0
+			s.PosMu.Unlock()
+			s.journalClose(ctx, st, ct.Close, dec.Reason)
+		}
+
 		if s.canSend("partial:"+st.InstID+":"+st.PosSide, 30*time.Minute) {
 			s.Notifier.SendF(ctx, s.UserID,
 				"💰 [%s] Частичная фиксация (%s) закрыто=%.4f | %s",
@@ -69,12 +119,20 @@ func (s *UserSession) trailOne(ctx context.Context, ct models.CandleTick, p mode
 	}
 	// --- CLOSE ---
 	if dec.Close {
-		_, _ = s.Okx.CloseMarket(ctx, st.InstID, st.PosSide, st.Size)
+		_, _ = s.Okx.CloseMarket(ctx, st.InstID, st.PosSide, size)
 
 		// удаляем стейт, чтобы не трогать закрытую
 		s.PosMu.Lock()
 		delete(s.Positions, key)
 		s.PosMu.Unlock()
+		s.deleteTrail(ctx, st.InstID, st.PosSide)
+
+		s.journalEvent(ctx, st.TradeID, models.EvTimeStop, map[string]any{
+			"price":  ct.Close,
+			"size":   size,
+			"reason": dec.Reason,
+		})
+		s.journalClose(ctx, st, ct.Close, dec.Reason)
 
 		s.Notifier.SendF(ctx, s.UserID,
 			"🕒 [%s] TimeStop закрытие позиции (%s) | reason=%s",
@@ -94,21 +152,32 @@ func (s *UserSession) trailOne(ctx context.Context, ct models.CandleTick, p mode
 	}
 
 	// cancel old SL
-	_ = s.Okx.CancelAlgo(ctx, st.InstID, st.AlgoID)
+	_ = s.Okx.CancelAlgo(ctx, st.InstID, algoID)
 
 	// place new SL
-	newAlgoID, err := s.Okx.PlaceSingleAlgo(ctx, st.InstID, st.PosSide, st.Size, newSL, false)
+	newAlgoID, err := s.Okx.PlaceSingleAlgo(ctx, st.InstID, st.PosSide, size, newSL, false)
 	if err != nil {
+		s.journalEvent(ctx, st.TradeID, models.EvError, map[string]any{"stage": "sl_move", "error": err.Error()})
 		return
 	}
 
+	s.journalEvent(ctx, st.TradeID, models.EvSLMoved, map[string]any{
+		"from":    oldSL,
+		"to":      newSL,
+		"algo_id": newAlgoID,
+		"reason":  dec.Reason,
+	})
+
 	s.PosMu.Lock()
 	st.SL = newSL
 	st.AlgoID = newAlgoID
 	st.LastTrailAt = ct.End
-	// LastTrailEnd уже выставил decideTrail15m через slot
+	// LastTrailEnd уже выставил DecideTrail15m через slot
 	s.PosMu.Unlock()
 
+	// лестница TP — под текущий объём (после partial ступени не должны его превышать)
+	s.resizeTPs(ctx, st)
+
 	if s.canSend("trail:"+st.InstID+":"+st.PosSide, 15*time.Minute) {
 		s.Notifier.SendF(ctx, s.UserID,
 			"🛡 [%s] SL обновлён (%s) -> %.6f | %s",
@@ -117,9 +186,12 @@ func (s *UserSession) trailOne(ctx context.Context, ct models.CandleTick, p mode
 	}
 }
 
-func decideTrail15m(
+// DecideTrail15m — решение трейлинга на 15m слот: BE, partial, lock, trail, тайм-стоп.
+// Мутирует флаги st (MovedToBE/TookPartial/LockedProfit/LastTrailEnd), биржу не трогает.
+func DecideTrail15m(
 	st *models.PositionTrailState,
 	cfg models.Settings,
+	mkt models.TrailMarket,
 	slotEnd time.Time,
 ) models.TrailDecision {
 	R := st.RiskDist
@@ -141,7 +213,7 @@ func decideTrail15m(
 		return candidate < st.SL
 	}
 
-	minImprove := 0.10 * R
+	minImprove := minImproveR * R
 	improvesEnough := func(candidate float64) bool {
 		if st.PosSide == "long" {
 			return candidate-st.SL >= minImprove
@@ -223,8 +295,61 @@ func decideTrail15m(
 			st.LastTrailEnd = slot
 			return models.TrailDecision{NewSL: cand, MoveSL: true, Reason: "LOCK@0.9R->0.3R"}
 		}
+		if !improves(cand) {
+			// SL уже не хуже уровня Lock (ручной перенос, большой BE-offset) —
+			// стадия пройдена, дальше Trail
+			st.LockedProfit = true
+		}
+	}
+
+	// --- 3) Trail после Lock: SL тянется за MFE / экстремумом баров ---
+	if st.LockedProfit {
+		cand, reason := trailCandidate(st, cfg.TrailingConfig, mkt)
+		// SL по ту сторону от цены биржа не примет
+		beyond := mkt.Last > 0 &&
+			((st.PosSide == "long" && cand >= mkt.Last) || (st.PosSide == "short" && cand <= mkt.Last))
+		if cand > 0 && !beyond && improves(cand) && improvesEnough(cand) {
+			st.LastTrailEnd = slot
+			return models.TrailDecision{NewSL: cand, MoveSL: true, Reason: reason}
+		}
 	}
 
 	// ничего не делаем, но слот фиксировать не нужно
 	return models.TrailDecision{}
 }
+
+// trailCandidate — кандидат SL стадии Trail; 0 — режим выключен или нет данных.
+func trailCandidate(st *models.PositionTrailState, tr models.TrailingConfig, mkt models.TrailMarket) (float64, string) {
+	long := st.PosSide == "long"
+
+	switch tr.TrailMode {
+	case models.TrailModeATR:
+		if mkt.ATR <= 0 || tr.TrailATRMult <= 0 {
+			return 0, ""
+		}
+		d := tr.TrailATRMult * mkt.ATR
+		if long {
+			return st.MFE - d, fmt.Sprintf("TRAIL_ATR x%.2f", tr.TrailATRMult)
+		}
+		return st.MFE + d, fmt.Sprintf("TRAIL_ATR x%.2f", tr.TrailATRMult)
+
+	case models.TrailModeR:
+		if tr.TrailDistR <= 0 {
+			return 0, ""
+		}
+		d := tr.TrailDistR * st.RiskDist
+		if long {
+			return st.MFE - d, fmt.Sprintf("TRAIL_R %.2fR", tr.TrailDistR)
+		}
+		return st.MFE + d, fmt.Sprintf("TRAIL_R %.2fR", tr.TrailDistR)
+
+	case models.TrailModeBars:
+		if long && mkt.BarLow > 0 {
+			return mkt.BarLow, fmt.Sprintf("TRAIL_BARS low[%d]", tr.TrailBars)
+		}
+		if !long && mkt.BarHigh > 0 {
+			return mkt.BarHigh, fmt.Sprintf("TRAIL_BARS high[%d]", tr.TrailBars)
+		}
+	}
+	return 0, ""
+}
@@ -0,0 +1,81 @@
+package sessions
+
+import (
+	"math"
+	"testing"
+	"time"
+	"trade_bot/internal/models"
+)
+
+func TestDecideTrail15mStages(t *testing.T) {
+	cfg := models.Settings{TrailingConfig: models.TrailingConfig{
+		BETriggerR:   0.6,
+		LockTriggerR: 0.9,
+		LockOffsetR:  0.3,
+		TrailMode:    models.TrailModeR,
+		TrailDistR:   1,
+	}}
+	slot := time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		st     models.PositionTrailState
+		wantSL float64 // 0 — SL не двигаем
+		locked bool
+	}{
+		{
+			name:   "BE",
+			st:     models.PositionTrailState{SL: 99, MFE: 100.7},
+			wantSL: 100,
+		},
+		{
+			name:   "BE improves less than minImproveR",
+			st:     models.PositionTrailState{SL: 99.95, MFE: 100.7},
+			wantSL: 0,
+		},
+		{
+			name:   "lock",
+			st:     models.PositionTrailState{SL: 100, MFE: 101, MovedToBE: true},
+			wantSL: 100.3,
+			locked: true,
+		},
+		{
+			// Lock не выставлен (улучшение < minImproveR) — Trail не начинается,
+			// хотя MFE уже выше LockTriggerR
+			name:   "no trail before lock",
+			st:     models.PositionTrailState{SL: 100.25, MFE: 102, MovedToBE: true},
+			wantSL: 0,
+		},
+		{
+			// SL уже выше уровня Lock: стадия пройдена, сразу Trail
+			name:   "lock level already behind SL",
+			st:     models.PositionTrailState{SL: 100.5, MFE: 102, MovedToBE: true},
+			wantSL: 101,
+			locked: true,
+		},
+		{
+			name:   "trail after lock",
+			st:     models.PositionTrailState{SL: 100.3, MFE: 102, MovedToBE: true, LockedProfit: true},
+			wantSL: 101,
+			locked: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := tt.st
+			st.InstID, st.PosSide, st.Entry, st.RiskDist = fakeInst, "long", 100, 1
+
+			dec := DecideTrail15m(&st, cfg, models.TrailMarket{Last: st.MFE}, slot)
+			if tt.wantSL == 0 {
+				if dec.MoveSL {
+					t.Fatalf("unexpected SL move to %.4f (%s)", dec.NewSL, dec.Reason)
+				}
+			} else if !dec.MoveSL || math.Abs(dec.NewSL-tt.wantSL) > 1e-9 {
+				t.Fatalf("NewSL = %.4f (move=%t %s), want %.4f", dec.NewSL, dec.MoveSL, dec.Reason, tt.wantSL)
+			}
+			if st.LockedProfit != tt.locked {
+				t.Fatalf("LockedProfit = %t, want %t", st.LockedProfit, tt.locked)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package sessions
+
+import (
+	"sync"
+	"testing"
+	"time"
+	"trade_bot/internal/helper"
+	"trade_bot/internal/models"
+)
+
+// trailOne на 1m свечах одновременно с приватным WS (onPositions, tpFilled):
+// под -race не должно быть гонок по Size/Entry/MFE/флагам/TPs.
+func TestTrailOneConcurrentWithPrivateStream(t *testing.T) {
+	srv := newFakeOKX(t)
+	s := newFakeSession(t, srv)
+	st := openLong(t, s)
+
+	slot := helper.TrailSlot15m(time.Now())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 60; i++ {
+			px := 100 + float64(i)*0.05
+			s.OnCandleClose(s.Ctx, bar1m(slot.Add(time.Duration(i)*time.Minute), px, px+0.1, px-0.1, px))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 60; i++ {
+			s.onPositions(s.Ctx, []models.OpenPosition{{
+				Symbol: fakeInst, Side: "long", HoldVol: st.Size - float64(i%2),
+				HoldAvgPrice: 100, LastPrice: 100,
+			}})
+			s.tpFilled(fakeInst, "long", "no-such-algo")
+		}
+	}()
+	wg.Wait()
+
+	if got := trailState(s, fakeInst, "long"); got == nil || !got.MovedToBE {
+		t.Fatalf("expected position moved to BE, got %+v", got)
+	}
+}
@@ -0,0 +1,46 @@
+package sessions
+
+import (
+	"context"
+	"log"
+	"time"
+	"trade_bot/internal/models"
+)
+
+// TrailStore — постоянное хранилище трейл-состояния (Postgres),
+// чтобы после рестарта трейлинг продолжил с того же места.
+type TrailStore interface {
+	SaveTrail(ctx context.Context, userID int64, st *models.PositionTrailState) error
+	DeleteTrail(ctx context.Context, userID int64, instID, posSide string) error
+	LoadTrails(ctx context.Context, userID int64) ([]*models.PositionTrailState, error)
+}
+
+const trailStoreTimeout = 5 * time.Second
+
+// saveTrail пишет копию состояния: st дальше мутирует trailOne.
+// Ошибку только логируем — торговлю из-за БД не останавливаем.
+func (s *UserSession) saveTrail(ctx context.Context, st *models.PositionTrailState) {
+	if s.Store == nil {
+		return
+	}
+	s.PosMu.RLock()
+	snap := *st
+	s.PosMu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), trailStoreTimeout)
+	defer cancel()
+	if err := s.Store.SaveTrail(ctx, s.UserID, &snap); err != nil {
+		log.Printf("[TRAIL] user=%d save %s %s: %v", s.UserID, snap.InstID, snap.PosSide, err)
+	}
+}
+
+func (s *UserSession) deleteTrail(ctx context.Context, instID, posSide string) {
+	if s.Store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), trailStoreTimeout)
+	defer cancel()
+	if err := s.Store.DeleteTrail(ctx, s.UserID, instID, posSide); err != nil {
+		log.Printf("[TRAIL] user=%d delete %s %s: %v", s.UserID, instID, posSide, err)
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 	"trade_bot/internal/helper"
 	"trade_bot/internal/models"
-	okx_client "trade_bot/internal/modules/okx_client/service"
 
 	tgbot "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -34,30 +33,52 @@ type UserSession struct {
 
 	// трейлинг состояние
 	Positions map[string]*models.PositionTrailState // key = instId:posSide
+	// фактические выходы из приватного WS, ждут закрытия позиции (под PosMu)
+	exitHints map[string]exitHint
 
 	// кеш позиций
 	PositionsCache map[models.PosKey]models.CachedPos
 	PosCacheAt     time.Time // когда последний раз обновляли с OKX
 
+	// equity в USDT из приватного WS (под PosCacheMu; 0 — ещё не приходил)
+	Equity   float64
+	EquityAt time.Time
+
 	//сенлдер в телеграм
 	Notifier TelegramNotifier
 	//клиент биржи
-	Okx *okx_client.Client
+	Okx Exchange
+	//хранилище трейл-состояния (nil — только в памяти)
+	Store TrailStore
+	//хранилище риск-счётчиков (nil — только в памяти)
+	Risk RiskStore
+	//журнал сделок (nil — не пишем)
+	Journal Journal
+	//корреляции инструментов (nil — фильтр выключен)
+	Corr CorrelationSource
+	//ATR и экстремумы баров для стадии Trail (nil — стадия только в режиме r)
+	ATR ATRSource
+	//ТФ, по которому берутся ATR и бары стадии Trail (см. TrailTF)
+	TrailTF string
 
 	Queue       chan models.Signal
 	Pending     map[string]bool
 	CooldownTil map[string]time.Time
+	risk        riskState // риск-лимиты (под mu)
+	riskSaveMu  sync.Mutex
 
 	msgMu     sync.Mutex
 	LastMsgAt map[string]time.Time // key -> time
 }
 
-// OpenPositionWithTpSl открывает рыночный ордер и пытается поставить TP/SL.
-// Возвращает orderID рыночного ордера (если успешно) или ошибку.
+// OpenPositionWithTpSl открывает позицию (по рынку или лимитом, см. EntryMode)
+// и пытается поставить TP/SL. Возвращает orderID ордера на открытие или ошибку.
+// tradeID — запись в журнале сделок (0 — не журналируем).
 func (s *UserSession) OpenPositionWithTpSl(
 	ctx context.Context,
 	sig models.Signal,
 	params *models.TradeParams,
+	tradeID int64,
 ) (*models.OpenResult, error) {
 
 	// 1. Маппим сторону в OKX side/openType
@@ -81,18 +102,16 @@ func (s *UserSession) OpenPositionWithTpSl(
 		len(ts.OKXAPISecret),
 		len(ts.OKXPassphrase),
 	)
-	// 2. Открываем рыночный ордер
-	orderID, err := s.Okx.PlaceMarket(
-		ctx,
-		sig.InstID,
-		params.Size,
-		sideInt,
-		params.Leverage,
-		openType,
-	)
+	// 2. Вход по режиму юзера: market / limit / post-only / limit → market
+	fill, err := s.enter(ctx, sig, params, sideInt, openType, tradeID)
 	if err != nil {
-		return nil, fmt.Errorf("PlaceMarket: %w", err)
+		return nil, err
 	}
+	orderID := fill.OrderID
+
+	// уровни и объём — от фактического входа (params меняется на месте:
+	// по нему же дальше пишутся кеш позиций и трейл-состояние)
+	refitToFill(params, fill.AvgPx, fill.Size, ts.TPLadder)
 
 	// 3. TP/SL (order-algo)
 	posSide := "long"
@@ -114,14 +133,66 @@ func (s *UserSession) OpenPositionWithTpSl(
 	if err != nil {
 		s.Notifier.SendF(ctx, s.UserID,
 			"⚠️ [%s] TP/SL не выставлены на OKX: %v", sig.InstID, err)
+		s.journalEvent(ctx, tradeID, models.EvError, map[string]any{"stage": "sl", "error": err.Error()})
 	}
 
-	// 2) Take-profit
-	tpAlgoId, err := s.Okx.PlaceSingleAlgo(ctx, sig.InstID, posSide, params.Size, params.TP, true)
-	if err != nil {
-		s.Notifier.SendF(ctx, s.UserID,
-			"⚠️ [%s] TP/SL не выставлены на OKX: %v", sig.InstID, err)
+	// 2) Take-profit: один на весь объём или лестница отдельными reduce-ордерами
+	var tpAlgoId string
+	var tps [models.MaxTPLevels]models.TPOrder
+	if len(params.TPs) == 0 {
+		tpAlgoId, err = s.Okx.PlaceSingleAlgo(ctx, sig.InstID, posSide, params.Size, params.TP, true)
+		if err != nil {
+			s.Notifier.SendF(ctx, s.UserID,
+				"⚠️ [%s] TP/SL не выставлены на OKX: %v", sig.InstID, err)
+			s.journalEvent(ctx, tradeID, models.EvError, map[string]any{"stage": "tp", "error": err.Error()})
+		}
+	}
+	for i, tp := range params.TPs {
+		tps[i] = tp
+		if tp.Size <= 0 {
+			continue
+		}
+		id, err := s.Okx.PlaceSingleAlgo(ctx, sig.InstID, posSide, tp.Size, tp.Price, true)
+		if err != nil {
+			tps[i].Size = 0
+			s.Notifier.SendF(ctx, s.UserID,
+				"⚠️ [%s] TP #%d не выставлен на OKX: %v", sig.InstID, i+1, err)
+			s.journalEvent(ctx, tradeID, models.EvError, map[string]any{"stage": "tp", "level": i + 1, "error": err.Error()})
+			continue
+		}
+		tps[i].AlgoID = id
+		if tpAlgoId == "" {
+			tpAlgoId = id
+		}
+	}
 
+	// журнал: вход + выставленные SL/TP
+	s.journalOpened(ctx, &models.Trade{
+		ID:            tradeID,
+		PosSide:       posSide,
+		Params:        params,
+		OrderID:       orderID,
+		EntryOrderIDs: fill.OrderIDs,
+		SLAlgoID:      slAlgoId,
+		TPAlgoID:      tpAlgoId,
+		EntryPrice:    params.Entry,
+		OpenedAt:      time.Now(),
+	})
+	s.journalEvent(ctx, tradeID, models.EvOpened, map[string]any{
+		"order_id": orderID,
+		"price":    params.Entry,
+		"size":     params.Size,
+	})
+	if slAlgoId != "" {
+		s.journalEvent(ctx, tradeID, models.EvSLPlaced, map[string]any{"algo_id": slAlgoId, "price": params.SL})
+	}
+	if len(params.TPs) == 0 && tpAlgoId != "" {
+		s.journalEvent(ctx, tradeID, models.EvTPPlaced, map[string]any{"algo_id": tpAlgoId, "price": params.TP})
+	}
+	for _, tp := range tps {
+		if tp.AlgoID != "" {
+			s.journalEvent(ctx, tradeID, models.EvTPPlaced, map[string]any{"algo_id": tp.AlgoID, "price": tp.Price, "size": tp.Size})
+		}
 	}
 
 	// 4. Финальное сообщение об успешном входе
@@ -139,7 +210,7 @@ func (s *UserSession) OpenPositionWithTpSl(
 		orderID,
 	)
 
-	return &models.OpenResult{PosSide: posSide, SLAlgoID: slAlgoId, TPAlgoID: tpAlgoId, Entry: params.Entry}, nil
+	return &models.OpenResult{PosSide: posSide, SLAlgoID: slAlgoId, TPAlgoID: tpAlgoId, Entry: params.Entry, TPs: tps}, nil
 }
 func (s *UserSession) Status(ctx context.Context) ([]models.OpenPosition, error) {
 	// просто прокидываем в OKX-клиент, который уже сконфигурен под этого юзера
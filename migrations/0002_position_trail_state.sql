@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE position_trail_state (
+                                      chatid bigint NOT NULL,
+                                      inst_id text NOT NULL,
+                                      pos_side text NOT NULL,
+                                      state jsonb NOT NULL default '{}',
+                                      updated_at timestamptz NOT NULL default now(),
+                                      PRIMARY KEY (chatid, inst_id, pos_side)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE position_trail_state;
+-- +goose StatementEnd
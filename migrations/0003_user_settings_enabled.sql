@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_settings ADD COLUMN enabled boolean NOT NULL default false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_settings DROP COLUMN enabled;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE trades (
+                        id bigserial PRIMARY KEY,
+                        chatid bigint NOT NULL,
+                        inst_id text NOT NULL,
+                        pos_side text NOT NULL default '',
+                        side text NOT NULL default '',
+                        strategy text NOT NULL default '',
+                        tf text NOT NULL default '',
+                        signal_reason text NOT NULL default '',
+                        signal_price double precision NOT NULL default 0,
+                        status text NOT NULL default 'signal',
+                        params jsonb NOT NULL default '{}',
+                        order_id text NOT NULL default '',
+                        sl_algo_id text NOT NULL default '',
+                        tp_algo_id text NOT NULL default '',
+                        entry_price double precision NOT NULL default 0,
+                        exit_price double precision NOT NULL default 0,
+                        exit_reason text NOT NULL default '',
+                        pnl_usdt double precision NOT NULL default 0,
+                        pnl_r double precision NOT NULL default 0,
+                        created_at timestamptz NOT NULL default now(),
+                        opened_at timestamptz,
+                        closed_at timestamptz
+);
+CREATE INDEX trades_chatid_created_at_idx ON trades (chatid, created_at);
+
+CREATE TABLE trade_events (
+                              id bigserial PRIMARY KEY,
+                              trade_id bigint NOT NULL REFERENCES trades (id) ON DELETE CASCADE,
+                              kind text NOT NULL,
+                              data jsonb NOT NULL default '{}',
+                              created_at timestamptz NOT NULL default now()
+);
+CREATE INDEX trade_events_trade_id_idx ON trade_events (trade_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE trade_events;
+DROP TABLE trades;
+-- +goose StatementEnd
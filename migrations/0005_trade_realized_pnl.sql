@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE trades
+    ADD COLUMN gross_usdt double precision NOT NULL default 0,
+    ADD COLUMN fee_usdt double precision NOT NULL default 0,
+    ADD COLUMN funding_usdt double precision NOT NULL default 0,
+    ADD COLUMN settled_at timestamptz;
+CREATE INDEX trades_chatid_closed_at_idx ON trades (chatid, closed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX trades_chatid_closed_at_idx;
+ALTER TABLE trades
+    DROP COLUMN settled_at,
+    DROP COLUMN funding_usdt,
+    DROP COLUMN fee_usdt,
+    DROP COLUMN gross_usdt;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE trades
+    ADD COLUMN entry_order_ids text[] NOT NULL default '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE trades
+    DROP COLUMN entry_order_ids;
+-- +goose StatementEnd
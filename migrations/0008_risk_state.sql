@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE risk_state (
+                            chatid bigint PRIMARY KEY,
+                            state jsonb NOT NULL default '{}',
+                            updated_at timestamptz NOT NULL default now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE risk_state;
+-- +goose StatementEnd